@@ -2,6 +2,9 @@ package generator
 
 import (
 	"fmt"
+	"go/types"
+	"path"
+	"sort"
 	"strings"
 
 	"github.com/13rac1/gowasm-bindgen/internal/parser"
@@ -13,24 +16,40 @@ import (
 // The TypeScript client checks for this field and throws it as a JavaScript Error.
 const ErrorFieldName = "__error"
 
+// ChunksFieldName is the field of the result a streamed []byte call returns
+// once all its chunks are posted (see Options.StreamBytes). It holds the
+// total length, which the client allocates the reassembled array with.
+const ChunksFieldName = "__chunks"
+
+// DiagnosticsFuncName is the JS global registered by --emit-diagnostics
+// (on the namespace object when Options.Namespace is set).
+// It returns goroutine and memory statistics from the Go runtime.
+const DiagnosticsFuncName = "__gowasmStats"
+
+// MethodsInstanceName is the package-level variable the bindings declare to
+// call the methods bound with --methods-of on.
+const MethodsInstanceName = "gowasmInstance"
+
 // GenerateGoBindings generates Go wrapper code for WASM export.
-// workerMode determines whether callbacks use postMessage-based invocation (true)
+// opts.WorkerMode determines whether callbacks use postMessage-based invocation (true)
 // or direct JS function invocation (false).
-func GenerateGoBindings(parsed *parser.ParsedFile, workerMode bool) string {
+func GenerateGoBindings(parsed *parser.ParsedFile, opts Options) string {
 	var b strings.Builder
-
-	// Header with build constraint for WASM-only compilation
-	b.WriteString("//go:build js && wasm\n\n")
-	b.WriteString("// Code generated by gowasm-bindgen. DO NOT EDIT.\n\n")
-	b.WriteString("package ")
-	b.WriteString(parsed.Package)
-	b.WriteString("\n\nimport (\n\t\"fmt\"\n\t\"syscall/js\"\n)\n\n")
+	imports := make(parser.Imports)
 
 	// ErrorFieldName constant for error responses
 	b.WriteString("const ErrorFieldName = \"")
-	b.WriteString(ErrorFieldName)
+	b.WriteString(errorKey(opts))
 	b.WriteString("\"\n\n")
 
+	// The instance bound methods are called on (see parser.UseMethodsOf)
+	for _, fn := range parsed.Functions {
+		if fn.Receiver != "" {
+			fmt.Fprintf(&b, "var %s = new(%s)\n\n", MethodsInstanceName, fn.Receiver)
+			break
+		}
+	}
+
 	// recoverFunc decorator for panic recovery
 	b.WriteString("func recoverFunc(fn func(js.Value, []js.Value) interface{}) js.Func {\n")
 	b.WriteString("\treturn js.FuncOf(func(this js.Value, args []js.Value) (ret interface{}) {\n")
@@ -45,26 +64,307 @@ func GenerateGoBindings(parsed *parser.ParsedFile, workerMode bool) string {
 
 	// Init function to register all functions
 	b.WriteString("func init() {\n")
+	target := "js.Global()"
+	if opts.Namespace != "" {
+		target = "ns"
+		fmt.Fprintf(&b, "\tns := js.Global().Get(%q)\n", opts.Namespace)
+		b.WriteString("\tif ns.IsUndefined() {\n")
+		b.WriteString("\t\tns = js.ValueOf(map[string]interface{}{})\n")
+		fmt.Fprintf(&b, "\t\tjs.Global().Set(%q, ns)\n", opts.Namespace)
+		b.WriteString("\t}\n")
+	}
 	for _, fn := range parsed.Functions {
-		b.WriteString("\tjs.Global().Set(\"")
-		b.WriteString(LowerFirst(fn.Name))
+		b.WriteString("\t")
+		b.WriteString(target)
+		b.WriteString(".Set(\"")
+		b.WriteString(fn.BindingName())
 		b.WriteString("\", recoverFunc(wasm")
 		b.WriteString(fn.Name)
 		b.WriteString("))\n")
 	}
+	if opts.Diagnostics {
+		b.WriteString("\t")
+		b.WriteString(target)
+		b.WriteString(".Set(\"")
+		b.WriteString(DiagnosticsFuncName)
+		b.WriteString("\", recoverFunc(gowasmRuntimeStats))\n")
+	}
 	b.WriteString("}\n\n")
 
 	// Generate wrapper for each function
 	for _, fn := range parsed.Functions {
-		b.WriteString(generateWrapperFunction(fn, workerMode))
+		b.WriteString(generateWrapperFunction(fn, opts, imports))
 		b.WriteString("\n\n")
 	}
 
+	b.WriteString(generateStructHelpers(parsed, b.String(), opts, imports))
+
+	if strings.Contains(b.String(), "gowasmStreamBytes(") {
+		b.WriteString(streamBytesFunction)
+	}
+	if strings.Contains(b.String(), parser.AnyDecoder+"(") {
+		b.WriteString(decodeAnyFunction)
+		imports.Add("encoding/json")
+	}
+	if strings.Contains(b.String(), parser.AnyEncoder+"(") {
+		b.WriteString(encodeAnyFunction)
+		imports.Add("encoding/json")
+	}
+	if strings.Contains(b.String(), parser.JSONDecoder+"(") {
+		b.WriteString(decodeJSONFunction)
+		imports.Add("encoding/json")
+	}
+
+	if opts.Diagnostics {
+		b.WriteString(diagnosticsFunction)
+		imports.Add("runtime")
+	}
+
+	body := b.String()
+	var out strings.Builder
+
+	// Header with build constraint for WASM-only compilation
+	out.WriteString("//go:build js && wasm\n\n")
+	out.WriteString("// Code generated by gowasm-bindgen. DO NOT EDIT.\n")
+	out.WriteString(checksumHeader(opts))
+	out.WriteString("\n")
+	pkg := parsed.Package
+	if opts.GoPackage != "" {
+		pkg = opts.GoPackage
+	}
+	out.WriteString("package ")
+	out.WriteString(pkg)
+	out.WriteString("\n\nimport (\n")
+	for _, imp := range collectImports(imports) {
+		out.WriteString("\t\"")
+		out.WriteString(imp)
+		out.WriteString("\"\n")
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(body)
+
+	return out.String()
+}
+
+// generateStructHelpers returns the conversion helpers of the recursive (or
+// helper-converted) structs used by code, including those only used by other
+// helpers.
+func generateStructHelpers(parsed *parser.ParsedFile, code string, opts Options, imports parser.Imports) string {
+	var names []string
+	for name, t := range parsed.Types {
+		if t.Recursive || t.Helpers {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	emitted := make(map[string]bool)
+	for progress := true; progress; {
+		progress = false
+		for _, name := range names {
+			used := strings.Contains(code, "gowasm"+name+"FromJS(") ||
+				strings.Contains(code, "gowasm"+name+"ToJS(")
+			if emitted[name] || !used {
+				continue
+			}
+			helpers := parser.StructHelpers(*parsed.Types[name], opts.WorkerMode, imports)
+			b.WriteString(helpers)
+			b.WriteString("\n")
+			code += helpers
+			emitted[name] = true
+			progress = true
+		}
+	}
+	return b.String()
+}
+
+// streamsBytes reports whether a result of type t is posted in chunks.
+func streamsBytes(t parser.GoType, opts Options) bool {
+	return opts.StreamBytes && opts.WorkerMode && parser.IsByteSlice(t) && !t.Base64
+}
+
+// streamBytesFunction posts []byte results larger than one chunk through the
+// worker's postChunk global. Each chunk is copied into its own Uint8Array
+// so its buffer can be transferred.
+const streamBytesFunction = `const gowasmChunkSize = 1 << 20
+
+func gowasmStreamBytes(data []byte) interface{} {
+	if len(data) <= gowasmChunkSize {
+		arr := js.Global().Get("Uint8Array").New(len(data))
+		js.CopyBytesToJS(arr, data)
+		return arr
+	}
+	postChunk := js.Global().Get("postChunk")
+	for start := 0; start < len(data); start += gowasmChunkSize {
+		end := start + gowasmChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := js.Global().Get("Uint8Array").New(end - start)
+		js.CopyBytesToJS(chunk, data[start:end])
+		postChunk.Invoke(chunk)
+	}
+	return map[string]interface{}{"` + ChunksFieldName + `": len(data)}
+}
+`
+
+// decodeAnyFunction converts a JS value to interface{} by decoding its
+// JSON.stringify output. undefined, which has no JSON form, becomes nil.
+const decodeAnyFunction = `func ` + parser.AnyDecoder + `(v js.Value) interface{} {
+	if v.IsUndefined() {
+		return nil
+	}
+	var out interface{}
+	if err := json.Unmarshal([]byte(js.Global().Get("JSON").Call("stringify", v).String()), &out); err != nil {
+		panic(err)
+	}
+	return out
+}
+`
+
+// decodeJSONFunction decodes a JS value into the Go value out points to by
+// way of its JSON text, for structs marked with GoType.JSON. An undefined
+// value leaves out unchanged.
+const decodeJSONFunction = `func ` + parser.JSONDecoder + `(v js.Value, out interface{}) {
+	if v.IsUndefined() {
+		return
+	}
+	if err := json.Unmarshal([]byte(js.Global().Get("JSON").Call("stringify", v).String()), out); err != nil {
+		panic(err)
+	}
+}
+`
+
+// encodeAnyFunction converts an interface{} value to JS by parsing its
+// encoding/json output, so any value json.Marshal accepts can be returned.
+const encodeAnyFunction = `func ` + parser.AnyEncoder + `(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return js.Global().Get("JSON").Call("parse", string(data))
+}
+`
+
+// generateEnumCheck returns a membership check for a parameter whose named
+// primitive type is a closed enum (see GoType.EnumClosed), or "" for other
+// parameters. The parameter's value is held in the wrapper variable name.
+func generateEnumCheck(param parser.GoParameter, name string) string {
+	if param.Type.Kind != parser.KindPrimitive || !param.Type.EnumClosed || len(param.Type.EnumValues) == 0 {
+		return ""
+	}
+
+	conditions := make([]string, len(param.Type.EnumValues))
+	for i, value := range param.Type.EnumValues {
+		conditions[i] = name + " != " + value
+	}
+
+	var b strings.Builder
+	b.WriteString("\tif ")
+	b.WriteString(strings.Join(conditions, " && "))
+	b.WriteString(" {\n")
+	fmt.Fprintf(&b, "\t\treturn map[string]interface{}{ErrorFieldName: fmt.Sprintf(\"invalid %s for %s: %%v\", %s)}\n",
+		param.Type.Name, param.Name, name)
+	b.WriteString("\t}\n")
 	return b.String()
 }
 
+// wrapperLocals are the variables a wrapper function declares itself.
+// "this" is kept free as well, as the conventional name of the JS receiver.
+var wrapperLocals = []string{"args", "result", "err", "this"}
+
+// wrapperParamNames returns the names of the variables the wrapper for fn
+// extracts its parameters into. They are the Go parameter names, except where
+// a name would shadow something the wrapper refers to: its own locals, an
+// imported package, a predeclared identifier, a generated declaration, or
+// the function or a parameter type. Those become p0, p1, and so on, after
+// their position. The TypeScript client renames parameters separately (see
+// tsParams), keeping all but reserved words.
+func wrapperParamNames(fn parser.GoFunction) []string {
+	taken := map[string]bool{"_": true, fn.Name: true, "ErrorFieldName": true, "js": true, "fmt": true}
+	for _, name := range wrapperLocals {
+		taken[name] = true
+	}
+	for _, imp := range optionalImports {
+		taken[path.Base(imp)] = true
+	}
+	for _, param := range fn.Params {
+		taken[param.Type.Name] = true
+		for elem := param.Type.Elem; elem != nil; elem = elem.Elem {
+			taken[elem.Name] = true
+		}
+	}
+	reserved := func(name string) bool {
+		return taken[name] || types.Universe.Lookup(name) != nil || strings.HasPrefix(name, "gowasm")
+	}
+
+	// Renamed parameters must not clash with the others either
+	used := make(map[string]bool)
+	for _, param := range fn.Params {
+		used[param.Name] = true
+	}
+
+	names := make([]string, len(fn.Params))
+	for i, param := range fn.Params {
+		name := param.Name
+		if reserved(name) {
+			name = fmt.Sprintf("p%d", i)
+			for used[name] {
+				name = "_" + name
+			}
+			used[name] = true
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// diagnosticsFunction reports Go runtime statistics for --emit-diagnostics.
+// The keys match the RuntimeStats interface in the TypeScript client.
+const diagnosticsFunction = `func gowasmRuntimeStats(_ js.Value, _ []js.Value) interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return map[string]interface{}{
+		"numGoroutine": runtime.NumGoroutine(),
+		"alloc":        m.Alloc,
+		"totalAlloc":   m.TotalAlloc,
+		"sys":          m.Sys,
+		"numGC":        m.NumGC,
+	}
+}
+
+`
+
+// optionalImports are the packages generated code may refer to besides fmt
+// and syscall/js. Wrapper variables never take their names.
+var optionalImports = []string{
+	"context",
+	"encoding/base64",
+	"encoding/binary",
+	"encoding/json",
+	"errors",
+	"math",
+	"runtime",
+	"strconv",
+	"time",
+	"unsafe",
+}
+
+// collectImports returns the sorted import paths of the generated bindings:
+// fmt and syscall/js, which recoverFunc always uses, and the packages the
+// emitted code recorded in imports.
+func collectImports(imports parser.Imports) []string {
+	paths := []string{"fmt", "syscall/js"}
+	for imp := range imports {
+		paths = append(paths, imp)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // generateWrapperFunction generates a single WASM wrapper function
-func generateWrapperFunction(fn parser.GoFunction, workerMode bool) string {
+func generateWrapperFunction(fn parser.GoFunction, opts Options, imports parser.Imports) string {
 	var b strings.Builder
 
 	// Function signature
@@ -72,15 +372,33 @@ func generateWrapperFunction(fn parser.GoFunction, workerMode bool) string {
 	b.WriteString(fn.Name)
 	b.WriteString("(_ js.Value, args []js.Value) interface{} {\n")
 
+	// Optional arguments left out by the caller are undefined, which pointer
+	// extraction turns into nil
+	if optionalParamsStart(fn.Params) < len(fn.Params) {
+		fmt.Fprintf(&b, "\tfor len(args) < %d {\n", len(fn.Params))
+		b.WriteString("\t\targs = append(args, js.Undefined())\n")
+		b.WriteString("\t}\n")
+	}
+
 	// Extract parameters
+	names := wrapperParamNames(fn)
 	for i, param := range fn.Params {
 		b.WriteString("\t")
-		b.WriteString(param.Name)
+		b.WriteString(names[i])
 		b.WriteString(" := ")
-		b.WriteString(parser.GoTypeToJSExtraction(param.Type, fmt.Sprintf("args[%d]", i), workerMode))
+		if param.IsVariadic {
+			b.WriteString(parser.GoTypeToJSVariadicExtraction(param.Type, i, opts.WorkerMode, imports))
+		} else {
+			b.WriteString(parser.GoTypeToJSExtraction(param.Type, fmt.Sprintf("args[%d]", i), opts.WorkerMode, imports))
+		}
 		b.WriteString("\n")
 	}
 
+	// Reject values outside the declared constants of enum parameters
+	for i, param := range fn.Params {
+		b.WriteString(generateEnumCheck(param, names[i]))
+	}
+
 	// Call the actual function
 	b.WriteString("\t")
 
@@ -99,19 +417,37 @@ func generateWrapperFunction(fn parser.GoFunction, workerMode bool) string {
 		b.WriteString("err := ")
 	}
 
+	if fn.Receiver != "" {
+		b.WriteString(MethodsInstanceName)
+		b.WriteString(".")
+	}
 	b.WriteString(fn.Name)
 	b.WriteString("(")
 
 	// Pass parameters
-	paramNames := make([]string, len(fn.Params))
+	var paramNames []string
+	if fn.Context {
+		// JS calls run to completion on the event loop, so nothing could
+		// cancel the context while the function runs
+		paramNames = append(paramNames, "context.Background()")
+		imports.Add("context")
+	}
 	for i, param := range fn.Params {
-		paramNames[i] = param.Name
+		name := names[i]
+		if param.IsVariadic {
+			name += "..."
+		}
+		paramNames = append(paramNames, name)
 	}
 	b.WriteString(strings.Join(paramNames, ", "))
 	b.WriteString(")\n")
 
 	// Handle errors
-	if hasError {
+	if fn.ResultUnion {
+		b.WriteString("\tif err != nil {\n")
+		b.WriteString("\t\treturn map[string]interface{}{\"ok\": false, \"error\": err.Error()}\n")
+		b.WriteString("\t}\n")
+	} else if hasError {
 		b.WriteString("\tif err != nil {\n")
 		b.WriteString("\t\treturn map[string]interface{}{ErrorFieldName: err.Error()}\n")
 		b.WriteString("\t}\n")
@@ -119,12 +455,32 @@ func generateWrapperFunction(fn parser.GoFunction, workerMode bool) string {
 
 	// Return result
 	b.WriteString("\t")
-	if hasNonErrorReturn {
+	if fn.ResultUnion {
+		b.WriteString("return map[string]interface{}{\"ok\": true")
+		if hasNonErrorReturn {
+			b.WriteString(", \"value\": ")
+			if opts.SharedMemory {
+				b.WriteString(parser.GoTypeToJSSharedReturn(fn.Returns[0], "result", imports))
+			} else {
+				b.WriteString(parser.GoTypeToJSReturn(fn.Returns[0], "result", imports))
+			}
+		}
+		b.WriteString("}\n")
+	} else if hasNonErrorReturn {
 		// Get the non-error return type
 		returnType := fn.Returns[0]
 		b.WriteString("return ")
-		b.WriteString(parser.GoTypeToJSReturn(returnType, "result"))
+		if streamsBytes(returnType, opts) {
+			b.WriteString("gowasmStreamBytes(result)")
+		} else if opts.SharedMemory {
+			b.WriteString(parser.GoTypeToJSSharedReturn(returnType, "result", imports))
+		} else {
+			b.WriteString(parser.GoTypeToJSReturn(returnType, "result", imports))
+		}
 		b.WriteString("\n")
+	} else if hasError {
+		// Report success so JS can tell a completed call from undefined
+		b.WriteString("return true\n")
 	} else {
 		b.WriteString("return nil\n")
 	}
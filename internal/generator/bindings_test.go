@@ -4,6 +4,7 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -27,6 +28,7 @@ func TestGenerateGoBindings(t *testing.T) {
 				checkPackage("main"),
 				checkImportSyscallJS,
 				checkInitFunction,
+				checkNotContains(`"strconv"`),
 			},
 		},
 		{
@@ -102,7 +104,8 @@ func Validate(x int) error { return nil }`,
 			checks: []func(*testing.T, string){
 				checkContains(`err := Validate(x)`),
 				checkContains(`if err != nil {`),
-				checkContains(`return nil`),
+				checkContains(`return true`),
+				checkNotContains(`return nil`),
 			},
 		},
 		{
@@ -128,6 +131,68 @@ func GetUser() User { return User{} }`,
 				checkContains(`"age": result.Age`),
 			},
 		},
+		{
+			name: "struct slice parameter",
+			source: `package main
+type User struct {
+	Name string ` + "`json:\"name\"`" + `
+	Age  int    ` + "`json:\"age\"`" + `
+}
+type Team struct {
+	Members []User ` + "`json:\"members\"`" + `
+}
+func Import(users []User, t Team) int { return len(users) + len(t.Members) }`,
+			checks: []func(*testing.T, string){
+				checkContains(`result := make([]User, length)`),
+				checkContains(`arr := args[0]`),
+				checkContains(`arr := args[1].Get("members")`),
+				checkContains(`Name: arr.Index(i).Get("name").String(),`),
+				checkContains(`Age: arr.Index(i).Get("age").Int(),`),
+			},
+		},
+		{
+			name: "string-tagged struct fields",
+			source: `package main
+type Counter struct {
+	Count int ` + "`json:\"count,string\"`" + `
+}
+func Bump(c Counter) Counter { return c }`,
+			checks: []func(*testing.T, string){
+				checkContains(`"strconv"`),
+				checkContains(`strconv.ParseInt(args[0].Get("count").String(), 10, 64)`),
+				checkContains(`"count": fmt.Sprint(result.Count)`),
+			},
+		},
+		{
+			name: "skipped struct fields",
+			source: `package main
+type Account struct {
+	Name  string ` + "`json:\"name\"`" + `
+	Token string ` + "`json:\"-\"`" + `
+}
+func Rename(a Account) Account { return a }`,
+			checks: []func(*testing.T, string){
+				checkContains(`Name: args[0].Get("name").String(),`),
+				checkContains(`"name": result.Name,`),
+				checkNotContains(`Token`),
+			},
+		},
+		{
+			name: "omitempty struct fields",
+			source: `package main
+type Page struct {
+	Items int    ` + "`json:\"items\"`" + `
+	Next  string ` + "`json:\"next,omitempty\"`" + `
+}
+func Load(p Page) Page { return p }`,
+			checks: []func(*testing.T, string){
+				checkContains(`Items: args[0].Get("items").Int(),`),
+				checkContains(`if !args[0].Get("next").IsUndefined() {`),
+				checkContains(`out.Next = args[0].Get("next").String()`),
+				checkContains(`if result.Next != "" {`),
+				checkContains(`out["next"] = result.Next`),
+			},
+		},
 		{
 			name: "byte slice parameter",
 			source: `package main
@@ -137,6 +202,16 @@ func ProcessBytes(data []byte) int { return len(data) }`,
 				checkContains(`make([]byte, length)`),
 			},
 		},
+		{
+			name: "float64 slice parameter",
+			source: `package main
+func Sum(xs []float64) float64 { return 0 }`,
+			checks: []func(*testing.T, string){
+				checkContains(`"unsafe"`),
+				checkContains(`arr.InstanceOf(js.Global().Get("Float64Array"))`),
+				checkContains(`result[i] = arr.Index(i).Float()`),
+			},
+		},
 		{
 			name: "byte slice return",
 			source: `package main
@@ -155,13 +230,68 @@ func GetNumbers() []int32 { return []int32{1, 2, 3} }`,
 				checkContains(`SetIndex`),
 			},
 		},
+		{
+			name: "named primitive slice return (typed array)",
+			source: `package main
+type Score int32
+func Scores() []Score { return []Score{1, 2, 3} }
+func Best(scores []Score) Score { return scores[0] }
+type Label string
+func Labels() []Label { return nil }`,
+			checks: []func(*testing.T, string){
+				checkContains(`js.Global().Get("Int32Array").New(len(slice))`),
+				checkContains(`arr.SetIndex(i, int32(v))`),
+				checkContains(`make([]Score, length)`),
+				checkContains(`Score(int32(arr.Index(i).Int()))`),
+				checkContains(`return int32(result)`),
+				checkContains(`for i, v := range result {`),
+				checkContains(`out[i] = string(v)`),
+			},
+		},
+		{
+			name: "nested slice parameter and return",
+			source: `package main
+func Double(grid [][]int) [][]int { return grid }`,
+			checks: []func(*testing.T, string){
+				checkContains(`result := make([][]int, length)`),
+				// The inner slice reads its element before its own loop
+				// variable shadows i
+				checkContains("result[i] = func() []int {\n\t\tarr := arr.Index(i)\n"),
+				checkContains(`result[i] = arr.Index(i).Int()`),
+				checkContains(`out[i] = func() []interface{} {`),
+				checkContains(`for i, v := range v {`),
+			},
+		},
+		{
+			name: "rune parameter and return",
+			source: `package main
+func Upper(c rune) rune { return c }
+func Counts(s string) map[rune]int { return nil }`,
+			checks: []func(*testing.T, string){
+				checkContains(`c := []rune(args[0].String())[0]`),
+				checkContains(`return string(result)`),
+				checkContains(`out[string(k)] = v`),
+			},
+		},
+		{
+			name: "rune slice parameter and return",
+			source: `package main
+func Reverse(s []rune) []rune { return s }
+func Words(s string) [][]rune { return nil }`,
+			checks: []func(*testing.T, string){
+				checkContains(`s := []rune(args[0].String())`),
+				checkContains(`return string(result)`),
+				checkContains(`out[i] = string(v)`),
+			},
+		},
 		{
 			name: "string slice parameter",
 			source: `package main
 func JoinStrings(items []string) string { return "" }`,
 			checks: []func(*testing.T, string){
 				checkContains(`make([]string, length)`),
-				checkContains(`args[0].Index(i)`),
+				checkContains(`arr := args[0]`),
+				checkContains(`arr.Index(i)`),
 				checkContains(`.String()`),
 			},
 		},
@@ -175,6 +305,97 @@ func ProcessMap(data map[string]int) int { return 0 }`,
 				checkContains(`keys`),
 			},
 		},
+		{
+			name: "struct map return",
+			source: `package main
+type User struct {
+	Name string ` + "`json:\"name\"`" + `
+	Age  int
+}
+func Index() map[string]User { return nil }`,
+			checks: []func(*testing.T, string){
+				checkNotContains(`map[string]interface{}(result)`),
+				checkContains(`out := make(map[string]interface{}, len(result))`),
+				checkContains(`for k, v := range result {`),
+				checkContains(`out[k] = map[string]interface{}{`),
+				checkContains(`"name": v.Name,`),
+				checkContains(`"age": v.Age,`),
+			},
+		},
+		{
+			name: "struct map parameter",
+			source: `package main
+type User struct {
+	Name string ` + "`json:\"name\"`" + `
+	Age  int
+}
+func Load(data map[string]User) int { return 0 }`,
+			checks: []func(*testing.T, string){
+				checkContains(`result := make(map[string]User)`),
+				func(t *testing.T, output string) {
+					t.Helper()
+					// Each entry is built from its own JS object inside the key loop
+					start := strings.Index(output, "for i := 0; i < keys.Length(); i++ {")
+					end := strings.Index(output, "return result\n\t}()")
+					if start < 0 || end < start {
+						t.Fatalf("map extraction loop not found:\n%s", output)
+					}
+					loop := output[start:end]
+					for _, want := range []string{
+						`result[key] = func() User {`,
+						`Name: obj.Get(key).Get("name").String(),`,
+						`Age: obj.Get(key).Get("Age").Int(),`,
+					} {
+						if !strings.Contains(loop, want) {
+							t.Errorf("map loop missing %q:\n%s", want, loop)
+						}
+					}
+				},
+			},
+		},
+		{
+			name: "struct error field",
+			source: `package main
+type Result struct {
+	Data string ` + "`json:\"data\"`" + `
+	Err  error  ` + "`json:\"err\"`" + `
+	Warn error  ` + "`json:\"warn,omitempty\"`" + `
+}
+func Run() Result { return Result{} }
+func Echo(r Result) string { return r.Data }`,
+			checks: []func(*testing.T, string){
+				checkContains(`"errors"`),
+				checkContains(`if result.Err == nil {`),
+				checkContains(`return result.Err.Error()`),
+				checkContains(`if result.Warn != nil {`),
+				checkContains(`if msg := args[0].Get("err"); msg.Type() == js.TypeString {`),
+				checkContains(`return errors.New(msg.String())`),
+				checkNotContains(`"err": result.Err.Error()`),
+			},
+		},
+		{
+			name: "slice of maps return",
+			source: `package main
+func Rows() []map[string]int { return nil }`,
+			checks: []func(*testing.T, string){
+				checkNotContains(`map[string]interface{}(v)`),
+				checkContains(`out := make([]interface{}, len(result))`),
+				checkContains(`out[i] = func() map[string]interface{} {`),
+				checkContains(`out := make(map[string]interface{}, len(v))`),
+				checkContains(`out[k] = v`),
+			},
+		},
+		{
+			name: "map of slices return",
+			source: `package main
+func Groups() map[string][]int { return nil }`,
+			checks: []func(*testing.T, string){
+				checkContains(`out := make(map[string]interface{}, len(result))`),
+				checkContains(`out[k] = func() []interface{} {`),
+				checkContains(`out := make([]interface{}, len(v))`),
+				checkContains(`out[i] = v`),
+			},
+		},
 		{
 			name:       "callback sync mode",
 			workerMode: false,
@@ -199,7 +420,7 @@ func ForEach(items []string, cb func(string, int)) {}`,
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			parsed := mustParse(t, tt.source)
-			output := GenerateGoBindings(parsed, tt.workerMode)
+			output := GenerateGoBindings(parsed, Options{WorkerMode: tt.workerMode})
 
 			for _, check := range tt.checks {
 				check(t, output)
@@ -278,6 +499,15 @@ func checkContains(substr string) func(*testing.T, string) {
 	}
 }
 
+func checkNotContains(substr string) func(*testing.T, string) {
+	return func(t *testing.T, output string) {
+		t.Helper()
+		if strings.Contains(output, substr) {
+			t.Errorf("output contains unexpected content: %q", substr)
+		}
+	}
+}
+
 func assertValidGoSyntax(t *testing.T, code string) {
 	t.Helper()
 	fset := token.NewFileSet()
@@ -287,6 +517,29 @@ func assertValidGoSyntax(t *testing.T, code string) {
 	}
 }
 
+// assertCompiles type-checks the generated bindings together with source
+// for js/wasm, which catches code that parses but doesn't compile.
+func assertCompiles(t *testing.T, source, bindings string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":          "module example.com/compile\n\ngo 1.21\n",
+		"main.go":         source,
+		"bindings_gen.go": bindings,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm", "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("generated bindings do not compile: %v\n%s\n\nCode:\n%s", err, output, bindings)
+	}
+}
+
 func mustParse(t *testing.T, source string) *goparser.ParsedFile {
 	t.Helper()
 	tmpDir := t.TempDir()
@@ -300,3 +553,545 @@ func mustParse(t *testing.T, source string) *goparser.ParsedFile {
 	}
 	return parsed
 }
+
+func TestGenerateGoBindings_StringTaggedNamedPrimitive(t *testing.T) {
+	source := `package main
+
+type Score int32
+
+type Result struct {
+	ID    Score ` + "`json:\"sid,string\"`" + `
+	Count int   ` + "`json:\"count,string\"`" + `
+}
+
+func Rank(r Result) Result { return r }
+
+func main() { select {} }
+`
+	output := GenerateGoBindings(mustParse(t, source), Options{})
+	// Parsed through the underlying int32, then converted
+	checkContains(`strconv.ParseInt(args[0].Get("sid").String(), 10, 64)`)(t, output)
+	checkContains(`return Score(v)`)(t, output)
+	assertCompiles(t, source, output)
+}
+
+func TestGenerateGoBindings_TypedArrayBytes(t *testing.T) {
+	source := `package main
+
+type Score int32
+
+func Mix(xs []Score, fs []float32, ds []float64, ss []uint16, bs []int8) int { return 0 }
+
+func main() { select {} }
+`
+	for order, binary := range map[string]string{"little": "binary.LittleEndian", "big": "binary.BigEndian"} {
+		t.Run(order, func(t *testing.T) {
+			parsed := mustParse(t, source)
+			goparser.UseTypedArrayBytes(parsed, order)
+
+			output := GenerateGoBindings(parsed, Options{})
+			checkContains(`if js.Global().Get("ArrayBuffer").Call("isView", arr).Bool() {`)(t, output)
+			checkContains(`panic(fmt.Sprintf("%d bytes is not a whole number of int32 values", len(raw)))`)(t, output)
+			checkContains(`result[i] = Score(` + binary + `.Uint32(raw[i*4:]))`)(t, output)
+			checkContains(`result[i] = float32(math.Float32frombits(` + binary + `.Uint32(raw[i*4:])))`)(t, output)
+			checkContains(`result[i] = float64(math.Float64frombits(` + binary + `.Uint64(raw[i*8:])))`)(t, output)
+			checkContains(`result[i] = uint16(` + binary + `.Uint16(raw[i*2:]))`)(t, output)
+			checkContains(`result[i] = int8(raw[i])`)(t, output)
+			// The byte path replaces the unsafe copy of matching typed arrays
+			checkNotContains(`unsafe.`)(t, output)
+			assertCompiles(t, source, output)
+		})
+	}
+}
+
+func TestGenerateGoBindings_Diagnostics(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Greet(name string) string { return "Hello, " + name }`)
+
+	output := GenerateGoBindings(parsed, Options{Diagnostics: true})
+	checkContains(`js.Global().Set("__gowasmStats", recoverFunc(gowasmRuntimeStats))`)(t, output)
+	checkContains(`"runtime"`)(t, output)
+	checkContains(`runtime.ReadMemStats(&m)`)(t, output)
+	checkContains(`"numGoroutine": runtime.NumGoroutine()`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	output = GenerateGoBindings(parsed, Options{})
+	checkNotContains(`__gowasmStats`)(t, output)
+	checkNotContains(`"runtime"`)(t, output)
+}
+
+func TestGenerate_NameDirective(t *testing.T) {
+	parsed := mustParse(t, `package main
+
+//gowasm:name httpGet
+func HTTPGet(url string) string { return url }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`js.Global().Set("httpGet", recoverFunc(wasmHTTPGet))`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	checkContains("  httpGet(url: string): string {")(t, Generate(parsed, "client.ts", "Wasm", Options{}))
+	client := GenerateClient(parsed, "client.ts", "Wasm", Options{})
+	checkContains("  httpGet(url: string, options?: { signal?: AbortSignal }): Promise<string> {")(t, client)
+	checkContains(`this.call<string>("httpGet", [url]`)(t, client)
+}
+
+func TestGenerateGoBindings_PanicRecovery(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Greet(name string) string { return "Hello, " + name }
+func Divide(a, b int) (int, error) { return a / b, nil }
+func Log(msg string) {}
+func Each(items []string, cb func(string)) {}`)
+
+	for _, opts := range []Options{{}, {WorkerMode: true}, {Namespace: "mylib"}} {
+		output := GenerateGoBindings(parsed, opts)
+
+		// The decorator converts a panic into an __error result instead of trapping
+		checkContains("defer func() {\n\t\t\tif r := recover(); r != nil {")(t, output)
+		checkContains(`ret = map[string]interface{}{ErrorFieldName: fmt.Sprintf("panic: %v", r)}`)(t, output)
+
+		// Every wrapper is registered through the decorator
+		for _, fn := range parsed.Functions {
+			checkContains(`.Set("`+LowerFirst(fn.Name)+`", recoverFunc(wasm`+fn.Name+`))`)(t, output)
+		}
+		if got := strings.Count(output, "js.FuncOf("); got != 1 {
+			t.Errorf("got %d js.FuncOf calls, want only the one inside recoverFunc", got)
+		}
+	}
+}
+
+func TestGenerateGoBindings_Time(t *testing.T) {
+	parsed := mustParse(t, `package main
+import "time"
+func Later(t time.Time, d int) time.Time { return t }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`"time"`)(t, output)
+	checkContains(`t := time.UnixMilli(int64(args[0].Call("getTime").Float()))`)(t, output)
+	checkContains(`return js.Global().Get("Date").New(result.UnixMilli())`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	// "runtime." must not pull in the time package
+	parsed = mustParse(t, `package main
+func Greet(name string) string { return "Hello, " + name }`)
+	output = GenerateGoBindings(parsed, Options{Diagnostics: true})
+	checkNotContains(`"time"`)(t, output)
+}
+
+func TestGenerateGoBindings_ImportsFollowEmittedCode(t *testing.T) {
+	// Strings that look like package selectors import nothing
+	source := `package main
+
+type Point struct {
+	X int ` + "`json:\"time.x\"`" + `
+	Y int ` + "`json:\"strconv.y\"`" + `
+}
+
+func Move(p Point) Point { return p }
+
+func main() { select {} }
+`
+	output := GenerateGoBindings(mustParse(t, source), Options{})
+	checkContains(`X: args[0].Get("time.x").Int(),`)(t, output)
+	checkContains("import (\n\t\"fmt\"\n\t\"syscall/js\"\n)")(t, output)
+	assertCompiles(t, source, output)
+
+	// A callback's time.Time parameter is spelled out in its wrapper
+	source = `package main
+
+import "time"
+
+func Each(cb func(at time.Time)) {}
+
+func main() { select {} }
+`
+	for _, workerMode := range []bool{false, true} {
+		output = GenerateGoBindings(mustParse(t, source), Options{WorkerMode: workerMode})
+		checkContains(`"time"`)(t, output)
+		assertCompiles(t, source, output)
+	}
+}
+
+func TestGenerateGoBindings_Any(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Handle(v interface{}, tags []any) any { return v }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`"encoding/json"`)(t, output)
+	checkContains(`v := gowasmDecodeAny(args[0])`)(t, output)
+	checkContains(`result[i] = gowasmDecodeAny(arr.Index(i))`)(t, output)
+	checkContains(`return gowasmEncodeAny(result)`)(t, output)
+	checkContains(`func gowasmDecodeAny(v js.Value) interface{} {`)(t, output)
+	checkContains(`js.Global().Get("JSON").Call("stringify", v).String()`)(t, output)
+	checkContains(`func gowasmEncodeAny(v interface{}) interface{} {`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	// Helpers and the json import are only emitted when used
+	parsed = mustParse(t, `package main
+func Greet(name string) string { return "Hello, " + name }`)
+	output = GenerateGoBindings(parsed, Options{})
+	checkNotContains(`"encoding/json"`)(t, output)
+	checkNotContains(`gowasmDecodeAny`)(t, output)
+}
+
+func TestGenerateGoBindings_PointerSliceReturn(t *testing.T) {
+	parsed := mustParse(t, `package main
+type User struct {
+	Name string
+}
+func Users() []*User { return []*User{{Name: "a"}, nil} }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	// Each element is checked, so a nil entry becomes null instead of panicking
+	checkContains("for i, v := range result {\n\t\t\tout[i] = func() interface{} {\n\t\tif v == nil {\n\t\t\treturn nil\n\t\t}")(t, output)
+	assertValidGoSyntax(t, output)
+
+	checkContains("  users(): (User | null)[] {")(t, Generate(parsed, "client.ts", "Wasm", Options{}))
+}
+
+func TestGenerateGoBindings_JSONStructs(t *testing.T) {
+	parsed := mustParse(t, `package main
+type Doc struct {
+	Title string `+"`json:\"title\"`"+`
+	Data  []byte
+}
+func Echo(d Doc, ds []*Doc) Doc { return d }`)
+	goparser.UseJSONStructs(parsed)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`"encoding/json"`)(t, output)
+	checkContains("var out Doc\n\t\tgowasmDecodeJSON(args[0], &out)")(t, output)
+	checkContains(`gowasmDecodeJSON(arr.Index(i), &out)`)(t, output)
+	checkContains(`return gowasmEncodeAny(result)`)(t, output)
+	checkContains(`func gowasmDecodeJSON(v js.Value, out interface{}) {`)(t, output)
+	checkNotContains(`gowasmDecodeAny`)(t, output)
+	checkNotContains(`CopyBytesToGo`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	// The interface describes the JSON encoding
+	checkContains("export interface Doc {\n  title: string;\n  Data: string | null;\n}")(t, Generate(parsed, "client.ts", "Wasm", Options{}))
+}
+
+func TestGenerateGoBindings_Base64Bytes(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Echo(b []byte) []byte { return b }`)
+	goparser.UseBase64Bytes(parsed)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`"encoding/base64"`)(t, output)
+	checkContains(`base64.StdEncoding.DecodeString(args[0].String())`)(t, output)
+	checkContains(`return base64.StdEncoding.EncodeToString(result)`)(t, output)
+	checkNotContains(`js.CopyBytesToJS`)(t, output)
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_PointerFields(t *testing.T) {
+	parsed := mustParse(t, `package main
+type User struct {
+	Name    string `+"`json:\"name\"`"+`
+	Manager *User  `+"`json:\"manager\"`"+`
+	Size    *int   `+"`json:\"size\"`"+`
+}
+func Promote(u User) User { return u }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`u := gowasmUserFromJS(args[0])`)(t, output)
+	checkContains(`return gowasmUserToJS(result)`)(t, output)
+	checkContains(`func gowasmUserFromJS(v js.Value) User {`)(t, output)
+	checkContains(`if v.Get("manager").IsNull() || v.Get("manager").IsUndefined() {`)(t, output)
+	checkContains(`elem := gowasmUserFromJS(v.Get("manager"))`)(t, output)
+	checkContains(`elem := v.Get("size").Int()`)(t, output)
+	checkContains(`func gowasmUserToJS(v User) interface{} {`)(t, output)
+	checkContains(`if v.Manager == nil {`)(t, output)
+	checkContains(`return gowasmUserToJS((*v.Manager))`)(t, output)
+	checkContains(`return (*v.Size)`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	// Helpers are only emitted for recursive structs the bindings use
+	parsed = mustParse(t, `package main
+type Node struct { Next *Node }
+func Greet(name string) string { return name }`)
+	output = GenerateGoBindings(parsed, Options{})
+	checkNotContains(`gowasmNode`)(t, output)
+}
+
+func TestGenerateGoBindings_StructHelpers(t *testing.T) {
+	source := `package main
+type Inner struct { Label string }
+type Outer struct { Inner Inner; Others []Inner }
+type Unused struct { N int }
+func Echo(o Outer) Outer { return o }
+func First(items []Inner) Inner { return items[0] }`
+
+	inline := GenerateGoBindings(mustParse(t, source), Options{})
+	checkNotContains(`gowasmInnerFromJS`)(t, inline)
+
+	parsed := mustParse(t, source)
+	goparser.UseStructHelpers(parsed)
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`o := gowasmOuterFromJS(args[0])`)(t, output)
+	checkContains(`return gowasmOuterToJS(result)`)(t, output)
+	checkContains(`result[i] = gowasmInnerFromJS(arr.Index(i))`)(t, output)
+	checkContains(`return gowasmInnerToJS(result)`)(t, output)
+	checkContains(`Inner: gowasmInnerFromJS(v.Get("Inner")),`)(t, output)
+	checkNotContains(`gowasmUnused`)(t, output)
+	// Each helper is emitted once
+	if n := strings.Count(output, "func gowasmInnerFromJS("); n != 1 {
+		t.Errorf("gowasmInnerFromJS defined %d times, want 1", n)
+	}
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_ResultUnion(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Find(name string) (int, error) { return 0, nil }
+func Check(n int) error { return nil }
+func Plain() int { return 0 }`)
+	goparser.UseResultUnion(parsed)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`return map[string]interface{}{"ok": false, "error": err.Error()}`)(t, output)
+	checkContains(`return map[string]interface{}{"ok": true, "value": result}`)(t, output)
+	checkContains(`return map[string]interface{}{"ok": true}`)(t, output)
+	// Panics and functions without an error result are unchanged
+	checkContains(`ret = map[string]interface{}{ErrorFieldName: fmt.Sprintf("panic: %v", r)}`)(t, output)
+	checkNotContains(`{ErrorFieldName: err.Error()}`)(t, output)
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_Namespace(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Greet(name string) string { return "Hello, " + name }`)
+
+	output := GenerateGoBindings(parsed, Options{Namespace: "mylib", Diagnostics: true})
+	checkContains(`ns := js.Global().Get("mylib")`)(t, output)
+	checkContains(`ns = js.ValueOf(map[string]interface{}{})`)(t, output)
+	checkContains(`js.Global().Set("mylib", ns)`)(t, output)
+	checkContains(`ns.Set("greet", recoverFunc(wasmGreet))`)(t, output)
+	checkContains(`ns.Set("__gowasmStats", recoverFunc(gowasmRuntimeStats))`)(t, output)
+	checkNotContains(`js.Global().Set("greet"`)(t, output)
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_OptionalPointer(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Search(query string, limit *int) string { return query }
+func Greet(name string) string { return name }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	// Missing trailing arguments become undefined, then nil
+	checkContains("for len(args) < 2 {\n\t\targs = append(args, js.Undefined())\n\t}")(t, output)
+	checkContains(`if args[1].IsNull() || args[1].IsUndefined() {`)(t, output)
+	checkContains(`elem := args[1].Int()`)(t, output)
+	if n := strings.Count(output, "js.Undefined()"); n != 1 {
+		t.Errorf("argument padding emitted %d times, want only for Search", n)
+	}
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_GoPackage(t *testing.T) {
+	parsed := mustParse(t, `package wasm
+func Greet(name string) string { return "Hello, " + name }`)
+
+	checkContains("\npackage wasm\n")(t, GenerateGoBindings(parsed, Options{}))
+	output := GenerateGoBindings(parsed, Options{GoPackage: "bindings"})
+	checkContains("\npackage bindings\n")(t, output)
+	checkNotContains("package wasm")(t, output)
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateErrorKey(t *testing.T) {
+	parsed := mustParse(t, `package main
+import "errors"
+func Check(n int) (int, error) { return 0, errors.New("bad") }`)
+	opts := Options{ErrorKey: "gowasmErr"}
+
+	output := GenerateGoBindings(parsed, opts)
+	checkContains(`const ErrorFieldName = "gowasmErr"`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	for name, got := range map[string]string{
+		"sync":   Generate(parsed, "client.ts", "Wasm", opts),
+		"worker": GenerateClient(parsed, "client.ts", "Wasm", opts),
+		"dts":    GenerateDeclarations(parsed, "client.d.ts", opts),
+	} {
+		if strings.Contains(got, "__error") {
+			t.Errorf("%s output still uses __error:\n%s", name, got)
+		}
+	}
+	checkContains(`throw new WasmError(__result.gowasmErr);`)(t, Generate(parsed, "client.ts", "Wasm", opts))
+	worker := GenerateClient(parsed, "client.ts", "Wasm", opts)
+	checkContains(`x is { gowasmErr: string }`)(t, worker)
+	checkContains(`'gowasmErr' in x`)(t, worker)
+	checkContains(`handler.reject(new WasmError(result.gowasmErr));`)(t, worker)
+	checkContains(`check(n: number): number | { gowasmErr: string };`)(t, GenerateDeclarations(parsed, "client.d.ts", opts))
+}
+
+func TestGenerateGoBindings_MethodsOf(t *testing.T) {
+	parsed := mustParse(t, `package main
+type Service struct{ calls int }
+func (s *Service) Do(name string) string { s.calls++; return name }
+func Hello(name string) string { return "hi " + name }`)
+	if err := goparser.UseMethodsOf(parsed, "Service"); err != nil {
+		t.Fatal(err)
+	}
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`var gowasmInstance = new(Service)`)(t, output)
+	checkContains(`js.Global().Set("do", recoverFunc(wasmDo))`)(t, output)
+	checkContains(`result := gowasmInstance.Do(name)`)(t, output)
+	checkContains(`result := Hello(name)`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	if output := GenerateGoBindings(mustParse(t, "package main\nfunc Hello() {}"), Options{}); strings.Contains(output, "gowasmInstance") {
+		t.Errorf("instance declared without bound methods:\n%s", output)
+	}
+}
+
+func TestGenerateGoBindings_Context(t *testing.T) {
+	parsed := mustParse(t, `package main
+import "context"
+func Lookup(ctx context.Context, id int) (string, error) { return "", ctx.Err() }
+func Ping(ctx context.Context) {}`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`"context"`)(t, output)
+	checkContains(`id := args[0].Int()`)(t, output)
+	checkContains(`result, err := Lookup(context.Background(), id)`)(t, output)
+	checkContains(`Ping(context.Background())`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	checkContains(`lookup(id: number): string {`)(t, Generate(parsed, "client.ts", "Wasm", Options{}))
+	checkContains(`lookup(id: number, options?: { signal?: AbortSignal }): Promise<string> {`)(t, GenerateClient(parsed, "client.ts", "Wasm", Options{}))
+}
+
+func TestGenerateGoBindings_CollidingParamNames(t *testing.T) {
+	parsed := mustParse(t, `package main
+type Color int
+const (
+	Red Color = iota
+	Green
+)
+func Echo(args string, result int, p0 string, len []int32, ok bool) (string, error) { return args, nil }
+func Paint(Color Color, _ int, json ...string) {}`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`_p0 := args[0].String()`)(t, output)
+	checkContains(`p1 := args[1].Int()`)(t, output)
+	checkContains(`p0 := args[2].String()`)(t, output)
+	checkContains(`ok := args[4].Bool()`)(t, output)
+	checkContains(`result, err := Echo(_p0, p1, p0, p3, ok)`)(t, output)
+	checkContains(`if p0 != Red && p0 != Green {`)(t, output)
+	checkContains(`"invalid Color for Color: %v", p0`)(t, output)
+	checkContains(`Paint(p0, p1, p2...)`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	// The TypeScript signature keeps the Go names
+	checkContains(`echo(args: string, result: number, p0: string, len: Int32Array, ok: boolean): string {`)(t, Generate(parsed, "client.ts", "Wasm", Options{}))
+}
+
+func TestGenerateGoBindings_StreamBytes(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Blur(data []byte) ([]byte, error) { return data, nil }
+func Greet(name string) string { return "Hello, " + name }`)
+
+	output := GenerateGoBindings(parsed, Options{WorkerMode: true, StreamBytes: true})
+	checkContains(`return gowasmStreamBytes(result)`)(t, output)
+	checkContains(`func gowasmStreamBytes(data []byte) interface{} {`)(t, output)
+	checkContains(`postChunk := js.Global().Get("postChunk")`)(t, output)
+	checkContains(`return map[string]interface{}{"__chunks": len(data)}`)(t, output)
+	checkContains(`return result
+}`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	// Sync mode has no worker to post chunks from
+	output = GenerateGoBindings(parsed, Options{StreamBytes: true})
+	checkNotContains(`gowasmStreamBytes`)(t, output)
+
+	// No helper without a []byte result
+	parsed = mustParse(t, `package main
+func Greet(name string) string { return "Hello, " + name }`)
+	output = GenerateGoBindings(parsed, Options{WorkerMode: true, StreamBytes: true})
+	checkNotContains(`gowasmStreamBytes`)(t, output)
+}
+
+func TestGenerateGoBindings_SharedMemory(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Blob(n int) ([]byte, error) { return make([]byte, n), nil }
+func Greet(name string) string { return "Hello, " + name }`)
+
+	output := GenerateGoBindings(parsed, Options{WorkerMode: true, SharedMemory: true})
+	checkContains(`js.Global().Get("SharedArrayBuffer")`)(t, output)
+	checkContains(`js.Global().Get("crossOriginIsolated").Truthy()`)(t, output)
+	checkContains(`js.CopyBytesToJS(arr, result)`)(t, output)
+	checkContains(`return result
+}`)(t, output)
+	assertValidGoSyntax(t, output)
+
+	output = GenerateGoBindings(parsed, Options{WorkerMode: true})
+	checkNotContains(`SharedArrayBuffer`)(t, output)
+
+	parsed = mustParse(t, `package main
+func Series(n int) []float64 { return make([]float64, n) }`)
+	output = GenerateGoBindings(parsed, Options{WorkerMode: true, SharedMemory: true})
+	checkContains(`js.Global().Get("Float64Array").New(sab.New(len(slice) * int(unsafe.Sizeof(slice[0]))))`)(t, output)
+	checkContains(`"unsafe"`)(t, output)
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_EnumParams(t *testing.T) {
+	parsed := mustParse(t, `package main
+type Color int
+const (
+	Red Color = iota
+	Green
+)
+type Level int
+func Paint(c Color, l Level) string { return "" }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`if c != Red && c != Green {`)(t, output)
+	checkContains(`return map[string]interface{}{ErrorFieldName: fmt.Sprintf("invalid Color for c: %v", c)}`)(t, output)
+	checkNotContains(`l != `)(t, output)
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_OpenEnumParams(t *testing.T) {
+	parsed := mustParse(t, `package main
+type Celsius float64
+const Freezing Celsius = 0
+type Perm int
+const (
+	Read Perm = 1 << iota
+	Write
+)
+type Level int
+const (
+	Low  Level = 1
+	High Level = 10
+)
+func Warm(c Celsius) string { return "" }
+func Open(p Perm) string { return "" }
+func Set(l Level) string { return "" }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	// Other temperatures, flag combinations such as Read|Write, and
+	// values between explicit constants are all valid
+	checkNotContains(`c != Freezing`)(t, output)
+	checkNotContains(`!= Read`)(t, output)
+	checkNotContains(`!= Low`)(t, output)
+	checkNotContains(`invalid `)(t, output)
+	assertValidGoSyntax(t, output)
+}
+
+func TestGenerateGoBindings_VariadicParams(t *testing.T) {
+	parsed := mustParse(t, `package main
+func Join(sep string, parts ...string) string { return "" }`)
+
+	output := GenerateGoBindings(parsed, Options{})
+	checkContains(`sep := args[0].String()`)(t, output)
+	checkContains(`for i := 1; i < len(args); i++ {`)(t, output)
+	checkContains(`result = append(result, args[i].String())`)(t, output)
+	checkContains(`Join(sep, parts...)`)(t, output)
+	assertValidGoSyntax(t, output)
+}
@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// checksumPrefix starts the header line that records the source checksum.
+const checksumPrefix = "// Source checksum: "
+
+// checksumScanLines limits how far into a file ReadChecksum looks for the header.
+const checksumScanLines = 10
+
+// ChecksumSource returns the checksum of Go source bytes in the form
+// "sha256:<hex>", as recorded in generated file headers.
+func ChecksumSource(src []byte) string {
+	sum := sha256.Sum256(src)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ReadChecksum returns the source checksum recorded in the header of a
+// generated file, or false if the file has none.
+func ReadChecksum(content []byte) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for i := 0; i < checksumScanLines && scanner.Scan(); i++ {
+		if checksum, ok := strings.CutPrefix(scanner.Text(), checksumPrefix); ok {
+			return strings.TrimSpace(checksum), true
+		}
+	}
+	return "", false
+}
+
+// checksumHeader returns the checksum header line, or "" if no checksum is set.
+func checksumHeader(opts Options) string {
+	if opts.SourceChecksum == "" {
+		return ""
+	}
+	return checksumPrefix + opts.SourceChecksum + "\n"
+}
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+func TestChecksumSource(t *testing.T) {
+	got := ChecksumSource([]byte("package main\n"))
+	if !strings.HasPrefix(got, "sha256:") || len(got) != len("sha256:")+64 {
+		t.Errorf("ChecksumSource() = %q, want sha256:<64 hex chars>", got)
+	}
+	if got == ChecksumSource([]byte("package other\n")) {
+		t.Error("ChecksumSource() should differ for different sources")
+	}
+}
+
+func TestReadChecksum(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Greet", Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}}},
+		},
+	}
+	opts := Options{SourceChecksum: "sha256:abc123"}
+
+	for name, content := range map[string]string{
+		"bindings":    GenerateGoBindings(parsed, opts),
+		"sync client": Generate(parsed, "client.ts", "Wasm", opts),
+		"worker":      GenerateClient(parsed, "client.ts", "Wasm", opts),
+	} {
+		got, ok := ReadChecksum([]byte(content))
+		if !ok || got != "sha256:abc123" {
+			t.Errorf("%s: ReadChecksum() = %q, %v, want %q, true", name, got, ok, "sha256:abc123")
+		}
+	}
+
+	if _, ok := ReadChecksum([]byte(GenerateGoBindings(parsed, Options{}))); ok {
+		t.Error("ReadChecksum() should report no checksum when none was recorded")
+	}
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+// DemoFileName is the manual test page written by --emit-demo.
+const DemoFileName = "index.html"
+
+// GenerateDemo creates a bare HTML page that initializes the client and
+// exposes it on window.wasm, for calling the functions from the browser
+// console. Browsers can't load TypeScript, so the page imports entry, the
+// client module (or its init module with --split-client) compiled to
+// JavaScript next to it, e.g. "go-wasm.js". initURL is what init is called
+// with: worker.js in worker mode, the .wasm file in sync mode.
+func GenerateDemo(parsed *parser.ParsedFile, className, entry, initURL string, opts Options) string {
+	var b strings.Builder
+
+	source := strings.TrimSuffix(entry, ".js") + ".ts"
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<!-- %s - Generated by gowasm-bindgen
+     Package: %s
+
+     Manual test page for %s. Compile the client to JavaScript, serve
+     this directory, and call the functions on window.wasm from the console:
+       npx esbuild %s --bundle --format=esm --outfile=%s
+       npx serve .
+-->
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+`, DemoFileName, parsed.Package, className, source, entry, className)
+	if !opts.WorkerMode {
+		// The sync client runs Go on this page, so it needs the Go runtime
+		b.WriteString("<script src=\"wasm_exec.js\"></script>\n")
+	}
+	b.WriteString("<script type=\"module\">\n")
+
+	b.WriteString("/*\n * Methods of window.wasm:\n")
+	for _, fn := range parsed.Functions {
+		returnType := determineReturnType(fn)
+		if opts.WorkerMode {
+			returnType = "Promise<" + returnType + ">"
+		}
+		fmt.Fprintf(&b, " *   %s(%s): %s\n", fn.BindingName(), generateFunctionParams(fn.Params), returnType)
+	}
+	b.WriteString(" */\n")
+
+	if opts.SplitClient {
+		fmt.Fprintf(&b, "import { init } from './%s';\n\n", entry)
+		fmt.Fprintf(&b, "window.wasm = await init('./%s');\n", initURL)
+	} else {
+		fmt.Fprintf(&b, "import { %s } from './%s';\n\n", className, entry)
+		fmt.Fprintf(&b, "window.wasm = await %s.init('./%s');\n", className, initURL)
+	}
+	b.WriteString("document.getElementById('status').textContent = 'Ready: call the methods of window.wasm from the console.';\n")
+	b.WriteString("</script>\n")
+	b.WriteString("</head>\n")
+	b.WriteString("<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", className)
+	b.WriteString("<p id=\"status\">Loading...</p>\n")
+	b.WriteString("</body>\n")
+	b.WriteString("</html>\n")
+
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+func TestGenerateDemo(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{
+				Name:    "Greet",
+				Params:  []parser.GoParameter{{Name: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+				Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+			},
+			{Name: "Reset"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		entry   string
+		initURL string
+		opts    Options
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "worker",
+			entry:   "go-wasm.js",
+			initURL: "worker.js",
+			opts:    Options{WorkerMode: true},
+			want: []string{
+				"npx esbuild go-wasm.ts --bundle --format=esm --outfile=go-wasm.js",
+				" *   greet(name: string): Promise<string>\n",
+				" *   reset(): Promise<void>\n",
+				"import { GoWasm } from './go-wasm.js';",
+				"window.wasm = await GoWasm.init('./worker.js');",
+			},
+			notWant: []string{"wasm_exec.js"},
+		},
+		{
+			name:    "sync",
+			entry:   "go-wasm.js",
+			initURL: "wasm.wasm",
+			want: []string{
+				"<script src=\"wasm_exec.js\"></script>",
+				" *   greet(name: string): string\n",
+				"window.wasm = await GoWasm.init('./wasm.wasm');",
+			},
+		},
+		{
+			name:    "split client",
+			entry:   "go-wasm-init.js",
+			initURL: "worker.js",
+			opts:    Options{WorkerMode: true, SplitClient: true},
+			want: []string{
+				"npx esbuild go-wasm-init.ts --bundle --format=esm --outfile=go-wasm-init.js",
+				"import { init } from './go-wasm-init.js';",
+				"window.wasm = await init('./worker.js');",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateDemo(parsed, "GoWasm", tt.entry, tt.initURL, tt.opts)
+			if !strings.HasPrefix(got, "<!DOCTYPE html>\n<!-- index.html - Generated by gowasm-bindgen") {
+				t.Errorf("missing doctype or header:\n%s", got)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("GenerateDemo() missing %q in output:\n%s", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("GenerateDemo() should not contain %q:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+// tsErrorResult returns the TypeScript shape of the error object a global
+// returns in place of throwing, whose error field is key.
+func tsErrorResult(key string) string {
+	return "{ " + key + ": string }"
+}
+
+// GenerateDeclarations creates an ambient .d.ts describing the globals
+// registered by the Go bindings, for plain JavaScript projects that call them
+// directly instead of through a generated client class. Functions that return
+// an error are typed with the raw { __error } object the global returns.
+func GenerateDeclarations(parsed *parser.ParsedFile, outputFile string, opts Options) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// %s - Generated by gowasm-bindgen --dts-only\n// Package: %s\n", outputFile, parsed.Package)
+	b.WriteString(checksumHeader(opts))
+	b.WriteString("\n")
+	b.WriteString(generateBrandedTypes(parsed.Types))
+	b.WriteString(generateNamedInterfaces(parsed.Types))
+
+	// Generate named interfaces for struct return types
+	for _, fn := range parsed.Functions {
+		if iface := generateInterfaceForFunction(fn); iface != "" {
+			b.WriteString(iface)
+			b.WriteString("\n\n")
+		}
+	}
+	if opts.Diagnostics {
+		b.WriteString(tsRuntimeStatsInterface)
+		b.WriteString("\n\n")
+	}
+
+	// Function and var declarations in a global block are visible as bare
+	// globals, on globalThis, and on window
+	b.WriteString("declare global {\n")
+	indent := "  "
+	if opts.Namespace != "" {
+		fmt.Fprintf(&b, "  var %s: {\n", opts.Namespace)
+		indent = "    "
+	}
+
+	for i, fn := range parsed.Functions {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(generateDeclaration(fn, indent, opts.Namespace != "", errorKey(opts)))
+	}
+	if opts.Diagnostics {
+		if len(parsed.Functions) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(indentLines(tsStatsDoc, indent[2:]))
+		b.WriteString(indent)
+		if opts.Namespace == "" {
+			b.WriteString("function ")
+		}
+		b.WriteString(DiagnosticsFuncName)
+		b.WriteString("(): RuntimeStats;\n")
+	}
+
+	if opts.Namespace != "" {
+		b.WriteString("  };\n")
+	}
+	b.WriteString("}\n\nexport {};\n")
+
+	return b.String()
+}
+
+// generateDeclaration declares one global function, or a method signature
+// when the functions live on a namespace object.
+func generateDeclaration(fn parser.GoFunction, indent string, method bool, errorKey string) string {
+	var b strings.Builder
+
+	b.WriteString(indentLines(generateJSDoc(fn), indent[2:]))
+
+	returnType := determineReturnType(fn)
+	if len(fn.Returns) > 0 && fn.Returns[len(fn.Returns)-1].IsError {
+		if len(fn.Returns) == 1 && !fn.ResultUnion {
+			// Error-only functions return true on success
+			returnType = "true"
+		}
+		returnType += " | " + tsErrorResult(errorKey)
+	}
+
+	b.WriteString(indent)
+	if !method {
+		b.WriteString("function ")
+	}
+	b.WriteString(fn.BindingName())
+	b.WriteString("(")
+	b.WriteString(generateFunctionParams(fn.Params))
+	b.WriteString("): ")
+	b.WriteString(returnType)
+	b.WriteString(";\n")
+
+	return b.String()
+}
+
+// indentLines prefixes every non-empty line of s with prefix.
+func indentLines(s, prefix string) string {
+	if prefix == "" || s == "" {
+		return s
+	}
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" && line != "\n" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
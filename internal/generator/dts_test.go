@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+func TestGenerateDeclarations(t *testing.T) {
+	str := parser.GoType{Name: "string", Kind: parser.KindPrimitive}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{
+				Name:    "Greet",
+				Doc:     "Greet says hello to name.",
+				Params:  []parser.GoParameter{{Name: "name", Type: str}},
+				Returns: []parser.GoType{str},
+			},
+			{
+				Name:    "Parse",
+				Params:  []parser.GoParameter{{Name: "input", Type: str}},
+				Returns: []parser.GoType{str, {Name: "error", Kind: parser.KindError, IsError: true}},
+			},
+			{
+				Name:    "Check",
+				Params:  []parser.GoParameter{{Name: "input", Type: str}},
+				Returns: []parser.GoType{{Name: "error", Kind: parser.KindError, IsError: true}},
+			},
+			{
+				Name: "GetInfo",
+				Returns: []parser.GoType{{
+					Name:   "struct",
+					Kind:   parser.KindStruct,
+					Fields: []parser.GoField{{Name: "Name", JSONTag: "name", Type: str}},
+				}},
+			},
+		},
+		Types: map[string]*parser.GoType{},
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "globals",
+			opts: Options{},
+			want: []string{
+				"// client.d.ts - Generated by gowasm-bindgen --dts-only",
+				"export interface GetInfoResult {\n  name: string;\n}",
+				"declare global {\n  /**\n   * Greet says hello to name.\n   * @param name\n   */\n  function greet(name: string): string;",
+				"  function parse(input: string): string | { __error: string };",
+				"  function check(input: string): true | { __error: string };",
+				"  function getInfo(): GetInfoResult;",
+				"}\n\nexport {};\n",
+			},
+			notWant: []string{"class ", "RuntimeStats", "var "},
+		},
+		{
+			name: "namespace",
+			opts: Options{Namespace: "mylib"},
+			want: []string{
+				"declare global {\n  var mylib: {\n    /**\n     * Greet says hello to name.",
+				"    greet(name: string): string;",
+				"    parse(input: string): string | { __error: string };",
+				"  };\n}",
+			},
+			notWant: []string{"function "},
+		},
+		{
+			name: "diagnostics",
+			opts: Options{Diagnostics: true},
+			want: []string{
+				"export interface RuntimeStats {",
+				"  function __gowasmStats(): RuntimeStats;",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateDeclarations(parsed, "client.d.ts", tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("GenerateDeclarations() missing %q in output:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("GenerateDeclarations() should not contain %q in output:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
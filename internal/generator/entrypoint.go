@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EntrypointHeader starts every entrypoint GenerateEntrypoint writes, so an
+// existing main.go can be told apart from a hand-written one before it is
+// replaced.
+const EntrypointHeader = "// Code generated by gowasm-bindgen. DO NOT EDIT."
+
+// GenerateEntrypoint generates the main package for --entrypoint, which
+// builds the library package at importPath into a WASM module. The bindings
+// live in the library itself and register its functions from init, so the
+// entrypoint only needs to import it for its side effects and keep running.
+func GenerateEntrypoint(importPath string) string {
+	var b strings.Builder
+
+	b.WriteString("//go:build js && wasm\n\n")
+	b.WriteString(EntrypointHeader)
+	b.WriteString("\n\n")
+	fmt.Fprintf(&b, "// Command wasm exports the functions of %s to JavaScript.\n", importPath)
+	b.WriteString("package main\n\n")
+	fmt.Fprintf(&b, "import _ %q\n\n", importPath)
+	b.WriteString("func main() {\n")
+	b.WriteString("\t// Block forever so the registered functions stay callable\n")
+	b.WriteString("\tselect {}\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateEntrypoint(t *testing.T) {
+	output := GenerateEntrypoint("example.com/mylib/geo")
+
+	if !strings.HasPrefix(output, "//go:build js && wasm\n\n"+EntrypointHeader+"\n") {
+		t.Errorf("missing build constraint or header:\n%s", output)
+	}
+	checkContains("package main\n")(t, output)
+	checkContains(`import _ "example.com/mylib/geo"`)(t, output)
+	checkContains("select {}")(t, output)
+	assertValidGoSyntax(t, output)
+}
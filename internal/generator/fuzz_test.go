@@ -101,7 +101,7 @@ func GetOuter() Outer { return Outer{} }`,
 		}
 
 		// Validate
-		if err := validator.ValidateFunctions(parsed); err != nil {
+		if err := validator.ValidateFunctions(parsed, validator.Options{}); err != nil {
 			return // Invalid for generation
 		}
 
@@ -111,19 +111,19 @@ func GetOuter() Outer { return Outer{} }`,
 		}
 
 		// Generate sync mode TypeScript - should not panic
-		_ = Generate(parsed, "test.ts", "TestWasm")
+		_ = Generate(parsed, "test.ts", "TestWasm", Options{})
 
 		// Generate worker mode TypeScript - should not panic
-		_ = GenerateClient(parsed, "test.ts", "TestWasm")
+		_ = GenerateClient(parsed, "test.ts", "TestWasm", Options{})
 
 		// Generate Go bindings (sync mode) - should not panic
-		_ = GenerateGoBindings(parsed, false)
+		_ = GenerateGoBindings(parsed, Options{})
 
 		// Generate Go bindings (worker mode) - should not panic
-		_ = GenerateGoBindings(parsed, true)
+		_ = GenerateGoBindings(parsed, Options{WorkerMode: true})
 
 		// Generate worker.js - should not panic
-		_ = GenerateWorker("test.wasm")
+		_ = GenerateWorker("test.wasm", Options{})
 	})
 }
 
@@ -159,7 +159,7 @@ func GetUser() User { return User{} }`,
 			return
 		}
 
-		if err := validator.ValidateFunctions(parsed); err != nil {
+		if err := validator.ValidateFunctions(parsed, validator.Options{}); err != nil {
 			return
 		}
 
@@ -168,7 +168,7 @@ func GetUser() User { return User{} }`,
 		}
 
 		// Test both modes - should not panic
-		_ = GenerateGoBindings(parsed, false)
-		_ = GenerateGoBindings(parsed, true)
+		_ = GenerateGoBindings(parsed, Options{})
+		_ = GenerateGoBindings(parsed, Options{WorkerMode: true})
 	})
 }
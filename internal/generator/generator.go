@@ -9,25 +9,107 @@
 package generator
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/13rac1/gowasm-bindgen/internal/parser"
 )
 
-// tsErrorCheck is the TypeScript code that checks for Go errors passed through WASM.
-const tsErrorCheck = `    if (result && typeof result === 'object' && '` + ErrorFieldName + `' in result) {
-      throw new Error((result as { ` + ErrorFieldName + `: string }).` + ErrorFieldName + `);
+// tsErrorCheck returns the TypeScript code that checks for Go errors passed
+// through WASM in the error field key.
+func tsErrorCheck(key string) string {
+	return `    if (isWasmError(__result)) {
+      throw new WasmError(__result.` + key + `);
     }
 `
+}
+
+// tsWasmError declares the error class thrown for Go errors and the type guard
+// that recognizes the raw error object, shared by both client modes. Each
+// declaration starts with the %[1]s verb for valueExport, and %[2]s is the
+// error field key.
+const tsWasmError = `/** Thrown when a Go function returns a non-nil error. */
+%[1]sclass WasmError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'WasmError';
+  }
+}
+
+/** Reports whether x is the object a Go function returns in place of its result on error. */
+%[1]sfunction isWasmError(x: unknown): x is { %[2]s: string } {
+  return x !== null && typeof x === 'object' && '%[2]s' in x;
+}
+
+`
+
+// errorKey returns the field that carries Go errors to the client.
+func errorKey(opts Options) string {
+	if opts.ErrorKey != "" {
+		return opts.ErrorKey
+	}
+	return ErrorFieldName
+}
+
+// valueExport is the keyword that starts exported class and function
+// declarations: empty with CommonJS, which exports them through
+// commonJSExports instead.
+func valueExport(opts Options) string {
+	if opts.CommonJS {
+		return ""
+	}
+	return "export "
+}
+
+// ModulePath returns the specifier a generated module uses to import file,
+// another generated module in the same directory: "./go-wasm" for
+// go-wasm.ts, or "./go-wasm.ts" for Deno, which resolves imports literally.
+func ModulePath(file string, opts Options) string {
+	if opts.Deno {
+		return "./" + file
+	}
+	return "./" + strings.TrimSuffix(file, ".ts")
+}
+
+// commonJSExports assigns the client's runtime values to module.exports. The
+// empty export keeps TypeScript treating the file as a module when it declares
+// no exported types, so its names don't leak into the global scope.
+func commonJSExports(className string) string {
+	return "\nexport {};\nmodule.exports = { " + className + ", WasmError, isWasmError };\n"
+}
+
+// tsRuntimeStatsInterface describes the object returned by the diagnostics global.
+const tsRuntimeStatsInterface = `export interface RuntimeStats {
+  numGoroutine: number;
+  alloc: number;
+  totalAlloc: number;
+  sys: number;
+  numGC: number;
+}`
+
+// tsStatsDoc is the JSDoc for the generated stats() method.
+const tsStatsDoc = `  /**
+   * Returns goroutine and memory statistics from the Go runtime.
+   */
+`
 
 // Generate creates TypeScript class-based client for sync mode.
 // This generates a class that wraps globalThis function calls.
-func Generate(parsed *parser.ParsedFile, outputFile, className string) string {
+func Generate(parsed *parser.ParsedFile, outputFile, className string, opts Options) string {
 
 	var b strings.Builder
 	b.WriteString(generateHeader(parsed.Package, outputFile))
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	b.WriteString(checksumHeader(opts))
+	b.WriteString("\n")
+	if opts.Node {
+		b.WriteString(nodeImports(opts))
+		b.WriteString("\n")
+	}
+	b.WriteString(generateBrandedTypes(parsed.Types))
+	b.WriteString(generateNamedInterfaces(parsed.Types))
 
 	// Generate named interfaces for struct return types
 	for _, fn := range parsed.Functions {
@@ -36,74 +118,181 @@ func Generate(parsed *parser.ParsedFile, outputFile, className string) string {
 			b.WriteString("\n\n")
 		}
 	}
+	if opts.Diagnostics {
+		b.WriteString(tsRuntimeStatsInterface)
+		b.WriteString("\n\n")
+	}
+
+	fmt.Fprintf(&b, tsWasmError, valueExport(opts), errorKey(opts))
 
 	// Generate the class
-	b.WriteString(generateClass(parsed.Functions, className))
+	b.WriteString(generateClass(parsed.Functions, className, opts))
+	if opts.CommonJS {
+		b.WriteString(commonJSExports(className))
+	}
 
 	return b.String()
 }
 
+// nodeImports loads the Go runtime and fs.readFile for the Node.js sync
+// client. wasm_exec.js only defines globalThis.Go, so it is imported for its
+// side effect in either module format.
+func nodeImports(opts Options) string {
+	if opts.CommonJS {
+		return "require('./wasm_exec.js');\nconst { readFile } = require('node:fs/promises');\n"
+	}
+	return "import './wasm_exec.js';\nimport { readFile } from 'node:fs/promises';\n"
+}
+
 // generateHeader creates the file header comment.
 func generateHeader(packageName, outputFile string) string {
 	return fmt.Sprintf(`// %s - Generated by gowasm-bindgen -m sync
 // Package: %s`, outputFile, packageName)
 }
 
+// tsInstantiateResponse is the sync client's helper that instantiates a
+// fetched module. instantiateStreaming compiles while the body downloads,
+// but rejects responses whose Content-Type is not exactly application/wasm
+// (and is missing in some runtimes), so those are buffered and instantiated
+// instead.
+const tsInstantiateResponse = `  private static async instantiateResponse(response: Response, importObject: WebAssembly.Imports): Promise<WebAssembly.Instance> {
+    const contentType = response.headers.get('Content-Type') ?? '';
+    if (typeof WebAssembly.instantiateStreaming === 'function' && contentType.trim() === 'application/wasm') {
+      return (await WebAssembly.instantiateStreaming(response, importObject)).instance;
+    }
+    return (await WebAssembly.instantiate(await response.arrayBuffer(), importObject)).instance;
+  }
+`
+
 // generateClass creates the TypeScript class with sync methods.
-func generateClass(functions []parser.GoFunction, className string) string {
+func generateClass(functions []parser.GoFunction, className string, opts Options) string {
 	var b strings.Builder
 
-	b.WriteString("export class ")
+	b.WriteString(valueExport(opts))
+	b.WriteString("class ")
 	b.WriteString(className)
 	b.WriteString(" {\n")
 	b.WriteString("  private constructor() {}\n\n")
 
-	// Static init method - supports both URL (browser) and bytes (Node.js)
-	b.WriteString("  static async init(wasmSource: string | BufferSource): Promise<")
+	// Static init method - accepts a URL or Response (browser), bytes (Node.js),
+	// a compiled Module, or an Instance already created with go.importObject.
+	// Deno and Node.js also take a file path or URL, read from disk.
+	sourceType := "string | BufferSource | Response | WebAssembly.Module | WebAssembly.Instance"
+	if opts.Deno || opts.Node {
+		sourceType = "string | URL | BufferSource | Response | WebAssembly.Module | WebAssembly.Instance"
+	}
+	b.WriteString("  static async init(wasmSource: " + sourceType + ", go: Go = new Go()): Promise<")
 	b.WriteString(className)
 	b.WriteString("> {\n")
-	b.WriteString("    const go = new Go();\n")
-	b.WriteString("    let result: WebAssembly.WebAssemblyInstantiatedSource;\n")
-	b.WriteString("    if (typeof wasmSource === 'string') {\n")
-	b.WriteString("      result = await WebAssembly.instantiateStreaming(fetch(wasmSource), go.importObject);\n")
+	b.WriteString("    let instance: WebAssembly.Instance;\n")
+	b.WriteString("    if (wasmSource instanceof WebAssembly.Instance) {\n")
+	b.WriteString("      // Must have been instantiated with go.importObject\n")
+	b.WriteString("      instance = wasmSource;\n")
+	b.WriteString("    } else if (wasmSource instanceof WebAssembly.Module) {\n")
+	b.WriteString("      instance = await WebAssembly.instantiate(wasmSource, go.importObject);\n")
+	if opts.Deno || opts.Node {
+		readFile := "readFile"
+		if opts.Deno {
+			readFile = "Deno.readFile"
+		}
+		b.WriteString("    } else if (typeof wasmSource === 'string' && /^https?:\\/\\//.test(wasmSource)) {\n")
+		b.WriteString("      instance = await " + className + ".instantiateResponse(await fetch(wasmSource), go.importObject);\n")
+		b.WriteString("    } else if (typeof wasmSource === 'string' || wasmSource instanceof URL) {\n")
+		b.WriteString("      instance = (await WebAssembly.instantiate(await " + readFile + "(wasmSource), go.importObject)).instance;\n")
+	} else {
+		b.WriteString("    } else if (typeof wasmSource === 'string') {\n")
+		b.WriteString("      instance = await " + className + ".instantiateResponse(await fetch(wasmSource), go.importObject);\n")
+	}
+	b.WriteString("    } else if (typeof Response !== 'undefined' && wasmSource instanceof Response) {\n")
+	b.WriteString("      instance = await " + className + ".instantiateResponse(wasmSource, go.importObject);\n")
 	b.WriteString("    } else {\n")
-	b.WriteString("      result = await WebAssembly.instantiate(wasmSource, go.importObject);\n")
+	b.WriteString("      instance = (await WebAssembly.instantiate(wasmSource, go.importObject)).instance;\n")
 	b.WriteString("    }\n")
-	b.WriteString("    void go.run(result.instance);\n")
+	b.WriteString("    void go.run(instance);\n")
 	b.WriteString("    return new ")
 	b.WriteString(className)
 	b.WriteString("();\n")
-	b.WriteString("  }\n")
+	b.WriteString("  }\n\n")
+	b.WriteString(tsInstantiateResponse)
 
 	// Instance methods
 	for _, fn := range functions {
 		b.WriteString("\n")
-		b.WriteString(generateClassMethod(fn))
+		b.WriteString(generateClassMethod(fn, opts))
+	}
+
+	if opts.Diagnostics {
+		b.WriteString("\n")
+		b.WriteString(tsStatsDoc)
+		b.WriteString("  stats(): RuntimeStats {\n")
+		b.WriteString("    return ")
+		b.WriteString(globalRef(opts))
+		b.WriteString(".")
+		b.WriteString(DiagnosticsFuncName)
+		b.WriteString("();\n")
+		b.WriteString("  }\n")
 	}
 
 	b.WriteString("}\n")
 	return b.String()
 }
 
-// generateClassMethod creates a single instance method that calls globalThis.
-func generateClassMethod(fn parser.GoFunction) string {
-	var b strings.Builder
+// generateJSDoc renders the Go doc comment as a JSDoc block, adding an
+// @param tag for each parameter the doc mentions by name. Returns an empty
+// string for undocumented functions.
+func generateJSDoc(fn parser.GoFunction) string {
+	if fn.Doc == "" {
+		return ""
+	}
 
-	// JSDoc if present
-	if fn.Doc != "" {
-		lines := strings.Split(fn.Doc, "\n")
-		b.WriteString("  /**\n")
-		for _, line := range lines {
-			b.WriteString("   * ")
-			b.WriteString(line)
+	var b strings.Builder
+	b.WriteString("  /**\n")
+	for _, line := range strings.Split(fn.Doc, "\n") {
+		b.WriteString("   * ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	// Parameters are mentioned as whole words, split like regexp's \b
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(fn.Doc, func(r rune) bool {
+		return r != '_' && !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z')
+	}) {
+		words[word] = true
+	}
+	params := tsParams(fn.Params)
+	for i, p := range fn.Params {
+		if p.Name == "" || p.Name == "_" || strings.Contains(fn.Doc, "@param "+p.Name) {
+			continue
+		}
+		if words[p.Name] {
+			b.WriteString("   * @param ")
+			b.WriteString(params[i].Name)
 			b.WriteString("\n")
 		}
-		b.WriteString("   */\n")
 	}
+	b.WriteString("   */\n")
+	return b.String()
+}
+
+// globalRef returns the TypeScript expression for the object the Go bindings
+// register functions on.
+func globalRef(opts Options) string {
+	if opts.Namespace != "" {
+		return "(globalThis as any)." + opts.Namespace
+	}
+	return "(globalThis as any)"
+}
+
+// generateClassMethod creates a single instance method that calls globalThis.
+func generateClassMethod(fn parser.GoFunction, opts Options) string {
+	var b strings.Builder
+
+	b.WriteString(generateJSDoc(fn))
+	fn.Params = tsParams(fn.Params)
 
 	params := generateFunctionParams(fn.Params)
 	returnType := determineReturnType(fn)
-	funcName := LowerFirst(fn.Name)
+	funcName := fn.BindingName()
 
 	b.WriteString("  ")
 	b.WriteString(funcName)
@@ -116,37 +305,168 @@ func generateClassMethod(fn parser.GoFunction) string {
 	// Build argument list
 	argNames := make([]string, len(fn.Params))
 	for i, p := range fn.Params {
-		argNames[i] = p.Name
+		argNames[i] = callArg(p)
 	}
 	argsStr := strings.Join(argNames, ", ")
 
 	// Generate function body with error checking
-	b.WriteString("    const result = (globalThis as any).")
+	b.WriteString("    const __result = ")
+	b.WriteString(globalRef(opts))
+	b.WriteString(".")
 	b.WriteString(funcName)
 	b.WriteString("(")
 	b.WriteString(argsStr)
 	b.WriteString(");\n")
-	b.WriteString(tsErrorCheck)
-	b.WriteString("    return result;\n")
+	b.WriteString(tsErrorCheck(errorKey(opts)))
+	if returnType != "void" {
+		b.WriteString("    return __result;\n")
+	}
 	b.WriteString("  }\n")
 
 	return b.String()
 }
 
 // generateFunctionParams formats the parameter list as TypeScript.
+// Trailing pointer and void callback parameters are optional, since the
+// bindings pass nil or a no-op for undefined; other pointer parameters
+// accept undefined explicitly.
 func generateFunctionParams(params []parser.GoParameter) string {
 	if len(params) == 0 {
 		return ""
 	}
 
+	params = tsParams(params)
+	optional := optionalParamsStart(params)
 	parts := make([]string, len(params))
 	for i, p := range params {
-		parts[i] = fmt.Sprintf("%s: %s", p.Name, parser.GoTypeToTS(p.Type))
+		switch {
+		case i >= optional:
+			parts[i] = p.Name + "?: " + parser.GoTypeToTS(p.Type)
+		case p.Type.Kind == parser.KindPointer && !p.IsVariadic:
+			parts[i] = parser.GoParamToTS(p) + " | undefined"
+		default:
+			parts[i] = parser.GoParamToTS(p)
+		}
 	}
 	return strings.Join(parts, ", ")
 }
 
+// tsParams returns params with the names they have in the TypeScript client.
+// Reserved words such as this or delete can't name a parameter there, so they
+// gain underscores until they clash with no other name; __result is taken by
+// the sync client's methods. Safe names are kept, so tsParams is idempotent.
+func tsParams(params []parser.GoParameter) []parser.GoParameter {
+	used := make(map[string]bool)
+	for _, p := range params {
+		used[p.Name] = true
+	}
+	var renamed []parser.GoParameter
+	for i, p := range params {
+		if !parser.IsJSReservedWord(p.Name) && p.Name != "__result" {
+			continue
+		}
+		if renamed == nil {
+			renamed = append([]parser.GoParameter(nil), params...)
+		}
+		name := p.Name
+		for used[name] || parser.IsJSReservedWord(name) || name == "__result" {
+			name = "_" + name
+		}
+		used[name] = true
+		renamed[i].Name = name
+	}
+	if renamed == nil {
+		return params
+	}
+	return renamed
+}
+
+// optionalParamsStart returns the index of the first of the trailing pointer
+// and void callback parameters, which TypeScript callers may leave out, or
+// len(params) if there are none. Optional callbacks suit progress reporting
+// (e.g., onProgress func(percent int)), which callers often don't need.
+func optionalParamsStart(params []parser.GoParameter) int {
+	i := len(params)
+	for i > 0 && !params[i-1].IsVariadic && (params[i-1].Type.Kind == parser.KindPointer || parser.IsVoidCallback(params[i-1].Type)) {
+		i--
+	}
+	return i
+}
+
+// callArg returns the argument expression forwarding a parameter, spreading
+// a variadic parameter back into individual arguments.
+func callArg(p parser.GoParameter) string {
+	if p.IsVariadic {
+		return "..." + p.Name
+	}
+	return p.Name
+}
+
+// generateBrandedTypes creates branded type aliases for named primitive types
+// (e.g., type Score int32) so TypeScript keeps them distinct from plain numbers.
+// String types with literal constants become a union of those literals instead.
+// Returns empty string if there are none.
+func generateBrandedTypes(types map[string]*parser.GoType) string {
+	names := make([]string, 0, len(types))
+	for name, t := range types {
+		if t.Kind == parser.KindPrimitive && t.Underlying != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		if literals := enumLiterals(*types[name]); literals != nil {
+			fmt.Fprintf(&b, "export type %s = %s;\n", name, strings.Join(literals, " | "))
+			continue
+		}
+		underlying := parser.GoTypeToTS(parser.GoType{Name: types[name].Underlying, Kind: parser.KindPrimitive, BigInt: types[name].BigInt})
+		fmt.Fprintf(&b, "export type %s = %s & { readonly __brand: '%s' };\n", name, underlying, name)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// enumLiterals returns the quoted TypeScript literals of a string enum type,
+// or nil if the type has no complete set of literal constants.
+func enumLiterals(t parser.GoType) []string {
+	if len(t.EnumLiterals) == 0 || len(t.EnumLiterals) != len(t.EnumValues) {
+		return nil
+	}
+	literals := make([]string, len(t.EnumLiterals))
+	for i, value := range t.EnumLiterals {
+		quoted, _ := json.Marshal(value) //nolint:errcheck // marshaling a string cannot fail
+		literals[i] = string(quoted)
+	}
+	return literals
+}
+
+// generateNamedInterfaces creates an exported interface for each named struct
+// type, which function signatures and other interfaces reference by name.
+// Returns empty string if there are none.
+func generateNamedInterfaces(types map[string]*parser.GoType) string {
+	names := make([]string, 0, len(types))
+	for name, t := range types {
+		if t.Kind == parser.KindStruct && t.Named {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(generateStructInterface(name, *types[name]))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
 // generateInterfaceForFunction creates an exported interface if the function returns a struct.
+// Named struct returns get a <Func>Result alias instead, kept for compatibility.
 // Returns empty string if the function doesn't return a struct type.
 func generateInterfaceForFunction(fn parser.GoFunction) string {
 	if len(fn.Returns) == 0 {
@@ -156,8 +476,17 @@ func generateInterfaceForFunction(fn parser.GoFunction) string {
 	// Get the non-error return type
 	hasError := fn.Returns[len(fn.Returns)-1].IsError
 	if !hasError || len(fn.Returns) > 1 {
-		returnType := fn.Returns[0]
+		returnType, nullable := resultType(fn.Returns[0])
 		if returnType.Kind == parser.KindStruct {
+			if returnType.Named {
+				if alias := interfaceName(fn.Name); alias != returnType.Name {
+					if nullable {
+						return "export type " + alias + " = " + returnType.Name + " | null;"
+					}
+					return "export type " + alias + " = " + returnType.Name + ";"
+				}
+				return ""
+			}
 			return generateStructInterface(interfaceName(fn.Name), returnType)
 		}
 	}
@@ -173,16 +502,29 @@ func generateStructInterface(name string, structType parser.GoType) string {
 	b.WriteString(" {\n")
 
 	for _, field := range structType.Fields {
-		fieldName := field.JSONTag
+		if field.Skip {
+			continue
+		}
+		fieldName := field.TSName
 		if fieldName == "" {
+			fieldName = field.JSONTag
+		}
+		if fieldName == "" && structType.JSON {
+			// encoding/json keys untagged fields by their Go name
+			fieldName = field.Name
+		} else if fieldName == "" {
 			// Use lowercase first letter
 			fieldName = strings.ToLower(field.Name[:1]) + field.Name[1:]
 		}
 
+		b.WriteString(fieldJSDoc(field.Doc))
 		b.WriteString("  ")
 		b.WriteString(fieldName)
+		if parser.IsOptionalField(field) {
+			b.WriteString("?")
+		}
 		b.WriteString(": ")
-		b.WriteString(parser.GoTypeToTS(field.Type))
+		b.WriteString(parser.GoFieldToTS(field))
 		b.WriteString(";\n")
 	}
 
@@ -190,6 +532,27 @@ func generateStructInterface(name string, structType parser.GoType) string {
 	return b.String()
 }
 
+// fieldJSDoc renders a struct field's doc comment as a JSDoc block for its
+// interface property, on one line when the doc is a single line.
+func fieldJSDoc(doc string) string {
+	if doc == "" {
+		return ""
+	}
+	lines := strings.Split(doc, "\n")
+	if len(lines) == 1 {
+		return "  /** " + doc + " */\n"
+	}
+	var b strings.Builder
+	b.WriteString("  /**\n")
+	for _, line := range lines {
+		b.WriteString("   * ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("   */\n")
+	return b.String()
+}
+
 // interfaceName converts a function name to a result interface name.
 // e.g., "formatUser" -> "FormatUserResult", "getInfo" -> "GetInfoResult"
 func interfaceName(funcName string) string {
@@ -200,9 +563,26 @@ func interfaceName(funcName string) string {
 	return strings.ToUpper(funcName[:1]) + funcName[1:] + "Result"
 }
 
+// tsResultError is the failure case of a result union (see
+// parser.UseResultUnion).
+const tsResultError = "{ ok: false; error: string }"
+
 // determineReturnType returns the TypeScript return type for a Go function.
 // For functions returning (T, error), returns T. For functions returning only error, returns "void".
+// Functions marked ResultUnion return { ok: true; value: T } | { ok: false; error: string }.
 func determineReturnType(fn parser.GoFunction) string {
+	if fn.ResultUnion {
+		if valueType := determineValueType(fn); valueType != "void" {
+			return "{ ok: true; value: " + valueType + " } | " + tsResultError
+		}
+		return "{ ok: true } | " + tsResultError
+	}
+	return determineValueType(fn)
+}
+
+// determineValueType returns the TypeScript type of a Go function's
+// non-error result, or "void" if it has none.
+func determineValueType(fn parser.GoFunction) string {
 	if len(fn.Returns) == 0 {
 		return "void"
 	}
@@ -210,8 +590,30 @@ func determineReturnType(fn parser.GoFunction) string {
 	if lastIsError && len(fn.Returns) == 1 {
 		return "void"
 	}
-	if fn.Returns[0].Kind == parser.KindStruct {
-		return interfaceName(fn.Name)
+	ret, nullable := resultType(fn.Returns[0])
+	var tsType string
+	switch {
+	case ret.Kind == parser.KindStruct && !ret.Named && fn.ReadonlyReturn:
+		tsType = "Readonly<" + interfaceName(fn.Name) + ">"
+	case ret.Kind == parser.KindStruct && !ret.Named:
+		tsType = interfaceName(fn.Name)
+	case fn.ReadonlyReturn:
+		tsType = parser.GoTypeToReadonlyTS(ret)
+	default:
+		tsType = parser.GoTypeToTS(ret)
+	}
+	if nullable {
+		return tsType + " | null"
+	}
+	return tsType
+}
+
+// resultType returns the type a function result is declared with in
+// TypeScript, dereferencing a pointer, and whether it may be null: the
+// bindings return a nil pointer as null.
+func resultType(t parser.GoType) (parser.GoType, bool) {
+	if t.Kind == parser.KindPointer && t.Elem != nil {
+		return *t.Elem, true
 	}
-	return parser.GoTypeToTS(fn.Returns[0])
+	return t, false
 }
@@ -45,11 +45,12 @@ func TestGenerate(t *testing.T) {
 			},
 			want: []string{
 				"export class Wasm",
-				"static async init(wasmSource: string | BufferSource): Promise<Wasm>",
+				"static async init(wasmSource: string | BufferSource | Response | WebAssembly.Module | WebAssembly.Instance, go: Go = new Go()): Promise<Wasm>",
 				"hashData(data: string): string",
-				"const result = (globalThis as any).hashData(data);",
-				"throw new Error((result as { __error: string }).__error);",
-				"return result;",
+				"const __result = (globalThis as any).hashData(data);",
+				"if (isWasmError(__result)) {",
+				"throw new WasmError(__result.__error);",
+				"return __result;",
 			},
 		},
 		{
@@ -82,7 +83,7 @@ func TestGenerate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Capitalize package name for class name (simulating explicit --class-name)
 			className := strings.ToUpper(tt.parsed.Package[:1]) + tt.parsed.Package[1:]
-			got := Generate(tt.parsed, "client.ts", className)
+			got := Generate(tt.parsed, "client.ts", className, Options{})
 			for _, want := range tt.want {
 				if !strings.Contains(got, want) {
 					t.Errorf("Generate() missing %q in output:\n%s", want, got)
@@ -92,6 +93,424 @@ func TestGenerate(t *testing.T) {
 	}
 }
 
+func TestGenerate_Diagnostics(t *testing.T) {
+	parsed := &parser.ParsedFile{Package: "main", Functions: []parser.GoFunction{}}
+
+	got := Generate(parsed, "client.ts", "Wasm", Options{Diagnostics: true})
+	for _, want := range []string{
+		"export interface RuntimeStats {",
+		"numGoroutine: number;",
+		"stats(): RuntimeStats {",
+		"return (globalThis as any).__gowasmStats();",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() missing %q in output:\n%s", want, got)
+		}
+	}
+
+	if got := Generate(parsed, "client.ts", "Wasm", Options{}); strings.Contains(got, "stats()") {
+		t.Error("Generate() should not emit stats() without Diagnostics")
+	}
+}
+
+func TestGenerate_ResultUnion(t *testing.T) {
+	errType := parser.GoType{Name: "error", Kind: parser.KindError, IsError: true}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Find", Returns: []parser.GoType{{Name: "int", Kind: parser.KindPrimitive}, errType}},
+			{Name: "Check", Returns: []parser.GoType{errType}},
+		},
+	}
+	parser.UseResultUnion(parsed)
+
+	got := Generate(parsed, "client.ts", "Wasm", Options{})
+	for _, want := range []string{
+		"find(): { ok: true; value: number } | { ok: false; error: string } {",
+		"check(): { ok: true } | { ok: false; error: string } {",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() missing %q in output:\n%s", want, got)
+		}
+	}
+
+	mock := GenerateMock(parsed, "client-mock.ts", "Wasm", "./client", Options{})
+	for _, want := range []string{"find: () => { ok: true, value: 0 },", "check: () => { ok: true },"} {
+		if !strings.Contains(mock, want) {
+			t.Errorf("GenerateMock() missing %q in output:\n%s", want, mock)
+		}
+	}
+}
+
+func TestGenerate_ReadonlyReturns(t *testing.T) {
+	user := parser.GoType{Name: "User", Kind: parser.KindStruct, Named: true}
+	users := parser.GoType{Name: "[]User", Kind: parser.KindSlice, Elem: &user}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "List", Params: []parser.GoParameter{{Name: "users", Type: users}}, Returns: []parser.GoType{users}},
+			{Name: "Stats", Returns: []parser.GoType{{Kind: parser.KindStruct, Fields: []parser.GoField{{Name: "Count", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}}}}}},
+		},
+	}
+	parser.UseReadonlyReturns(parsed)
+
+	for name, got := range map[string]string{
+		"sync":   Generate(parsed, "client.ts", "Wasm", Options{}),
+		"worker": GenerateClient(parsed, "client.ts", "Wasm", Options{}),
+		"dts":    GenerateDeclarations(parsed, "client.d.ts", Options{}),
+	} {
+		// Parameters keep their mutable types
+		for _, want := range []string{"list(users: User[]", "ReadonlyArray<Readonly<User>>", "Readonly<StatsResult>"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: missing %q in output:\n%s", name, want, got)
+			}
+		}
+	}
+}
+
+func TestGenerate_Namespace(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{{
+			Name:    "Greet",
+			Params:  []parser.GoParameter{{Name: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+			Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+		}},
+	}
+
+	got := Generate(parsed, "client.ts", "Wasm", Options{Namespace: "mylib", Diagnostics: true})
+	for _, want := range []string{
+		"const __result = (globalThis as any).mylib.greet(name);",
+		"return (globalThis as any).mylib.__gowasmStats();",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() missing %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerate_CommonJS(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{{
+			Name:    "Greet",
+			Params:  []parser.GoParameter{{Name: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+			Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+		}},
+	}
+
+	got := Generate(parsed, "client.ts", "Wasm", Options{CommonJS: true})
+	if strings.Contains(got, "export class") || strings.Contains(got, "export function") {
+		t.Errorf("CommonJS client should not export values with ESM syntax:\n%s", got)
+	}
+	if !strings.Contains(got, "\nclass Wasm {") {
+		t.Errorf("Generate() missing the Wasm class:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "}\n\nexport {};\nmodule.exports = { Wasm, WasmError, isWasmError };\n") {
+		t.Errorf("Generate() should end with module.exports:\n%s", got)
+	}
+}
+
+func TestGenerate_Deno(t *testing.T) {
+	parsed := &parser.ParsedFile{Package: "main", Functions: []parser.GoFunction{}}
+
+	got := Generate(parsed, "client.ts", "Wasm", Options{Deno: true})
+	for _, want := range []string{
+		"static async init(wasmSource: string | URL | BufferSource | Response | WebAssembly.Module | WebAssembly.Instance, go: Go = new Go()): Promise<Wasm> {",
+		"Deno.readFile(wasmSource)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Deno client missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerate_Node(t *testing.T) {
+	parsed := &parser.ParsedFile{Package: "main", Functions: []parser.GoFunction{}}
+
+	got := Generate(parsed, "client.ts", "Wasm", Options{Node: true})
+	for _, want := range []string{
+		"\nimport './wasm_exec.js';\nimport { readFile } from 'node:fs/promises';\n",
+		"static async init(wasmSource: string | URL | BufferSource | Response | WebAssembly.Module | WebAssembly.Instance, go: Go = new Go()): Promise<Wasm> {",
+		"await WebAssembly.instantiate(await readFile(wasmSource), go.importObject)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Node client missing %q:\n%s", want, got)
+		}
+	}
+
+	got = Generate(parsed, "client.ts", "Wasm", Options{Node: true, CommonJS: true})
+	if !strings.Contains(got, "\nrequire('./wasm_exec.js');\nconst { readFile } = require('node:fs/promises');\n") {
+		t.Errorf("CommonJS Node client should require its dependencies:\n%s", got)
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	if got := ModulePath("go-wasm.ts", Options{}); got != "./go-wasm" {
+		t.Errorf("ModulePath() = %q, want %q", got, "./go-wasm")
+	}
+	// Deno resolves imports literally, so the extension stays
+	if got := ModulePath("go-wasm.ts", Options{Deno: true}); got != "./go-wasm.ts" {
+		t.Errorf("ModulePath(Deno) = %q, want %q", got, "./go-wasm.ts")
+	}
+}
+
+func TestGenerate_StringEnumUnion(t *testing.T) {
+	status := parser.GoType{
+		Name:         "Status",
+		Kind:         parser.KindPrimitive,
+		Underlying:   "string",
+		EnumValues:   []string{"Active", "Quoted"},
+		EnumLiterals: []string{"active", `it's "q"`},
+	}
+	partial := parser.GoType{
+		Name:         "Mode",
+		Kind:         parser.KindPrimitive,
+		Underlying:   "string",
+		EnumValues:   []string{"Fast", "Dynamic"},
+		EnumLiterals: nil,
+	}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "SetStatus", Params: []parser.GoParameter{{Name: "s", Type: status}, {Name: "m", Type: partial}}},
+		},
+		Types: map[string]*parser.GoType{"Status": &status, "Mode": &partial},
+	}
+
+	got := Generate(parsed, "client.ts", "Wasm", Options{})
+	for _, want := range []string{
+		`export type Status = "active" | "it's \"q\"";`,
+		"export type Mode = string & { readonly __brand: 'Mode' };",
+		"setStatus(s: Status, m: Mode): void",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerate_NamedPrimitiveSlice(t *testing.T) {
+	score := parser.GoType{Name: "Score", Kind: parser.KindPrimitive, Underlying: "int32"}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Scores", Returns: []parser.GoType{{Name: "[]Score", Kind: parser.KindSlice, Elem: &score}}},
+		},
+		Types: map[string]*parser.GoType{"Score": &score},
+	}
+
+	for name, got := range map[string]string{
+		"sync":   Generate(parsed, "client.ts", "Wasm", Options{}),
+		"worker": GenerateClient(parsed, "client.ts", "Wasm", Options{}),
+	} {
+		for _, want := range []string{
+			"export type Score = number & { readonly __brand: 'Score' };",
+			"scores(",
+			"Score[]",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s client missing %q in output:\n%s", name, want, got)
+			}
+		}
+	}
+}
+
+func TestGenerate_NamedStructInterfaces(t *testing.T) {
+	address := parser.GoType{Name: "Address", Kind: parser.KindStruct, Named: true, Fields: []parser.GoField{
+		{Name: "City", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+		{Name: "Geo", JSONTag: "-", Skip: true, Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+	}}
+	user := parser.GoType{Name: "User", Kind: parser.KindStruct, Named: true, Fields: []parser.GoField{
+		{Name: "Name", JSONTag: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+		{Name: "Home", Type: address},
+		{Name: "Email", JSONTag: "email", OmitEmpty: true, Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+		{Name: "Manager", JSONTag: "manager", Type: parser.GoType{Name: "*User", Kind: parser.KindPointer, Elem: &parser.GoType{
+			Name: "User", Kind: parser.KindStruct, Named: true, Recursive: true,
+		}}},
+	}}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "GetUser", Returns: []parser.GoType{user}},
+			{Name: "FindUser", Returns: []parser.GoType{{Name: "*User", Kind: parser.KindPointer, Elem: &user}}},
+			{Name: "Save", Params: []parser.GoParameter{{Name: "u", Type: user}}},
+			{Name: "Point", Returns: []parser.GoType{{Name: "struct", Kind: parser.KindStruct, Fields: []parser.GoField{
+				{Name: "X", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+			}}}},
+		},
+		Types: map[string]*parser.GoType{"Address": &address, "User": &user},
+	}
+
+	for name, got := range map[string]string{
+		"sync":   Generate(parsed, "client.ts", "Wasm", Options{}),
+		"worker": GenerateClient(parsed, "client.ts", "Wasm", Options{}),
+	} {
+		for _, want := range []string{
+			"export interface Address {\n  city: string;\n}",
+			"export interface User {\n  name: string;\n  home: Address;\n  email?: string;\n  manager: User | null;\n}",
+			"export type GetUserResult = User;",
+			"getUser(",
+			"findUser(",
+			"save(u: User",
+			// Anonymous structs keep the per-function interface
+			"export interface PointResult {\n  x: number;\n}",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s client missing %q in output:\n%s", name, want, got)
+			}
+		}
+		if strings.Count(got, "export interface User ") != 1 {
+			t.Errorf("%s client should declare User once:\n%s", name, got)
+		}
+		// A nil pointer result arrives as null
+		if !strings.Contains(got, "export type FindUserResult = User | null;") {
+			t.Errorf("%s client should alias pointer results as nullable:\n%s", name, got)
+		}
+	}
+}
+
+func TestGenerate_PointerResults(t *testing.T) {
+	user := parser.GoType{Name: "User", Kind: parser.KindStruct, Named: true, Fields: []parser.GoField{
+		{Name: "Name", JSONTag: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+	}}
+	intType := parser.GoType{Name: "int", Kind: parser.KindPrimitive}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Find", Params: []parser.GoParameter{{Name: "id", Type: intType}}, Returns: []parser.GoType{{Name: "*User", Kind: parser.KindPointer, Elem: &user}}},
+			{Name: "Count", Returns: []parser.GoType{{Name: "*int", Kind: parser.KindPointer, Elem: &intType}, {Name: "error", Kind: parser.KindError, IsError: true}}},
+			{Name: "Origin", Returns: []parser.GoType{{Name: "*struct", Kind: parser.KindPointer, Elem: &parser.GoType{Name: "struct", Kind: parser.KindStruct, Fields: []parser.GoField{
+				{Name: "X", Type: intType},
+			}}}}},
+		},
+		Types: map[string]*parser.GoType{"User": &user},
+	}
+
+	tests := []struct {
+		name   string
+		client string
+		want   []string
+	}{
+		{
+			name:   "sync",
+			client: Generate(parsed, "client.ts", "Wasm", Options{}),
+			want: []string{
+				"export type FindResult = User | null;",
+				"export interface OriginResult {\n  x: number;\n}",
+				"  find(id: number): User | null {",
+				"  count(): number | null {",
+				"  origin(): OriginResult | null {",
+			},
+		},
+		{
+			name:   "worker",
+			client: GenerateClient(parsed, "client.ts", "Wasm", Options{}),
+			want: []string{
+				"find(id: number, options?: { signal?: AbortSignal }): Promise<User | null> {",
+				"count(options?: { signal?: AbortSignal }): Promise<number | null> {",
+			},
+		},
+	}
+	parser.UseReadonlyReturns(parsed)
+	tests = append(tests, struct {
+		name   string
+		client string
+		want   []string
+	}{"readonly", Generate(parsed, "client.ts", "Wasm", Options{}), []string{"  find(id: number): Readonly<User> | null {"}})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(tt.client, want) {
+					t.Errorf("client missing %q:\n%s", want, tt.client)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_ReservedParamNames(t *testing.T) {
+	parsed := mustParse(t, `package main
+// Collide returns result unless this is set.
+func Collide(args float64, result string, this bool, delete int, _this int, __result int) (string, error) { return result, nil }
+func Each(this func(int)) {}`)
+
+	tests := []struct {
+		name   string
+		client string
+		want   []string
+	}{
+		{
+			name:   "sync",
+			client: Generate(parsed, "client.ts", "Wasm", Options{}),
+			want: []string{
+				"   * @param result\n   * @param __this\n",
+				"  collide(args: number, result: string, __this: boolean, _delete: number, _this: number, ___result: number): string {",
+				"    const __result = (globalThis as any).collide(args, result, __this, _delete, _this, ___result);",
+				"    if (isWasmError(__result)) {",
+				"    return __result;",
+				"  each(_this?: (arg0: number) => void): void {",
+			},
+		},
+		{
+			name:   "worker",
+			client: GenerateClient(parsed, "client.ts", "Wasm", Options{}),
+			want: []string{
+				"  collide(args: number, result: string, __this: boolean, _delete: number, _this: number, ___result: number, options?: { signal?: AbortSignal }): Promise<string> {",
+				`return this.call<string>("collide", [args, result, __this, _delete, _this, ___result], options?.signal);`,
+				"    const _thisId = _this ? this.registerCallback(_this as (...args: unknown[]) => void) : 0;",
+			},
+		},
+		{
+			name:   "declarations",
+			client: GenerateDeclarations(parsed, "client.d.ts", Options{}),
+			want:   []string{"function each(_this?: (arg0: number) => void): void;"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(tt.client, want) {
+					t.Errorf("client missing %q:\n%s", want, tt.client)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_NamedStructParams(t *testing.T) {
+	parsed := mustParse(t, `package main
+type Address struct {
+	Street string `+"`json:\"street\"`"+`
+}
+type FormData struct {
+	Name string  `+"`json:\"name\"`"+`
+	Home Address `+"`json:\"home\"`"+`
+}
+func Submit(form FormData) bool { return true }
+func SubmitAll(forms []FormData, byID map[string]FormData, opt *FormData) int { return 0 }`)
+
+	for name, got := range map[string]string{
+		"sync":   Generate(parsed, "client.ts", "Wasm", Options{}),
+		"worker": GenerateClient(parsed, "client.ts", "Wasm", Options{}),
+	} {
+		for _, want := range []string{
+			"export interface FormData {\n  name: string;\n  home: Address;\n}",
+			"submit(form: FormData",
+			"submitAll(forms: FormData[], byID: {[key: string]: FormData}, opt?: FormData",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s client missing %q in output:\n%s", name, want, got)
+			}
+		}
+		if strings.Contains(got, "form: {") {
+			t.Errorf("%s client should not inline the FormData shape:\n%s", name, got)
+		}
+	}
+}
+
 func TestGenerateHeader(t *testing.T) {
 	got := generateHeader("wasm", "my-api.ts")
 	want := []string{
@@ -120,7 +539,15 @@ func TestGenerateClass(t *testing.T) {
 			want: []string{
 				"export class Wasm {",
 				"private constructor() {}",
-				"static async init(wasmSource: string | BufferSource): Promise<Wasm>",
+				"static async init(wasmSource: string | BufferSource | Response | WebAssembly.Module | WebAssembly.Instance, go: Go = new Go()): Promise<Wasm>",
+				"if (wasmSource instanceof WebAssembly.Instance) {\n      // Must have been instantiated with go.importObject\n      instance = wasmSource;",
+				"} else if (wasmSource instanceof WebAssembly.Module) {\n      instance = await WebAssembly.instantiate(wasmSource, go.importObject);",
+				"typeof wasmSource === 'string') {\n      instance = await Wasm.instantiateResponse(await fetch(wasmSource), go.importObject);",
+				"wasmSource instanceof Response) {\n      instance = await Wasm.instantiateResponse(wasmSource, go.importObject);",
+				"private static async instantiateResponse(response: Response, importObject: WebAssembly.Imports): Promise<WebAssembly.Instance> {",
+				"contentType.trim() === 'application/wasm') {\n      return (await WebAssembly.instantiateStreaming(response, importObject)).instance;",
+				"return (await WebAssembly.instantiate(await response.arrayBuffer(), importObject)).instance;",
+				"void go.run(instance);",
 			},
 		},
 		{
@@ -135,7 +562,7 @@ func TestGenerateClass(t *testing.T) {
 			className: "Calculator",
 			want: []string{
 				"export class Calculator {",
-				"static async init(wasmSource: string | BufferSource): Promise<Calculator>",
+				"static async init(wasmSource: string | BufferSource | Response | WebAssembly.Module | WebAssembly.Instance, go: Go = new Go()): Promise<Calculator>",
 				"greet(name: string): string",
 			},
 		},
@@ -143,7 +570,7 @@ func TestGenerateClass(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := generateClass(tt.functions, tt.className)
+			got := generateClass(tt.functions, tt.className, Options{})
 			for _, w := range tt.want {
 				if !strings.Contains(got, w) {
 					t.Errorf("generateClass() missing %q in output:\n%s", w, got)
@@ -170,9 +597,9 @@ func TestGenerateClassMethod(t *testing.T) {
 			},
 			want: []string{
 				"hashData(data: string): string {",
-				"const result = (globalThis as any).hashData(data);",
-				"'__error' in result",
-				"return result;",
+				"const __result = (globalThis as any).hashData(data);",
+				"if (isWasmError(__result))",
+				"return __result;",
 			},
 		},
 		{
@@ -184,8 +611,22 @@ func TestGenerateClassMethod(t *testing.T) {
 			},
 			want: []string{
 				"getCurrentTime(): number {",
-				"const result = (globalThis as any).getCurrentTime();",
-				"return result;",
+				"const __result = (globalThis as any).getCurrentTime();",
+				"return __result;",
+			},
+		},
+		{
+			name: "error only",
+			fn: parser.GoFunction{
+				Name:    "Validate",
+				Params:  []parser.GoParameter{{Name: "x", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}}},
+				Returns: []parser.GoType{{Name: "error", Kind: parser.KindError, IsError: true}},
+			},
+			want: []string{
+				"validate(x: number): void {",
+				"const __result = (globalThis as any).validate(x);",
+				// The Go side's true success value is not returned from a void method
+				"if (isWasmError(__result)) {\n      throw new WasmError(__result.__error);\n    }\n  }\n",
 			},
 		},
 		{
@@ -203,6 +644,49 @@ func TestGenerateClassMethod(t *testing.T) {
 				"process(data: string): string {",
 			},
 		},
+		{
+			name: "documentation mentioning parameters",
+			fn: parser.GoFunction{
+				Name: "Scale",
+				Params: []parser.GoParameter{
+					{Name: "value", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+					{Name: "factor", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+				},
+				Returns: []parser.GoType{{Name: "int", Kind: parser.KindPrimitive}},
+				Doc:     "Scale multiplies value by\nthe given factor.",
+			},
+			want: []string{
+				"   * Scale multiplies value by\n   * the given factor.\n   * @param value\n   * @param factor\n   */",
+			},
+		},
+		{
+			name: "documentation mentioning parameters between punctuation",
+			fn: parser.GoFunction{
+				Name: "Pad",
+				Params: []parser.GoParameter{
+					{Name: "s", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+					{Name: "max_len", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+					{Name: "pad", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+				},
+				Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+				Doc:     "Pad extends s (up to max_len).",
+			},
+			want: []string{
+				"   * Pad extends s (up to max_len).\n   * @param s\n   * @param max_len\n   */",
+			},
+		},
+		{
+			name: "documentation not mentioning parameters",
+			fn: parser.GoFunction{
+				Name:    "Process",
+				Params:  []parser.GoParameter{{Name: "in", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+				Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+				Doc:     "Process handles input",
+			},
+			want: []string{
+				"   * Process handles input\n   */",
+			},
+		},
 		{
 			name: "function with error return",
 			fn: parser.GoFunction{
@@ -218,10 +702,10 @@ func TestGenerateClassMethod(t *testing.T) {
 			},
 			want: []string{
 				"divide(a: number, b: number): number {",
-				"const result = (globalThis as any).divide(a, b);",
-				"'__error' in result",
-				"throw new Error",
-				"return result;",
+				"const __result = (globalThis as any).divide(a, b);",
+				"if (isWasmError(__result))",
+				"throw new WasmError",
+				"return __result;",
 			},
 		},
 		{
@@ -235,14 +719,14 @@ func TestGenerateClassMethod(t *testing.T) {
 			},
 			want: []string{
 				"close(): void {",
-				"const result = (globalThis as any).close();",
+				"const __result = (globalThis as any).close();",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := generateClassMethod(tt.fn)
+			got := generateClassMethod(tt.fn, Options{})
 			for _, w := range tt.want {
 				if !strings.Contains(got, w) {
 					t.Errorf("generateClassMethod() missing %q in output:\n%s", w, got)
@@ -252,6 +736,43 @@ func TestGenerateClassMethod(t *testing.T) {
 	}
 }
 
+func TestGenerateStructInterface_FieldDocs(t *testing.T) {
+	got := generateStructInterface("User", parser.GoType{Name: "User", Kind: parser.KindStruct, Named: true, Fields: []parser.GoField{
+		{Name: "Name", JSONTag: "name", Doc: "the user's display name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+		{Name: "Age", JSONTag: "age", Doc: "Age in years.\nZero when unknown.", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+		{Name: "ID", JSONTag: "id", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+	}})
+	want := `export interface User {
+  /** the user's display name */
+  name: string;
+  /**
+   * Age in years.
+   * Zero when unknown.
+   */
+  age: number;
+  id: number;
+}`
+	if got != want {
+		t.Errorf("generateStructInterface() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateStructInterface_TSNames(t *testing.T) {
+	got := generateStructInterface("Event", parser.GoType{Name: "Event", Kind: parser.KindStruct, Named: true, Fields: []parser.GoField{
+		{Name: "CreatedAt", JSONTag: "created_at", TSName: "createdAt", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+		{Name: "Kind", TSName: "type", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+		{Name: "ID", JSONTag: "id", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+	}})
+	want := `export interface Event {
+  createdAt: string;
+  type: string;
+  id: number;
+}`
+	if got != want {
+		t.Errorf("generateStructInterface() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestInterfaceName(t *testing.T) {
 	tests := []struct {
 		funcName string
@@ -301,6 +822,37 @@ func TestGenerateFunctionParams(t *testing.T) {
 			},
 			want: "input: string, count: number, enabled: boolean",
 		},
+		{
+			name: "trailing pointer parameters",
+			params: []parser.GoParameter{
+				{Name: "query", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+				{Name: "limit", Type: parser.GoType{Name: "*int", Kind: parser.KindPointer, Elem: &parser.GoType{Name: "int", Kind: parser.KindPrimitive}}},
+			},
+			want: "query: string, limit?: number",
+		},
+		{
+			name: "leading pointer parameter",
+			params: []parser.GoParameter{
+				{Name: "limit", Type: parser.GoType{Name: "*int", Kind: parser.KindPointer, Elem: &parser.GoType{Name: "int", Kind: parser.KindPrimitive}}},
+				{Name: "query", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+			},
+			want: "limit: number | undefined, query: string",
+		},
+		{
+			name: "trailing void callback",
+			params: []parser.GoParameter{
+				{Name: "opts", Type: parser.GoType{Name: "*Options", Kind: parser.KindPointer, Elem: &parser.GoType{Name: "Options", Kind: parser.KindStruct, Named: true}}},
+				{Name: "onProgress", Type: parser.GoType{Kind: parser.KindFunction, CallbackParams: []parser.GoType{{Name: "int", Kind: parser.KindPrimitive}}}},
+			},
+			want: "opts?: Options, onProgress?: (arg0: number) => void",
+		},
+		{
+			name: "trailing callback with a result",
+			params: []parser.GoParameter{
+				{Name: "keep", Type: parser.GoType{Kind: parser.KindFunction, CallbackParams: []parser.GoType{{Name: "int", Kind: parser.KindPrimitive}}, CallbackResults: []parser.GoType{{Name: "bool", Kind: parser.KindPrimitive}}}},
+			},
+			want: "keep: (arg0: number) => boolean",
+		},
 	}
 
 	for _, tt := range tests {
@@ -312,3 +864,60 @@ func TestGenerateFunctionParams(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerate_Deterministic(t *testing.T) {
+	// Several named types, so any output built from the Types map would
+	// eventually come out in a different order
+	src := `package main
+
+type Zeta struct {
+	B string ` + "`json:\"b\"`" + `
+	A int    ` + "`json:\"a\"`" + `
+}
+
+type Alpha struct {
+	Z Zeta
+	M map[string]int
+}
+
+type Score int32
+
+type Level int64
+
+type Status string
+
+const (
+	Active   Status = "active"
+	Inactive Status = "inactive"
+)
+
+func Make(a Alpha, s Score) Zeta { return Zeta{} }
+
+func Rank(l Level, st Status) (map[string]Alpha, error) { return nil, nil }
+
+func Each(items []Zeta, cb func(Alpha)) {}
+`
+
+	generate := func() []string {
+		parsed := mustParse(t, src)
+		opts := Options{Diagnostics: true}
+		workerOpts := Options{Diagnostics: true, WorkerMode: true}
+		return []string{
+			GenerateGoBindings(parsed, opts),
+			GenerateGoBindings(parsed, workerOpts),
+			Generate(parsed, "client.ts", "Wasm", opts),
+			GenerateClient(parsed, "client.ts", "Wasm", workerOpts),
+			GenerateMock(parsed, "client-mock.ts", "Wasm", "./client", workerOpts),
+			GenerateDeclarations(parsed, "client.d.ts", opts),
+		}
+	}
+
+	first := generate()
+	for run := 0; run < 20; run++ {
+		for i, got := range generate() {
+			if got != first[i] {
+				t.Fatalf("output %d differs between runs:\nfirst:\n%s\nlater:\n%s", i, first[i], got)
+			}
+		}
+	}
+}
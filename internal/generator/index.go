@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+// IndexFileName is the barrel module that re-exports the clients generated
+// into an output directory (see GenerateIndex).
+const IndexFileName = "index.ts"
+
+// indexHeader starts every generated index.ts.
+const indexHeader = `// index.ts - Generated by gowasm-bindgen
+// Re-exports each client generated into this directory. Every run updates
+// the line of its own client and keeps the others.
+`
+
+// indexExportPattern matches the re-export line of one client module.
+var indexExportPattern = regexp.MustCompile(`^export \{ (.*) \} from '\./([^']+)';$`)
+
+// GenerateIndex merges the exports of the client module (the client's file
+// name without .ts) into existing, the current contents of index.ts or ""
+// if there is none. The module's line is replaced if present, so running it
+// again with the same client yields the same file. Lines of other modules,
+// and any lines added by hand, are kept.
+//
+// A name another module already re-exports is left out, since TypeScript
+// rejects duplicate exports; those names are returned as skipped.
+func GenerateIndex(existing, module string, parsed *parser.ParsedFile, className string, opts Options) (content string, skipped []string) {
+	lines := make(map[string]string) // module -> export line
+	taken := make(map[string]bool)   // names exported by other modules
+	var other []string
+	for _, line := range strings.Split(existing, "\n") {
+		if line == "" || (strings.HasPrefix(line, "//") && strings.Contains(indexHeader, line+"\n")) {
+			continue
+		}
+		m := indexExportPattern.FindStringSubmatch(line)
+		if m == nil {
+			other = append(other, line)
+			continue
+		}
+		if m[2] == module {
+			continue
+		}
+		lines[m[2]] = line
+		for _, name := range strings.Split(m[1], ", ") {
+			taken[strings.TrimPrefix(name, "type ")] = true
+		}
+	}
+
+	var names []string
+	if taken[className] {
+		skipped = append(skipped, className)
+	} else {
+		names = append(names, className)
+	}
+	for _, name := range clientTypeExports(parsed, opts) {
+		if taken[name] {
+			skipped = append(skipped, name)
+			continue
+		}
+		names = append(names, "type "+name)
+	}
+	if len(names) > 0 {
+		lines[module] = "export { " + strings.Join(names, ", ") + " } from './" + module + "';"
+	}
+
+	modules := make([]string, 0, len(lines))
+	for m := range lines {
+		modules = append(modules, m)
+	}
+	sort.Strings(modules)
+
+	var b strings.Builder
+	b.WriteString(indexHeader)
+	b.WriteString("\n")
+	for _, m := range modules {
+		b.WriteString(lines[m])
+		b.WriteString("\n")
+	}
+	for _, line := range other {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String(), skipped
+}
+
+// clientTypeExports returns the sorted names of the types and interfaces a
+// client generated by Generate or GenerateClient exports.
+func clientTypeExports(parsed *parser.ParsedFile, opts Options) []string {
+	var names []string
+	for name, t := range parsed.Types {
+		if (t.Kind == parser.KindPrimitive && t.Underlying != "") || (t.Kind == parser.KindStruct && t.Named) {
+			names = append(names, name)
+		}
+	}
+	for _, fn := range parsed.Functions {
+		if generateInterfaceForFunction(fn) != "" {
+			names = append(names, interfaceName(fn.Name))
+		}
+	}
+	if opts.Diagnostics {
+		names = append(names, "RuntimeStats")
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	image := mustParse(t, `package main
+type Pixel uint8
+type Size struct{ W, H int }
+func Resize(s Size) Size { return s }
+func Info() struct{ Name string } { return struct{ Name string }{} }`)
+	simple := mustParse(t, `package main
+type Size struct{ W int }
+func Greet(name string) string { return name }`)
+
+	content, skipped := GenerateIndex("", "go-image", image, "GoImage", Options{Diagnostics: true})
+	if len(skipped) != 0 {
+		t.Errorf("skipped = %v, want none", skipped)
+	}
+	want := "export { GoImage, type InfoResult, type Pixel, type ResizeResult, type RuntimeStats, type Size } from './go-image';\n"
+	if !strings.HasPrefix(content, indexHeader+"\n") || !strings.HasSuffix(content, "\n"+want) {
+		t.Errorf("first index:\n%s\nwant line %q", content, want)
+	}
+
+	// A second client is added alongside the first, minus names it would
+	// export twice
+	content, skipped = GenerateIndex(content, "go-simple", simple, "GoSimple", Options{})
+	if len(skipped) != 1 || skipped[0] != "Size" {
+		t.Errorf("skipped = %v, want [Size]", skipped)
+	}
+	for _, line := range []string{want, "export { GoSimple } from './go-simple';\n"} {
+		if strings.Count(content, line) != 1 {
+			t.Errorf("merged index missing %q:\n%s", line, content)
+		}
+	}
+	if strings.Index(content, "'./go-image'") > strings.Index(content, "'./go-simple'") {
+		t.Errorf("modules should be sorted:\n%s", content)
+	}
+
+	// Regenerating a client replaces its line, keeping hand-written lines
+	content += "export { helper } from './helper';\n"
+	again, _ := GenerateIndex(content, "go-image", image, "GoImage", Options{Diagnostics: true})
+	if again != content {
+		t.Errorf("regenerating should not change the index:\n%s\nwant:\n%s", again, content)
+	}
+	changed, _ := GenerateIndex(content, "go-image", image, "GoImage", Options{})
+	if strings.Contains(changed, "RuntimeStats") || strings.Count(changed, "'./go-image'") != 1 {
+		t.Errorf("regenerated line not replaced:\n%s", changed)
+	}
+}
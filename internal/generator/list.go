@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+// GenerateListing describes what the generator sees in parsed, for --list:
+// the TypeScript signature of each function, as the sync client declares
+// it, followed by the declarations of the types they use.
+func GenerateListing(parsed *parser.ParsedFile) string {
+	var b strings.Builder
+
+	b.WriteString("Functions:\n")
+	for _, fn := range parsed.Functions {
+		b.WriteString("  ")
+		b.WriteString(fn.BindingName())
+		b.WriteString("(")
+		b.WriteString(generateFunctionParams(fn.Params))
+		b.WriteString("): ")
+		b.WriteString(determineReturnType(fn))
+		if fn.Pos != "" {
+			b.WriteString("  // ")
+			b.WriteString(fn.Pos)
+		}
+		b.WriteString("\n")
+	}
+
+	var types strings.Builder
+	types.WriteString(generateBrandedTypes(parsed.Types))
+	types.WriteString(generateNamedInterfaces(parsed.Types))
+	for _, fn := range parsed.Functions {
+		if iface := generateInterfaceForFunction(fn); iface != "" {
+			types.WriteString(iface)
+			types.WriteString("\n\n")
+		}
+	}
+	if decls := strings.TrimSpace(types.String()); decls != "" {
+		b.WriteString("\nTypes:\n")
+		b.WriteString(decls)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
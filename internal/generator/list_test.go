@@ -0,0 +1,30 @@
+package generator
+
+import "testing"
+
+func TestGenerateListing(t *testing.T) {
+	parsed := mustParse(t, `package main
+import "errors"
+type Color string
+const Red Color = "red"
+type User struct {
+	Name string `+"`json:\"name\"`"+`
+}
+func Greet(name string, u *User) string { return name }
+func Find(id int) (User, error) { return User{}, errors.New("missing") }
+func Stats() struct{ Count int } { return struct{ Count int }{} }`)
+
+	output := GenerateListing(parsed)
+	checkContains("Functions:\n  greet(name: string, u?: User): string  // ")(t, output)
+	checkContains("  find(id: number): User  // ")(t, output)
+	checkContains("  stats(): StatsResult  // ")(t, output)
+	checkContains("\nTypes:\nexport type Color = \"red\";\n")(t, output)
+	checkContains("export interface User {\n  name: string;\n}")(t, output)
+	checkContains("export interface StatsResult {")(t, output)
+}
+
+func TestGenerateListing_NoTypes(t *testing.T) {
+	output := GenerateListing(mustParse(t, "package main\nfunc Add(a, b int) int { return a + b }"))
+	checkContains("  add(a: number, b: number): number")(t, output)
+	checkNotContains("Types:")(t, output)
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"encoding/json"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+// ManifestFileName is the function manifest written by --manifest.
+const ManifestFileName = "manifest.json"
+
+// Manifest lists the functions the Go bindings register, for tooling that
+// validates call sites without parsing the TypeScript client.
+type Manifest struct {
+	Package string `json:"package"`
+
+	// Namespace is the globalThis property the functions are registered
+	// on, empty when they are globals themselves.
+	Namespace string             `json:"namespace,omitempty"`
+	Functions []ManifestFunction `json:"functions"`
+}
+
+// ManifestFunction describes one registered function. Returns is the
+// TypeScript result type as the sync client declares it; the worker client
+// wraps it in a Promise.
+type ManifestFunction struct {
+	Name    string          `json:"name"`
+	Params  []ManifestParam `json:"params"`
+	Returns string          `json:"returns"`
+}
+
+// ManifestParam describes a parameter by its TypeScript type. A variadic
+// parameter has the array type its arguments are collected in.
+type ManifestParam struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+	Variadic bool   `json:"variadic,omitempty"`
+}
+
+// GenerateManifest renders the manifest of parsed as indented JSON.
+func GenerateManifest(parsed *parser.ParsedFile, opts Options) string {
+	manifest := Manifest{
+		Package:   parsed.Package,
+		Namespace: opts.Namespace,
+		Functions: make([]ManifestFunction, len(parsed.Functions)),
+	}
+	for i, fn := range parsed.Functions {
+		optional := optionalParamsStart(fn.Params)
+		params := make([]ManifestParam, len(fn.Params))
+		for j, p := range tsParams(fn.Params) {
+			params[j] = ManifestParam{
+				Name:     p.Name,
+				Type:     parser.GoTypeToTS(p.Type),
+				Optional: j >= optional,
+				Variadic: p.IsVariadic,
+			}
+		}
+		manifest.Functions[i] = ManifestFunction{
+			Name:    fn.BindingName(),
+			Params:  params,
+			Returns: determineReturnType(fn),
+		}
+	}
+
+	out, _ := json.MarshalIndent(manifest, "", "  ") //nolint:errcheck // marshaling strings and bools cannot fail
+	return string(out) + "\n"
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+func TestGenerateManifest(t *testing.T) {
+	str := parser.GoType{Name: "string", Kind: parser.KindPrimitive}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{
+				Name: "Search",
+				Params: []parser.GoParameter{
+					{Name: "query", Type: str},
+					{Name: "limit", Type: parser.GoType{Name: "*int", Kind: parser.KindPointer, Elem: &parser.GoType{Name: "int", Kind: parser.KindPrimitive}}},
+				},
+				Returns: []parser.GoType{{Name: "[]string", Kind: parser.KindSlice, Elem: &str}, {Name: "error", Kind: parser.KindError, IsError: true}},
+			},
+			{Name: "HTTPGet", JSName: "httpGet", Params: []parser.GoParameter{{Name: "url", Type: str}}, Returns: []parser.GoType{str}},
+			{Name: "Reset"},
+		},
+	}
+
+	var got Manifest
+	if err := json.Unmarshal([]byte(GenerateManifest(parsed, Options{Namespace: "lib"})), &got); err != nil {
+		t.Fatalf("GenerateManifest() is not valid JSON: %v", err)
+	}
+	want := Manifest{
+		Package:   "main",
+		Namespace: "lib",
+		Functions: []ManifestFunction{
+			{
+				Name: "search",
+				Params: []ManifestParam{
+					{Name: "query", Type: "string"},
+					{Name: "limit", Type: "number", Optional: true},
+				},
+				Returns: "string[]",
+			},
+			{Name: "httpGet", Params: []ManifestParam{{Name: "url", Type: "string"}}, Returns: "string"},
+			{Name: "reset", Params: []ManifestParam{}, Returns: "void"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateManifest() = %+v, want %+v", got, want)
+	}
+}
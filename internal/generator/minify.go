@@ -0,0 +1,92 @@
+package generator
+
+import "strings"
+
+// Minify strips comments and redundant whitespace from a generated
+// TypeScript or JavaScript file. The leading comments are kept as is, since
+// they hold the generated-file header and the source checksum.
+// Lines are kept separate so automatic semicolon insertion is unaffected.
+//
+// It only needs to handle code the generator emits: string and template
+// literals are copied verbatim, but regular expression literals, which the
+// generator never emits, are not recognized.
+func Minify(src string) string {
+	var out strings.Builder
+
+	// Copy the header
+	rest := src
+	for {
+		var header string
+		var found bool
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			header, rest, found = strings.Cut(rest, "\n")
+		case strings.HasPrefix(rest, "/*"):
+			header, rest, found = strings.Cut(rest, "*/")
+			header += "*/"
+			rest = strings.TrimPrefix(rest, "\n")
+		}
+		if header == "" {
+			break
+		}
+		out.WriteString(header)
+		out.WriteString("\n")
+		if !found {
+			return out.String()
+		}
+	}
+
+	var line []byte
+	flush := func() {
+		if trimmed := strings.TrimSpace(string(line)); trimmed != "" {
+			out.WriteString(trimmed)
+			out.WriteString("\n")
+		}
+		line = line[:0]
+	}
+	space := func() {
+		if len(line) > 0 && line[len(line)-1] != ' ' {
+			line = append(line, ' ')
+		}
+	}
+
+	var quote byte // the open string delimiter, or 0 in code
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case quote != 0:
+			line = append(line, c)
+			if c == '\\' && i+1 < len(rest) {
+				i++
+				line = append(line, rest[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			line = append(line, c)
+		case strings.HasPrefix(rest[i:], "//"):
+			// Skip to the end of the line
+			for i+1 < len(rest) && rest[i+1] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(rest[i:], "/*"):
+			end := strings.Index(rest[i+2:], "*/")
+			if end < 0 {
+				i = len(rest)
+				break
+			}
+			i += end + 3
+			space()
+		case c == '\n':
+			flush()
+		case c == ' ' || c == '\t':
+			space()
+		default:
+			line = append(line, c)
+		}
+	}
+	flush()
+
+	return out.String()
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+func TestMinify(t *testing.T) {
+	src := `// client.ts - Generated by gowasm-bindgen
+// Source checksum: sha256:abc
+
+/** Doc comment. */
+export class Wasm {
+  // line comment
+  greet(name: string): string {
+    const url = 'http://example.com'; // trailing comment
+    const s = "a /* not a comment */ b";
+    const t = ` + "`two\n  lines`" + `;
+    return  name  /* inline */ + 'it\'s';
+  }
+}
+`
+	want := `// client.ts - Generated by gowasm-bindgen
+// Source checksum: sha256:abc
+export class Wasm {
+greet(name: string): string {
+const url = 'http://example.com';
+const s = "a /* not a comment */ b";
+const t = ` + "`two\n  lines`" + `;
+return name + 'it\'s';
+}
+}
+`
+	if got := Minify(src); got != want {
+		t.Errorf("Minify() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMinify_BlockHeader(t *testing.T) {
+	got := Minify("/**\n * Generated by gowasm-bindgen\n */\n\n// setup\nconst go = new Go();\n")
+	want := "/**\n * Generated by gowasm-bindgen\n */\nconst go = new Go();\n"
+	if got != want {
+		t.Errorf("Minify() = %q, want %q", got, want)
+	}
+}
+
+func TestMinify_GeneratedClient(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{{
+			Name:    "Greet",
+			Doc:     "Greet says hello.",
+			Params:  []parser.GoParameter{{Name: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+			Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+		}},
+	}
+
+	for name, src := range map[string]string{
+		"sync":   Generate(parsed, "client.ts", "Wasm", Options{SourceChecksum: "sha256:abc"}),
+		"worker": GenerateClient(parsed, "client.ts", "Wasm", Options{SourceChecksum: "sha256:abc"}),
+	} {
+		got := Minify(src)
+		if len(got) >= len(src) {
+			t.Errorf("%s: minified client is not smaller", name)
+		}
+		if checksum, ok := ReadChecksum([]byte(got)); !ok || checksum != "sha256:abc" {
+			t.Errorf("%s: checksum header lost: %q", name, checksum)
+		}
+		if strings.Contains(got, "Greet says hello") || strings.Contains(got, "\n ") {
+			t.Errorf("%s: comments or indentation left in:\n%s", name, got)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+// GenerateMock creates a mock client for unit tests that runs without WASM.
+// The mock has the same public methods as the generated client class; each
+// method records its arguments in calls and returns the result of a
+// replaceable stub that defaults to the zero value of the return type.
+// clientImport is the module path of the real client (e.g., "./go-wasm").
+func GenerateMock(parsed *parser.ParsedFile, outputFile, className, clientImport string, opts Options) string {
+	var body strings.Builder
+
+	mockName := "Mock" + className
+	body.WriteString("export class ")
+	body.WriteString(mockName)
+	body.WriteString(" implements Pick<")
+	body.WriteString(className)
+	body.WriteString(", keyof ")
+	body.WriteString(className)
+	body.WriteString("> {\n")
+
+	// Stub table: one replaceable implementation per function
+	body.WriteString("  /** Stub implementations; replace entries to control return values. */\n")
+	body.WriteString("  stubs: {\n")
+	for _, fn := range parsed.Functions {
+		fmt.Fprintf(&body, "    %s: (%s) => %s;\n",
+			fn.BindingName(), generateFunctionParams(fn.Params), determineReturnType(fn))
+	}
+	body.WriteString("  } = {\n")
+	for _, fn := range parsed.Functions {
+		fmt.Fprintf(&body, "    %s: () => %s,\n", fn.BindingName(), mockZeroValue(fn))
+	}
+	body.WriteString("  };\n\n")
+
+	body.WriteString("  /** Every call made on the mock, in order. */\n")
+	body.WriteString("  calls: { method: string; args: unknown[] }[] = [];\n")
+
+	for _, fn := range parsed.Functions {
+		funcName := fn.BindingName()
+		returnType := determineReturnType(fn)
+		fn.Params = tsParams(fn.Params)
+
+		argNames := make([]string, len(fn.Params))
+		for i, p := range fn.Params {
+			argNames[i] = callArg(p)
+		}
+		args := strings.Join(argNames, ", ")
+
+		body.WriteString("\n  ")
+		body.WriteString(funcName)
+		body.WriteString("(")
+		body.WriteString(generateFunctionParams(fn.Params))
+		body.WriteString("): ")
+		if opts.WorkerMode {
+			body.WriteString("Promise<" + returnType + ">")
+		} else {
+			body.WriteString(returnType)
+		}
+		body.WriteString(" {\n")
+		fmt.Fprintf(&body, "    this.calls.push({ method: '%s', args: [%s] });\n", funcName, args)
+		if opts.WorkerMode {
+			fmt.Fprintf(&body, "    return Promise.resolve(this.stubs.%s(%s));\n", funcName, args)
+		} else {
+			fmt.Fprintf(&body, "    return this.stubs.%s(%s);\n", funcName, args)
+		}
+		body.WriteString("  }\n")
+	}
+
+	if opts.Diagnostics {
+		body.WriteString("\n  stats(): ")
+		if opts.WorkerMode {
+			body.WriteString("Promise<RuntimeStats> {\n")
+			body.WriteString("    return Promise.resolve({ numGoroutine: 0, alloc: 0, totalAlloc: 0, sys: 0, numGC: 0 });\n")
+		} else {
+			body.WriteString("RuntimeStats {\n")
+			body.WriteString("    return { numGoroutine: 0, alloc: 0, totalAlloc: 0, sys: 0, numGC: 0 };\n")
+		}
+		body.WriteString("  }\n")
+	}
+
+	if opts.WorkerMode {
+		if opts.SplitClient {
+			body.WriteString("\n  handleMessage(): void {}\n")
+		}
+		body.WriteString("\n  terminate(): void {}\n")
+	}
+
+	body.WriteString("}\n")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `// %s - Generated by gowasm-bindgen
+// Package: %s
+//
+// Test double for %s that runs without loading WASM.
+
+`, outputFile, parsed.Package, className)
+	b.WriteString("import type { ")
+	b.WriteString(strings.Join(mockImports(parsed, className, body.String()), ", "))
+	b.WriteString(" } from '")
+	b.WriteString(clientImport)
+	b.WriteString("';\n\n")
+	b.WriteString(body.String())
+
+	return b.String()
+}
+
+// mockZeroValue returns a TypeScript expression for the zero value of a
+// function's return type, used as the default stub result.
+func mockZeroValue(fn parser.GoFunction) string {
+	if fn.ResultUnion {
+		if determineValueType(fn) == "void" {
+			return "{ ok: true }"
+		}
+		valueFn := fn
+		valueFn.ResultUnion = false
+		return "{ ok: true, value: " + mockZeroValue(valueFn) + " }"
+	}
+
+	returnType := determineReturnType(fn)
+	if returnType == "void" {
+		return "undefined"
+	}
+
+	// Branded named primitives are zero values of their underlying type
+	ret := fn.Returns[0]
+	if literals := enumLiterals(ret); literals != nil {
+		return literals[0]
+	}
+	if ret.Kind == parser.KindPrimitive && ret.Underlying != "" {
+		underlying := parser.GoType{Name: ret.Underlying, Kind: parser.KindPrimitive, BigInt: ret.BigInt}
+		return tsZeroValue(parser.GoTypeToTS(underlying)) + " as " + returnType
+	}
+
+	return tsZeroValue(returnType)
+}
+
+// tsZeroValue returns a TypeScript zero-value expression for a type string.
+func tsZeroValue(tsType string) string {
+	switch {
+	case tsType == "string":
+		return "''"
+	case tsType == "number":
+		return "0"
+	case tsType == "bigint":
+		return "0n"
+	case tsType == "boolean":
+		return "false"
+	case tsType == "Date":
+		return "new Date(0)"
+	case tsType == "any" || tsType == "unknown":
+		return "undefined"
+	case strings.HasSuffix(tsType, " | null"):
+		return "null"
+	case strings.HasSuffix(tsType, "[]"):
+		return "[]"
+	case strings.HasSuffix(tsType, "Array") && !strings.ContainsAny(tsType, " <{"):
+		// Typed arrays such as Uint8Array
+		return "new " + tsType + "()"
+	default:
+		return "{} as " + tsType
+	}
+}
+
+// wordPattern matches identifiers in generated TypeScript.
+var wordPattern = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// mockImports returns the client module's exported names referenced by the mock body.
+func mockImports(parsed *parser.ParsedFile, className, body string) []string {
+	candidates := map[string]bool{"RuntimeStats": true}
+	for _, fn := range parsed.Functions {
+		candidates[interfaceName(fn.Name)] = true
+	}
+	for name, t := range parsed.Types {
+		if (t.Kind == parser.KindPrimitive && t.Underlying != "") || (t.Kind == parser.KindStruct && t.Named) {
+			candidates[name] = true
+		}
+	}
+
+	used := map[string]bool{}
+	for _, word := range wordPattern.FindAllString(body, -1) {
+		if candidates[word] {
+			used[word] = true
+		}
+	}
+
+	imports := []string{className}
+	for name := range used {
+		imports = append(imports, name)
+	}
+	sort.Strings(imports[1:])
+	return imports
+}
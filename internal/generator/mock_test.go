@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+)
+
+func TestGenerateMock(t *testing.T) {
+	score := parser.GoType{Name: "Score", Kind: parser.KindPrimitive, Underlying: "int32"}
+	status := parser.GoType{
+		Name:         "Status",
+		Kind:         parser.KindPrimitive,
+		Underlying:   "string",
+		EnumValues:   []string{"Active", "Inactive"},
+		EnumLiterals: []string{"active", "inactive"},
+	}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{
+				Name:    "Greet",
+				Params:  []parser.GoParameter{{Name: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+				Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+			},
+			{
+				Name: "GetInfo",
+				Returns: []parser.GoType{{
+					Name:   "Info",
+					Kind:   parser.KindStruct,
+					Fields: []parser.GoField{{Name: "Name", JSONTag: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+				}},
+			},
+			{Name: "Best", Returns: []parser.GoType{score}},
+			{Name: "State", Returns: []parser.GoType{status}},
+			{Name: "Bytes", Returns: []parser.GoType{{Name: "[]byte", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "byte", Kind: parser.KindPrimitive}}}},
+			{Name: "Reset"},
+		},
+		Types: map[string]*parser.GoType{"Score": &score, "Status": &status},
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "sync",
+			opts: Options{},
+			want: []string{
+				"import type { Wasm, GetInfoResult, Score, Status } from './client';",
+				"export class MockWasm implements Pick<Wasm, keyof Wasm> {",
+				"greet: (name: string) => string;",
+				"greet: () => '',",
+				"getInfo: () => {} as GetInfoResult,",
+				"best: () => 0 as Score,",
+				`state: () => "active",`,
+				"bytes: () => new Uint8Array(),",
+				"reset: () => undefined,",
+				"greet(name: string): string {",
+				"this.calls.push({ method: 'greet', args: [name] });",
+				"return this.stubs.greet(name);",
+			},
+			notWant: []string{"terminate()", "Promise"},
+		},
+		{
+			name: "worker",
+			opts: Options{WorkerMode: true},
+			want: []string{
+				"greet(name: string): Promise<string> {",
+				"return Promise.resolve(this.stubs.greet(name));",
+				"terminate(): void {}",
+			},
+			notWant: []string{"handleMessage", "stats()"},
+		},
+		{
+			name: "worker with diagnostics and split client",
+			opts: Options{WorkerMode: true, Diagnostics: true, SplitClient: true},
+			want: []string{
+				"import type { Wasm, GetInfoResult, RuntimeStats, Score, Status } from './client';",
+				"stats(): Promise<RuntimeStats> {",
+				"handleMessage(): void {}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateMock(parsed, "client-mock.ts", "Wasm", "./client", tt.opts)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("GenerateMock() missing %q in output:\n%s", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("GenerateMock() should not contain %q in output:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTSZeroValue(t *testing.T) {
+	tests := []struct {
+		tsType string
+		want   string
+	}{
+		{"string", "''"},
+		{"number", "0"},
+		{"bigint", "0n"},
+		{"Date", "new Date(0)"},
+		{"boolean", "false"},
+		{"string[]", "[]"},
+		{"Float64Array", "new Float64Array()"},
+		{"Int32Array | null", "null"},
+		{"Record<string, number>", "{} as Record<string, number>"},
+		{"FormatUserResult", "{} as FormatUserResult"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tsType, func(t *testing.T) {
+			if got := tsZeroValue(tt.tsType); got != tt.want {
+				t.Errorf("tsZeroValue(%q) = %q, want %q", tt.tsType, got, tt.want)
+			}
+		})
+	}
+}
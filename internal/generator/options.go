@@ -0,0 +1,65 @@
+package generator
+
+// Options configures optional features of the generated Go bindings and
+// TypeScript clients. The zero value produces the default output.
+type Options struct {
+	// WorkerMode generates postMessage-based callback invocation (true)
+	// instead of direct JS function invocation (false).
+	WorkerMode bool
+
+	// Diagnostics registers a runtime stats global in the Go bindings and
+	// exposes it as a stats() method on the TypeScript client.
+	Diagnostics bool
+
+	// SplitClient moves worker startup out of the worker-mode class into a
+	// separate init module (see GenerateClientInit) for code-splitting.
+	SplitClient bool
+
+	// WorkerPool, when positive, makes the worker-mode client spread calls
+	// across a pool of workers (WorkerPool by default) instead of using one.
+	// Not supported together with SplitClient.
+	WorkerPool int
+
+	// SharedMemory returns []byte and numeric slice results in
+	// SharedArrayBuffer-backed typed arrays when the page is cross-origin
+	// isolated, so they reach the main thread and other workers without a
+	// structured-clone copy.
+	SharedMemory bool
+
+	// StreamBytes makes worker-mode bindings post large []byte results to
+	// the main thread in chunks whose buffers are transferred rather than
+	// cloned; the client reassembles them (see ChunksFieldName).
+	StreamBytes bool
+
+	// GoPackage, when non-empty, replaces the parsed package name in the
+	// package clause of the Go bindings.
+	GoPackage string
+
+	// Namespace, when non-empty, registers the exported functions on the
+	// global object of that name instead of directly on the global scope.
+	Namespace string
+
+	// CommonJS makes the TypeScript client export its runtime values with
+	// module.exports instead of ESM export declarations. Types keep their
+	// export, which is erased on compilation.
+	CommonJS bool
+
+	// Deno targets the Deno runtime: imports between generated modules keep
+	// their .ts extension, workers are module workers, and the sync client
+	// reads local .wasm paths with Deno.readFile instead of fetching them.
+	Deno bool
+
+	// Node targets Node.js with the sync client: it loads wasm_exec.js
+	// itself and reads local .wasm paths with fs.readFile instead of
+	// fetching them.
+	Node bool
+
+	// ErrorKey replaces ErrorFieldName as the field that carries Go errors
+	// from the bindings to the client, for results that use __error
+	// themselves. It must be a JavaScript identifier.
+	ErrorKey string
+
+	// SourceChecksum is recorded in the header of the Go bindings and the
+	// TypeScript client when non-empty (see ChecksumSource and ReadChecksum).
+	SourceChecksum string
+}
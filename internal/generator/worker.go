@@ -9,7 +9,35 @@ import (
 
 // GenerateWorker creates worker.js content that runs Go WASM in a Web Worker.
 // The wasmPath parameter specifies the path to the WASM file (e.g., "module.wasm").
-func GenerateWorker(wasmPath string) string {
+// For Deno, which only runs module workers, the runtime is imported instead
+// of loaded with importScripts, and wasmPath is resolved against the
+// worker's own URL.
+func GenerateWorker(wasmPath string, opts Options) string {
+	target := "self"
+	if opts.Namespace != "" {
+		target = "self." + opts.Namespace
+	}
+
+	var chunks, trackCall string
+	if opts.StreamBytes {
+		chunks = `
+// ID of the call running now. Go posts large []byte results through
+// postChunk before returning; each chunk's buffer is transferred, not copied.
+let currentId = 0;
+self.postChunk = function(chunk) {
+  self.postMessage({ type: 'chunk', id: currentId, result: chunk }, [chunk.buffer]);
+};
+`
+		trackCall = "  currentId = id;\n"
+	}
+
+	loadRuntime := "importScripts('wasm_exec.js');"
+	wasmURL := "'" + wasmPath + "'"
+	if opts.Deno {
+		loadRuntime = "import './wasm_exec.js';"
+		wasmURL = "new URL('" + wasmPath + "', import.meta.url)"
+	}
+
 	return `/**
  * Go WASM Web Worker
  * Generated by gowasm-bindgen
@@ -19,7 +47,7 @@ func GenerateWorker(wasmPath string) string {
  */
 
 // Load Go WASM runtime
-importScripts('wasm_exec.js');
+` + loadRuntime + `
 
 const go = new Go();
 let wasmReady = false;
@@ -30,9 +58,23 @@ self.invokeCallback = function(callbackId, args) {
   self.postMessage({ type: 'invokeCallback', callbackId: callbackId, args: args });
 };
 
+// IDs of calls currently running. A cancel message from the main thread
+// removes its ID, so cooperative cancellation can check __inFlight.has(id).
+self.__inFlight = new Set();
+` + chunks + `
+// Compile while downloading when the server sends exactly application/wasm,
+// which instantiateStreaming requires; otherwise buffer the whole module first
+function instantiateResponse(response) {
+  const contentType = response.headers.get('Content-Type') || '';
+  if (typeof WebAssembly.instantiateStreaming === 'function' && contentType.trim() === 'application/wasm') {
+    return WebAssembly.instantiateStreaming(response, go.importObject);
+  }
+  return response.arrayBuffer().then(bytes => WebAssembly.instantiate(bytes, go.importObject));
+}
+
 // Initialize WASM
-fetch('` + wasmPath + `')
-  .then(response => WebAssembly.instantiateStreaming(response, go.importObject))
+fetch(` + wasmURL + `)
+  .then(instantiateResponse)
   .then(result => {
     go.run(result.instance);
     wasmReady = true;
@@ -44,32 +86,52 @@ fetch('` + wasmPath + `')
 
 // Handle function calls from main thread
 self.onmessage = (event) => {
-  const { id, fn, args } = event.data;
+  const { type, id, fn, args } = event.data;
+
+  if (type === 'cancel') {
+    self.__inFlight.delete(id);
+    return;
+  }
 
   if (!wasmReady) {
     self.postMessage({ id, error: 'WASM not ready' });
     return;
   }
 
-  try {
-    const result = self[fn](...args);
-    self.postMessage({ id, result });
+  self.__inFlight.add(id);
+` + trackCall + `  try {
+    const result = ` + target + `[fn](...args);
+    // Typed array results are built in fresh buffers, so move them instead of copying
+    const transfer = ArrayBuffer.isView(result) && result.buffer instanceof ArrayBuffer ? [result.buffer] : [];
+    self.postMessage({ id, result }, transfer);
   } catch (error) {
     self.postMessage({ id, error: error.message });
+  } finally {
+    self.__inFlight.delete(id);
   }
 };
 `
 }
 
 // GenerateClient creates client.ts with a class-based API for worker mode.
-func GenerateClient(parsed *parser.ParsedFile, outputFile, className string) string {
+func GenerateClient(parsed *parser.ParsedFile, outputFile, className string, opts Options) string {
 
 	var b strings.Builder
 
 	b.WriteString(fmt.Sprintf(`// %s - Generated by gowasm-bindgen
 // Package: %s
-
 `, outputFile, parsed.Package))
+	if opts.WorkerPool > 0 {
+		b.WriteString(`//
+// Worker pool: every worker instantiates its own copy of the WASM module,
+// with its own Go heap and package state, so memory use grows linearly with
+// the pool size. Size the pool for the work, not just the core count.
+`)
+	}
+	b.WriteString(checksumHeader(opts))
+	b.WriteString("\n")
+	b.WriteString(generateBrandedTypes(parsed.Types))
+	b.WriteString(generateNamedInterfaces(parsed.Types))
 
 	// Generate named interfaces for struct return types
 	for _, fn := range parsed.Functions {
@@ -78,120 +140,463 @@ func GenerateClient(parsed *parser.ParsedFile, outputFile, className string) str
 			b.WriteString("\n\n")
 		}
 	}
+	if opts.Diagnostics {
+		b.WriteString(tsRuntimeStatsInterface)
+		b.WriteString("\n\n")
+	}
+
+	fmt.Fprintf(&b, tsWasmError, valueExport(opts), errorKey(opts))
 
 	// Generate the class
-	b.WriteString("export class ")
+	b.WriteString(valueExport(opts))
+	b.WriteString("class ")
 	b.WriteString(className)
 	b.WriteString(" {\n")
-	b.WriteString("  private worker: Worker;\n")
-	b.WriteString("  private requestId = 0;\n")
-	b.WriteString("  private pending = new Map<number, { resolve: (v: unknown) => void; reject: (e: Error) => void }>();\n")
+	pool := opts.WorkerPool > 0
+	transfer := hasTransferredParams(parsed)
+	callParams := "fn: string, args: unknown[], signal?: AbortSignal"
+	if transfer {
+		callParams += ", transfer: Uint8Array[] = []"
+	}
+	// Calls collect the chunks of a streamed []byte result until it settles
+	chunksField, chunksInit := "", ""
+	if opts.StreamBytes {
+		chunksField, chunksInit = "; chunks: Uint8Array[]", ", chunks: []"
+	}
+	if pool {
+		b.WriteString("  private workers: Worker[];\n")
+		b.WriteString("  private inFlight: number[];\n")
+		b.WriteString("  private requestId = 0;\n")
+		b.WriteString("  private pending = new Map<number, { resolve: (v: unknown) => void; reject: (e: Error) => void; worker: number" + chunksField + " }>();\n")
+	} else {
+		b.WriteString("  private worker: Worker;\n")
+		b.WriteString("  private requestId = 0;\n")
+		b.WriteString("  private pending = new Map<number, { resolve: (v: unknown) => void; reject: (e: Error) => void" + chunksField + " }>();\n")
+	}
 	b.WriteString("  private nextCallbackId = 0;\n")
 	b.WriteString("  private callbacks = new Map<number, (...args: unknown[]) => void>();\n\n")
 
-	b.WriteString("  private constructor(worker: Worker) {\n")
-	b.WriteString("    this.worker = worker;\n")
+	if opts.SplitClient {
+		// Construction and message routing are public so the init module can drive them
+		b.WriteString("  /**\n")
+		b.WriteString("   * Wraps a worker created by init() in the companion init module.\n")
+		b.WriteString("   */\n")
+		b.WriteString("  constructor(worker: Worker) {\n")
+		b.WriteString("    this.worker = worker;\n")
+		b.WriteString("  }\n\n")
+
+		b.WriteString("  /**\n")
+		b.WriteString("   * Routes a worker message to its pending call or callback.\n")
+		b.WriteString("   * Called by the init module for every message after 'ready'.\n")
+		b.WriteString("   */\n")
+		b.WriteString("  handleMessage(data: any): void {\n")
+		b.WriteString("    const { type, id, result, error, callbackId, args } = data;\n")
+		writeMessageRouting(&b, "this", "    ", false, opts.StreamBytes, errorKey(opts))
+		b.WriteString("  }\n\n")
+	} else if pool {
+		writeWorkerPoolInit(&b, className, opts)
+	} else {
+		b.WriteString("  private constructor(worker: Worker) {\n")
+		b.WriteString("    this.worker = worker;\n")
+		b.WriteString("  }\n\n")
+
+		// Static init method
+		b.WriteString("  static async init(workerUrl: " + workerURLType(opts) + "): Promise<")
+		b.WriteString(className)
+		b.WriteString("> {\n")
+		b.WriteString("    const worker = " + newWorker(opts) + ";\n")
+		b.WriteString("    const instance = new ")
+		b.WriteString(className)
+		b.WriteString("(worker);\n\n")
+
+		b.WriteString("    await new Promise<void>((resolve, reject) => {\n")
+		b.WriteString("      worker.onmessage = (event) => {\n")
+		b.WriteString("        const { type, id, result, error, callbackId, args } = event.data;\n")
+		b.WriteString("        if (type === 'ready') {\n")
+		b.WriteString("          resolve();\n")
+		b.WriteString("          return;\n")
+		b.WriteString("        }\n")
+		writeMessageRouting(&b, "instance", "        ", false, opts.StreamBytes, errorKey(opts))
+		b.WriteString("      };\n")
+		b.WriteString("      worker.onerror = (e) => reject(new Error(e.message || 'Worker failed to load'));\n")
+		b.WriteString("    });\n\n")
+
+		b.WriteString("    return instance;\n")
+		b.WriteString("  }\n\n")
+	}
+
+	if pool {
+		// Terminate method
+		b.WriteString("  terminate(): void {\n")
+		b.WriteString("    this.workers.forEach((worker) => worker.terminate());\n")
+		b.WriteString("  }\n\n")
+
+		// Private call method dispatching to the least busy worker
+		b.WriteString("  private call<T>(" + callParams + "): Promise<T> {\n")
+		b.WriteString("    // Dispatch to the worker with the fewest calls in flight\n")
+		b.WriteString("    let worker = 0;\n")
+		b.WriteString("    for (let i = 1; i < this.workers.length; i++) {\n")
+		b.WriteString("      if (this.inFlight[i] < this.inFlight[worker]) worker = i;\n")
+		b.WriteString("    }\n")
+		b.WriteString("    return new Promise((resolve, reject) => {\n")
+		writeAbortCheck(&b)
+		b.WriteString("      const id = ++this.requestId;\n")
+		writeAbortListener(&b, "this.workers[worker]", true)
+		b.WriteString("      this.inFlight[worker]++;\n")
+		writePending(&b, "worker"+chunksInit)
+		writePostCall(&b, "this.workers[worker]", true, transfer)
+		b.WriteString("      signal?.addEventListener('abort', onAbort, { once: true });\n")
+		b.WriteString("    });\n")
+		b.WriteString("  }\n\n")
+	} else {
+		// Terminate method
+		b.WriteString("  terminate(): void {\n")
+		b.WriteString("    this.worker.terminate();\n")
+		b.WriteString("  }\n\n")
+
+		// Private call method
+		b.WriteString("  private call<T>(" + callParams + "): Promise<T> {\n")
+		b.WriteString("    return new Promise((resolve, reject) => {\n")
+		writeAbortCheck(&b)
+		b.WriteString("      const id = ++this.requestId;\n")
+		writeAbortListener(&b, "this.worker", false)
+		writePending(&b, strings.TrimPrefix(chunksInit, ", "))
+		writePostCall(&b, "this.worker", false, transfer)
+		b.WriteString("      signal?.addEventListener('abort', onAbort, { once: true });\n")
+		b.WriteString("    });\n")
+		b.WriteString("  }\n\n")
+	}
+
+	// Private registerCallback method
+	b.WriteString("  private registerCallback(fn: (...args: unknown[]) => void): number {\n")
+	b.WriteString("    const id = ++this.nextCallbackId;\n")
+	b.WriteString("    this.callbacks.set(id, fn);\n")
+	b.WriteString("    return id;\n")
+	b.WriteString("  }\n")
+
+	// Instance methods
+	for _, fn := range parsed.Functions {
+		b.WriteString("\n")
+		b.WriteString(GenerateWorkerClassMethod(fn))
+	}
+
+	if opts.Diagnostics {
+		b.WriteString("\n")
+		b.WriteString(tsStatsDoc)
+		b.WriteString("  stats(): Promise<RuntimeStats> {\n")
+		b.WriteString("    return this.call<RuntimeStats>(\"")
+		b.WriteString(DiagnosticsFuncName)
+		b.WriteString("\", []);\n")
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("}\n")
+	if opts.CommonJS {
+		b.WriteString(commonJSExports(className))
+	}
+	return b.String()
+}
+
+// writeWorkerPoolInit writes the constructor and static init method of a
+// worker pool client. Every worker loads the same WASM module; init resolves
+// once all of them are ready. opts.WorkerPool is the default size.
+func writeWorkerPoolInit(b *strings.Builder, className string, opts Options) {
+	b.WriteString("  private constructor(workers: Worker[]) {\n")
+	b.WriteString("    this.workers = workers;\n")
+	b.WriteString("    this.inFlight = workers.map(() => 0);\n")
 	b.WriteString("  }\n\n")
 
-	// Static init method
-	b.WriteString("  static async init(workerUrl: string): Promise<")
-	b.WriteString(className)
-	b.WriteString("> {\n")
-	b.WriteString("    const worker = new Worker(workerUrl);\n")
+	b.WriteString("  /**\n")
+	b.WriteString("   * Starts a pool of workers; calls go to the worker with the fewest in flight.\n")
+	b.WriteString("   */\n")
+	fmt.Fprintf(b, "  static async init(workerUrl: %s, size = %d): Promise<%s> {\n", workerURLType(opts), opts.WorkerPool, className)
+	b.WriteString("    const workers = Array.from({ length: size }, () => " + newWorker(opts) + ");\n")
 	b.WriteString("    const instance = new ")
 	b.WriteString(className)
-	b.WriteString("(worker);\n\n")
+	b.WriteString("(workers);\n\n")
 
-	b.WriteString("    await new Promise<void>((resolve, reject) => {\n")
+	b.WriteString("    await Promise.all(workers.map((worker) => new Promise<void>((resolve, reject) => {\n")
 	b.WriteString("      worker.onmessage = (event) => {\n")
 	b.WriteString("        const { type, id, result, error, callbackId, args } = event.data;\n")
 	b.WriteString("        if (type === 'ready') {\n")
 	b.WriteString("          resolve();\n")
 	b.WriteString("          return;\n")
 	b.WriteString("        }\n")
-	b.WriteString("        // Handle callback invocations from Go\n")
-	b.WriteString("        if (type === 'invokeCallback') {\n")
-	b.WriteString("          const callback = instance.callbacks.get(callbackId);\n")
-	b.WriteString("          if (callback) {\n")
-	b.WriteString("            try { callback(...args); }\n")
-	b.WriteString("            catch (e) { console.error('Callback error:', e); }\n")
-	b.WriteString("          }\n")
-	b.WriteString("          return;\n")
-	b.WriteString("        }\n")
-	b.WriteString("        const handler = instance.pending.get(id);\n")
-	b.WriteString("        if (handler) {\n")
-	b.WriteString("          instance.pending.delete(id);\n")
-	b.WriteString("          if (error) {\n")
-	b.WriteString("            handler.reject(new Error(error));\n")
-	b.WriteString("          } else if (result && typeof result === 'object' && '")
-	b.WriteString(ErrorFieldName)
-	b.WriteString("' in result) {\n")
-	b.WriteString("            handler.reject(new Error((result as { ")
-	b.WriteString(ErrorFieldName)
-	b.WriteString(": string }).")
-	b.WriteString(ErrorFieldName)
-	b.WriteString("));\n")
-	b.WriteString("          } else {\n")
-	b.WriteString("            handler.resolve(result);\n")
-	b.WriteString("          }\n")
-	b.WriteString("        }\n")
+	writeMessageRouting(b, "instance", "        ", true, opts.StreamBytes, errorKey(opts))
 	b.WriteString("      };\n")
 	b.WriteString("      worker.onerror = (e) => reject(new Error(e.message || 'Worker failed to load'));\n")
-	b.WriteString("    });\n\n")
+	b.WriteString("    })));\n\n")
 
 	b.WriteString("    return instance;\n")
 	b.WriteString("  }\n\n")
+}
 
-	// Terminate method
-	b.WriteString("  terminate(): void {\n")
-	b.WriteString("    this.worker.terminate();\n")
-	b.WriteString("  }\n\n")
+// writeMessageRouting writes the code that dispatches a worker message to a
+// registered callback or settles the matching pending call. self is the
+// expression for the client instance and indent prefixes every line. With
+// pool set, settling a call also releases its worker's in-flight slot. With
+// stream set, chunk messages are collected and joined into the result.
+// errorKey is the field of a Go error result.
+func writeMessageRouting(b *strings.Builder, self, indent string, pool, stream bool, errorKey string) {
+	lines := []string{
+		"// Handle callback invocations from Go",
+		"if (type === 'invokeCallback') {",
+		"  const callback = " + self + ".callbacks.get(callbackId);",
+		"  if (callback) {",
+		"    try { callback(...args); }",
+		"    catch (e) { console.error('Callback error:', e); }",
+		"  }",
+		"  return;",
+		"}",
+	}
+	if stream {
+		lines = append(lines,
+			"// Collect the chunks of a streamed []byte result",
+			"if (type === 'chunk') {",
+			"  "+self+".pending.get(id)?.chunks.push(result);",
+			"  return;",
+			"}",
+		)
+	}
+	lines = append(lines,
+		"const handler = "+self+".pending.get(id);",
+		"if (handler) {",
+		"  "+self+".pending.delete(id);",
+	)
+	if pool {
+		lines = append(lines, "  "+self+".inFlight[handler.worker]--;")
+	}
+	lines = append(lines,
+		"  if (error) {",
+		"    handler.reject(new Error(error));",
+		"  } else if (isWasmError(result)) {",
+		"    handler.reject(new WasmError(result."+errorKey+"));",
+	)
+	if stream {
+		lines = append(lines,
+			"  } else if (result && typeof result === 'object' && '"+ChunksFieldName+"' in result) {",
+			"    const joined = new Uint8Array((result as { "+ChunksFieldName+": number })."+ChunksFieldName+");",
+			"    let offset = 0;",
+			"    for (const chunk of handler.chunks) {",
+			"      joined.set(chunk, offset);",
+			"      offset += chunk.length;",
+			"    }",
+			"    handler.resolve(joined);",
+		)
+	}
+	lines = append(lines,
+		"  } else {",
+		"    handler.resolve(result);",
+		"  }",
+		"}",
+	)
+	for _, line := range lines {
+		b.WriteString(indent)
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
 
-	// Private call method
-	b.WriteString("  private call<T>(fn: string, args: unknown[]): Promise<T> {\n")
-	b.WriteString("    return new Promise((resolve, reject) => {\n")
-	b.WriteString("      const id = ++this.requestId;\n")
-	b.WriteString("      this.pending.set(id, { resolve: resolve as (v: unknown) => void, reject });\n")
-	b.WriteString("      this.worker.postMessage({ id, fn, args });\n")
-	b.WriteString("    });\n")
-	b.WriteString("  }\n\n")
+// writePostCall writes the postMessage that starts a call on worker. With
+// transfer set, the buffers of the call's transferred arrays are moved to the
+// worker instead of copied, when that is safe. If posting fails, the call is
+// dropped and rejects with the error; with pool set, its in-flight slot on
+// worker number worker is released.
+func writePostCall(b *strings.Builder, worker string, pool, transfer bool) {
+	b.WriteString("      try {\n")
+	if transfer {
+		b.WriteString("        // Only whole buffers move: transferring the buffer of a view into a larger\n")
+		b.WriteString("        // one would detach bytes the caller never passed. Shared buffers can't be\n")
+		b.WriteString("        // transferred, and each buffer may only be listed once.\n")
+		b.WriteString("        const buffers = [...new Set(transfer.filter((a) => a.byteOffset === 0 && a.byteLength === a.buffer.byteLength).map((a) => a.buffer))].filter((buf): buf is ArrayBuffer => buf instanceof ArrayBuffer);\n")
+		b.WriteString("        try {\n")
+		b.WriteString("          " + worker + ".postMessage({ id, fn, args }, buffers);\n")
+		b.WriteString("        } catch {\n")
+		b.WriteString("          // A WebAssembly.Memory buffer can't be detached, so copy the arguments\n")
+		b.WriteString("          " + worker + ".postMessage({ id, fn, args });\n")
+		b.WriteString("        }\n")
+	} else {
+		b.WriteString("        " + worker + ".postMessage({ id, fn, args });\n")
+	}
+	b.WriteString("      } catch (e) {\n")
+	b.WriteString("        this.pending.delete(id);\n")
+	if pool {
+		b.WriteString("        this.inFlight[worker]--;\n")
+	}
+	b.WriteString("        reject(e);\n")
+	b.WriteString("        return;\n")
+	b.WriteString("      }\n")
+}
 
-	// Private registerCallback method
-	b.WriteString("  private registerCallback(fn: (...args: unknown[]) => void): number {\n")
-	b.WriteString("    const id = ++this.nextCallbackId;\n")
-	b.WriteString("    this.callbacks.set(id, fn);\n")
-	b.WriteString("    return id;\n")
-	b.WriteString("  }\n")
+// transferredParams returns the parameters whose Uint8Array argument is
+// transferred to the worker rather than copied: []byte parameters, except
+// variadic ones and those sent as base64 strings.
+func transferredParams(fn parser.GoFunction) []string {
+	var names []string
+	for _, p := range fn.Params {
+		if parser.IsByteSlice(p.Type) && !p.Type.Base64 && !p.IsVariadic {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
 
-	// Instance methods
+// hasTransferredParams reports whether any function transfers an argument.
+func hasTransferredParams(parsed *parser.ParsedFile) bool {
 	for _, fn := range parsed.Functions {
-		b.WriteString("\n")
-		b.WriteString(GenerateWorkerClassMethod(fn))
+		if len(transferredParams(fn)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tsAbortError constructs the error a call rejects with when its signal aborts.
+const tsAbortError = "new DOMException('The operation was aborted.', 'AbortError')"
+
+// writeAbortCheck writes the guard that rejects a call whose signal has
+// already aborted, before anything is posted to a worker.
+func writeAbortCheck(b *strings.Builder) {
+	b.WriteString("      if (signal?.aborted) {\n")
+	b.WriteString("        reject(" + tsAbortError + ");\n")
+	b.WriteString("        return;\n")
+	b.WriteString("      }\n")
+}
+
+// writeAbortListener writes onAbort, the abort handler of a call, which the
+// call registers on its signal once posted. It stops waiting on the call and
+// tells the worker to cancel it; a result that arrives later finds no pending
+// entry and is dropped. worker is the expression for the worker that runs the
+// call. With pool set, aborting also releases its in-flight slot.
+func writeAbortListener(b *strings.Builder, worker string, pool bool) {
+	b.WriteString("      const onAbort = () => {\n")
+	b.WriteString("        if (!this.pending.delete(id)) return;\n")
+	if pool {
+		b.WriteString("        this.inFlight[worker]--;\n")
+	}
+	b.WriteString("        " + worker + ".postMessage({ type: 'cancel', id });\n")
+	b.WriteString("        reject(" + tsAbortError + ");\n")
+	b.WriteString("      };\n")
+}
+
+// writePending writes the pending entry of a call, with the extra fields
+// after resolve and reject, if any. Settling the call removes its abort listener,
+// so a long-lived signal doesn't keep every call it was passed to reachable.
+func writePending(b *strings.Builder, fields string) {
+	b.WriteString("      this.pending.set(id, {\n")
+	b.WriteString("        resolve: (v) => {\n")
+	b.WriteString("          signal?.removeEventListener('abort', onAbort);\n")
+	b.WriteString("          resolve(v as T);\n")
+	b.WriteString("        },\n")
+	b.WriteString("        reject: (e) => {\n")
+	b.WriteString("          signal?.removeEventListener('abort', onAbort);\n")
+	b.WriteString("          reject(e);\n")
+	b.WriteString("        },\n")
+	if fields != "" {
+		b.WriteString("        " + fields + ",\n")
+	}
+	b.WriteString("      });\n")
+}
+
+// newWorker returns the expression that starts a client's worker from
+// workerUrl. Deno only runs module workers (see GenerateWorker).
+func newWorker(opts Options) string {
+	if opts.Deno {
+		return "new Worker(workerUrl, { type: 'module' })"
+	}
+	return "new Worker(workerUrl)"
+}
+
+// workerURLType is the type of the workerUrl parameter of a client's init.
+// Deno resolves relative worker specifiers against no base, so it also
+// takes the URL from new URL('./worker.js', import.meta.url).
+func workerURLType(opts Options) string {
+	if opts.Deno {
+		return "string | URL"
 	}
+	return "string"
+}
+
+// GenerateClientInit creates the init module for --split-client. It holds the
+// worker startup that GenerateClient otherwise emits as a static init method,
+// so bundlers can lazy-load it separately from the call-dispatch class.
+// clientImport is the module path of the class file (e.g., "./go-wasm").
+func GenerateClientInit(parsed *parser.ParsedFile, outputFile, className, clientImport string, opts Options) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `// %s - Generated by gowasm-bindgen
+// Package: %s
+//
+// Worker startup for %s. Load it lazily to defer WASM setup:
+//   const { init } = await import('%s');
+
+import { %s } from '%s';
+
+`, outputFile, parsed.Package, className, ModulePath(outputFile, opts), className, clientImport)
 
+	b.WriteString("export async function init(workerUrl: " + workerURLType(opts) + "): Promise<")
+	b.WriteString(className)
+	b.WriteString("> {\n")
+	b.WriteString("  const worker = " + newWorker(opts) + ";\n")
+	b.WriteString("  const instance = new ")
+	b.WriteString(className)
+	b.WriteString("(worker);\n\n")
+	b.WriteString("  await new Promise<void>((resolve, reject) => {\n")
+	b.WriteString("    worker.onmessage = (event) => {\n")
+	b.WriteString("      if (event.data.type === 'ready') {\n")
+	b.WriteString("        resolve();\n")
+	b.WriteString("        return;\n")
+	b.WriteString("      }\n")
+	b.WriteString("      instance.handleMessage(event.data);\n")
+	b.WriteString("    };\n")
+	b.WriteString("    worker.onerror = (e) => reject(new Error(e.message || 'Worker failed to load'));\n")
+	b.WriteString("  });\n\n")
+	b.WriteString("  return instance;\n")
 	b.WriteString("}\n")
+
 	return b.String()
 }
 
 // GenerateWorkerClassMethod creates a single async instance method for worker mode.
+// Methods take an optional trailing { signal } argument to abort the call,
+// except for variadic functions, whose rest parameter must come last.
 func GenerateWorkerClassMethod(fn parser.GoFunction) string {
 	var b strings.Builder
 
-	// JSDoc if present
-	if fn.Doc != "" {
-		lines := strings.Split(fn.Doc, "\n")
-		b.WriteString("  /**\n")
-		for _, line := range lines {
-			b.WriteString("   * ")
-			b.WriteString(line)
-			b.WriteString("\n")
+	doc := generateJSDoc(fn)
+	fn.Params = tsParams(fn.Params)
+	transferred := transferredParams(fn)
+	if len(transferred) > 0 {
+		note := "   * Transferred to the worker, leaving the caller's array detached (empty): " +
+			strings.Join(transferred, ", ") + "\n"
+		if doc == "" {
+			doc = "  /**\n" + note + "   */\n"
+		} else {
+			doc = strings.TrimSuffix(doc, "   */\n") + "   *\n" + note + "   */\n"
 		}
-		b.WriteString("   */\n")
 	}
+	b.WriteString(doc)
 
 	params := generateFunctionParams(fn.Params)
 	returnType := determineReturnType(fn)
-	funcName := LowerFirst(fn.Name)
+	funcName := fn.BindingName()
+
+	// Pass the abort signal through to call() unless the method can't take options
+	signalArg := ""
+	if n := len(fn.Params); n == 0 || !fn.Params[n-1].IsVariadic {
+		optionsName := callOptionsName(fn.Params)
+		if params != "" {
+			params += ", "
+		}
+		params += optionsName + "?: { signal?: AbortSignal }"
+		signalArg = ", " + optionsName + "?.signal"
+	}
+	if len(transferred) > 0 {
+		if signalArg == "" {
+			signalArg = ", undefined"
+		}
+		signalArg += ", [" + strings.Join(transferred, ", ") + "]"
+	}
 
 	// Check if any parameters are callbacks
 	var callbackParams []int
@@ -213,9 +618,15 @@ func GenerateWorkerClassMethod(fn parser.GoFunction) string {
 	if hasCallbacks {
 		// Register callbacks and get their IDs
 		// Cast to unknown[] => void since registerCallback uses a generic signature
+		// Left-out optional callbacks are passed as 0, which Go ignores
+		optional := optionalParamsStart(fn.Params)
 		for _, idx := range callbackParams {
 			paramName := fn.Params[idx].Name
-			b.WriteString(fmt.Sprintf("    const %sId = this.registerCallback(%s as (...args: unknown[]) => void);\n", paramName, paramName))
+			register := fmt.Sprintf("this.registerCallback(%s as (...args: unknown[]) => void)", paramName)
+			if idx >= optional {
+				register = paramName + " ? " + register + " : 0"
+			}
+			b.WriteString(fmt.Sprintf("    const %sId = %s;\n", paramName, register))
 		}
 
 		// Build the call with .finally() for cleanup
@@ -231,12 +642,14 @@ func GenerateWorkerClassMethod(fn parser.GoFunction) string {
 			if p.Type.Kind == parser.KindFunction {
 				argNames[i] = p.Name + "Id"
 			} else {
-				argNames[i] = p.Name
+				argNames[i] = callArg(p)
 			}
 		}
 		b.WriteString(strings.Join(argNames, ", "))
 
-		b.WriteString("]).finally(() => {\n")
+		b.WriteString("]")
+		b.WriteString(signalArg)
+		b.WriteString(").finally(() => {\n")
 
 		// Clean up all registered callbacks
 		for _, idx := range callbackParams {
@@ -256,14 +669,29 @@ func GenerateWorkerClassMethod(fn parser.GoFunction) string {
 		// Generate argument list
 		argNames := make([]string, len(fn.Params))
 		for i, p := range fn.Params {
-			argNames[i] = p.Name
+			argNames[i] = callArg(p)
 		}
 		b.WriteString(strings.Join(argNames, ", "))
 
-		b.WriteString("]);\n")
+		b.WriteString("]")
+		b.WriteString(signalArg)
+		b.WriteString(");\n")
 	}
 
 	b.WriteString("  }\n")
 
 	return b.String()
 }
+
+// callOptionsName returns the name of the trailing call options parameter,
+// avoiding a clash with the function's own parameter names.
+func callOptionsName(params []parser.GoParameter) string {
+	name := "options"
+	for i := 0; i < len(params); i++ {
+		if params[i].Name == name {
+			name = "_" + name
+			i = -1
+		}
+	}
+	return name
+}
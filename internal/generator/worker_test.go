@@ -8,7 +8,7 @@ import (
 )
 
 func TestGenerateWorker(t *testing.T) {
-	worker := GenerateWorker("module.wasm")
+	worker := GenerateWorker("module.wasm", Options{})
 
 	// Check key parts of the worker
 	if !strings.Contains(worker, "importScripts('wasm_exec.js')") {
@@ -25,6 +25,89 @@ func TestGenerateWorker(t *testing.T) {
 	}
 }
 
+func TestGenerateWorker_Instantiate(t *testing.T) {
+	worker := GenerateWorker("module.wasm", Options{})
+	for _, want := range []string{
+		"fetch('module.wasm')\n  .then(instantiateResponse)",
+		"contentType.trim() === 'application/wasm') {\n    return WebAssembly.instantiateStreaming(response, go.importObject);",
+		"return response.arrayBuffer().then(bytes => WebAssembly.instantiate(bytes, go.importObject));",
+	} {
+		if !strings.Contains(worker, want) {
+			t.Errorf("worker missing %q:\n%s", want, worker)
+		}
+	}
+}
+
+func TestGenerateWorker_Cancel(t *testing.T) {
+	worker := GenerateWorker("module.wasm", Options{})
+	for _, want := range []string{
+		"self.__inFlight = new Set();",
+		"if (type === 'cancel') {\n    self.__inFlight.delete(id);\n    return;\n  }",
+		"self.__inFlight.add(id);",
+		"} finally {\n    self.__inFlight.delete(id);\n  }",
+	} {
+		if !strings.Contains(worker, want) {
+			t.Errorf("worker missing %q:\n%s", want, worker)
+		}
+	}
+}
+
+func TestGenerateWorker_TransferResult(t *testing.T) {
+	worker := GenerateWorker("module.wasm", Options{})
+	for _, want := range []string{
+		"const transfer = ArrayBuffer.isView(result) && result.buffer instanceof ArrayBuffer ? [result.buffer] : [];",
+		"self.postMessage({ id, result }, transfer);",
+	} {
+		if !strings.Contains(worker, want) {
+			t.Errorf("worker missing %q:\n%s", want, worker)
+		}
+	}
+}
+
+func TestGenerateWorker_StreamBytes(t *testing.T) {
+	worker := GenerateWorker("module.wasm", Options{StreamBytes: true})
+	for _, want := range []string{
+		"let currentId = 0;",
+		"self.postMessage({ type: 'chunk', id: currentId, result: chunk }, [chunk.buffer]);",
+		"self.__inFlight.add(id);\n  currentId = id;\n  try {",
+	} {
+		if !strings.Contains(worker, want) {
+			t.Errorf("worker missing %q:\n%s", want, worker)
+		}
+	}
+
+	if worker := GenerateWorker("module.wasm", Options{}); strings.Contains(worker, "postChunk") {
+		t.Errorf("worker should only define postChunk with StreamBytes:\n%s", worker)
+	}
+}
+
+func TestGenerateWorker_Namespace(t *testing.T) {
+	worker := GenerateWorker("module.wasm", Options{Namespace: "mylib"})
+	if !strings.Contains(worker, "const result = self.mylib[fn](...args);") {
+		t.Errorf("worker should call functions on the namespace:\n%s", worker)
+	}
+
+	worker = GenerateWorker("module.wasm", Options{})
+	if !strings.Contains(worker, "const result = self[fn](...args);") {
+		t.Errorf("worker should call global functions by default:\n%s", worker)
+	}
+}
+
+func TestGenerateWorker_Deno(t *testing.T) {
+	worker := GenerateWorker("module.wasm", Options{Deno: true})
+	for _, want := range []string{
+		"import './wasm_exec.js';",
+		"fetch(new URL('module.wasm', import.meta.url))",
+	} {
+		if !strings.Contains(worker, want) {
+			t.Errorf("Deno worker missing %q:\n%s", want, worker)
+		}
+	}
+	if strings.Contains(worker, "importScripts") {
+		t.Errorf("Deno module worker cannot use importScripts:\n%s", worker)
+	}
+}
+
 func TestGenerateWorkerCustomPath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -39,7 +122,7 @@ func TestGenerateWorkerCustomPath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			worker := GenerateWorker(tt.wasmPath)
+			worker := GenerateWorker(tt.wasmPath, Options{})
 			if !strings.Contains(worker, tt.want) {
 				t.Errorf("GenerateWorker(%q) should contain %q", tt.wasmPath, tt.want)
 			}
@@ -81,7 +164,7 @@ func TestGenerateClient(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	client := GenerateClient(parsed, "client.ts", "Wasm")
+	client := GenerateClient(parsed, "client.ts", "Wasm", Options{})
 
 	// Check header
 	if !strings.Contains(client, "// client.ts - Generated by gowasm-bindgen") {
@@ -112,20 +195,46 @@ func TestGenerateClient(t *testing.T) {
 	}
 
 	// Check method for greet (lowercased)
-	if !strings.Contains(client, "greet(name: string): Promise<string>") {
+	if !strings.Contains(client, "greet(name: string, options?: { signal?: AbortSignal }): Promise<string>") {
 		t.Error("client should have greet method")
 	}
-	if !strings.Contains(client, `return this.call<string>("greet", [name])`) {
+	if !strings.Contains(client, `return this.call<string>("greet", [name], options?.signal)`) {
 		t.Error("client should call greet with correct args")
 	}
 
+	// Check aborting a call stops waiting and tells the worker
+	for _, want := range []string{
+		"private call<T>(fn: string, args: unknown[], signal?: AbortSignal): Promise<T> {",
+		"if (signal?.aborted) {\n        reject(new DOMException('The operation was aborted.', 'AbortError'));",
+		"const onAbort = () => {\n        if (!this.pending.delete(id)) return;\n        this.worker.postMessage({ type: 'cancel', id });",
+		"signal?.addEventListener('abort', onAbort, { once: true });",
+		// Settling removes the listener from a signal that outlives the call
+		"resolve: (v) => {\n          signal?.removeEventListener('abort', onAbort);\n          resolve(v as T);\n        },",
+		"reject: (e) => {\n          signal?.removeEventListener('abort', onAbort);\n          reject(e);\n        },\n      });",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client missing %q", want)
+		}
+	}
+
+	// Check Go errors reject with WasmError, recognized by the exported guard
+	for _, want := range []string{
+		"export class WasmError extends Error {",
+		"export function isWasmError(x: unknown): x is { __error: string } {",
+		"} else if (isWasmError(result)) {\n            handler.reject(new WasmError(result.__error));",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client missing %q", want)
+		}
+	}
+
 	// Check interface for object return
 	if !strings.Contains(client, "export interface FormatUserResult") {
 		t.Error("client should have FormatUserResult interface")
 	}
 
 	// Check method for formatUser (lowercased)
-	if !strings.Contains(client, "formatUser(name: string, age: number): Promise<FormatUserResult>") {
+	if !strings.Contains(client, "formatUser(name: string, age: number, options?: { signal?: AbortSignal }): Promise<FormatUserResult>") {
 		t.Error("client should have formatUser method")
 	}
 }
@@ -148,7 +257,7 @@ func TestGenerateClientWithDifferentPackage(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	client := GenerateClient(parsed, "calculator.ts", "Calculator")
+	client := GenerateClient(parsed, "calculator.ts", "Calculator", Options{})
 
 	// Check class name
 	if !strings.Contains(client, "export class Calculator {") {
@@ -159,6 +268,220 @@ func TestGenerateClientWithDifferentPackage(t *testing.T) {
 	}
 }
 
+func TestGenerateClient_Diagnostics(t *testing.T) {
+	parsed := &parser.ParsedFile{Package: "main", Functions: []parser.GoFunction{}}
+
+	client := GenerateClient(parsed, "client.ts", "Wasm", Options{Diagnostics: true})
+	if !strings.Contains(client, "export interface RuntimeStats {") {
+		t.Error("client should have RuntimeStats interface")
+	}
+	if !strings.Contains(client, "stats(): Promise<RuntimeStats> {") {
+		t.Error("client should have stats method")
+	}
+	if !strings.Contains(client, `return this.call<RuntimeStats>("__gowasmStats", [])`) {
+		t.Error("client should call the diagnostics global")
+	}
+}
+
+func TestGenerateClient_CommonJS(t *testing.T) {
+	parsed := &parser.ParsedFile{Package: "main", Functions: []parser.GoFunction{}}
+
+	client := GenerateClient(parsed, "client.ts", "Wasm", Options{CommonJS: true, Diagnostics: true})
+	for _, want := range []string{
+		"\nclass WasmError extends Error {",
+		"\nfunction isWasmError(x: unknown)",
+		"\nclass Wasm {",
+		"export interface RuntimeStats {",
+		"}\n\nexport {};\nmodule.exports = { Wasm, WasmError, isWasmError };\n",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client missing %q", want)
+		}
+	}
+	if strings.Contains(client, "export class") || strings.Contains(client, "export function") {
+		t.Errorf("CommonJS client should not export values with ESM syntax:\n%s", client)
+	}
+}
+
+func TestGenerateClient_Deno(t *testing.T) {
+	parsed := &parser.ParsedFile{Package: "main", Functions: []parser.GoFunction{}}
+
+	tests := []struct {
+		name   string
+		client string
+		want   []string
+	}{
+		{
+			name:   "single worker",
+			client: GenerateClient(parsed, "client.ts", "Wasm", Options{Deno: true}),
+			want:   []string{"static async init(workerUrl: string | URL): Promise<Wasm> {", "new Worker(workerUrl, { type: 'module' })"},
+		},
+		{
+			name:   "pool",
+			client: GenerateClient(parsed, "client.ts", "Wasm", Options{Deno: true, WorkerPool: 2}),
+			want:   []string{"static async init(workerUrl: string | URL, size = 2): Promise<Wasm> {", "() => new Worker(workerUrl, { type: 'module' })"},
+		},
+		{
+			name:   "init module",
+			client: GenerateClientInit(parsed, "client-init.ts", "Wasm", "./client.ts", Options{Deno: true}),
+			want:   []string{"import('./client-init.ts')", "export async function init(workerUrl: string | URL): Promise<Wasm> {", "new Worker(workerUrl, { type: 'module' })"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(tt.client, want) {
+					t.Errorf("client missing %q:\n%s", want, tt.client)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateClient_WorkerPool(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Greet", Params: []parser.GoParameter{{Name: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}}, Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}}},
+		},
+	}
+
+	client := GenerateClient(parsed, "go-wasm.ts", "GoWasm", Options{WorkerPool: 4})
+	if strings.Contains(client, "private worker: Worker;") {
+		t.Error("pool client should not hold a single worker")
+	}
+	for _, want := range []string{
+		"private workers: Worker[];",
+		"static async init(workerUrl: string, size = 4): Promise<GoWasm> {",
+		"const workers = Array.from({ length: size }, () => new Worker(workerUrl));",
+		"await Promise.all(workers.map((worker) => new Promise<void>((resolve, reject) => {",
+		"instance.inFlight[handler.worker]--;",
+		"if (this.inFlight[i] < this.inFlight[worker]) worker = i;",
+		"reject(e);\n        },\n        worker,\n      });",
+		"this.workers[worker].postMessage({ id, fn, args });",
+		"this.inFlight[worker]--;\n        this.workers[worker].postMessage({ type: 'cancel', id });",
+		"this.workers.forEach((worker) => worker.terminate());",
+		"// Worker pool: every worker instantiates its own copy of the WASM module,",
+		`return this.call<string>("greet", [name], options?.signal)`,
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("pool client missing %q", want)
+		}
+	}
+}
+
+func TestGenerateClient_TransferBytes(t *testing.T) {
+	bytes := parser.GoType{Name: "[]byte", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "byte", Kind: parser.KindPrimitive}}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Blur", Params: []parser.GoParameter{{Name: "pixels", Type: bytes}}, Returns: []parser.GoType{bytes}},
+		},
+	}
+
+	for name, opts := range map[string]Options{
+		"single": {},
+		"pool":   {WorkerPool: 2},
+	} {
+		client := GenerateClient(parsed, "go-wasm.ts", "GoWasm", opts)
+		for _, want := range []string{
+			"private call<T>(fn: string, args: unknown[], signal?: AbortSignal, transfer: Uint8Array[] = []): Promise<T> {",
+			"const buffers = [...new Set(transfer.filter((a) => a.byteOffset === 0 && a.byteLength === a.buffer.byteLength).map((a) => a.buffer))].filter((buf): buf is ArrayBuffer => buf instanceof ArrayBuffer);",
+			".postMessage({ id, fn, args }, buffers);\n        } catch {\n",
+			// Falls back to copying, then drops the call if that fails too
+			".postMessage({ id, fn, args });\n        }\n      } catch (e) {\n        this.pending.delete(id);\n",
+			`return this.call<Uint8Array>("blur", [pixels], options?.signal, [pixels]);`,
+		} {
+			if !strings.Contains(client, want) {
+				t.Errorf("%s client missing %q:\n%s", name, want, client)
+			}
+		}
+	}
+
+	parsed.Functions[0].Params[0].Type.Base64 = true
+	if client := GenerateClient(parsed, "go-wasm.ts", "GoWasm", Options{}); strings.Contains(client, "transfer") {
+		t.Errorf("client should not transfer base64 strings:\n%s", client)
+	}
+}
+
+func TestGenerateClient_StreamBytes(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Blur", Returns: []parser.GoType{{Name: "[]byte", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "byte", Kind: parser.KindPrimitive}}}},
+		},
+	}
+
+	for name, opts := range map[string]Options{
+		"single": {StreamBytes: true},
+		"pool":   {StreamBytes: true, WorkerPool: 2},
+		"split":  {StreamBytes: true, SplitClient: true},
+	} {
+		client := GenerateClient(parsed, "go-wasm.ts", "GoWasm", opts)
+		for _, want := range []string{
+			"reject: (e: Error) => void",
+			"; chunks: Uint8Array[] }>();",
+			"chunks: [],\n      });",
+			"if (type === 'chunk') {",
+			".pending.get(id)?.chunks.push(result);",
+			"'__chunks' in result) {",
+			"const joined = new Uint8Array((result as { __chunks: number }).__chunks);",
+			"joined.set(chunk, offset);",
+			"handler.resolve(joined);",
+		} {
+			if !strings.Contains(client, want) {
+				t.Errorf("%s client missing %q:\n%s", name, want, client)
+			}
+		}
+	}
+
+	if client := GenerateClient(parsed, "go-wasm.ts", "GoWasm", Options{}); strings.Contains(client, "chunk") {
+		t.Errorf("client should only collect chunks with StreamBytes:\n%s", client)
+	}
+}
+
+func TestGenerateClient_SplitClient(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Greet", Params: []parser.GoParameter{{Name: "name", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}}}, Returns: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}}},
+		},
+	}
+
+	client := GenerateClient(parsed, "go-wasm.ts", "GoWasm", Options{SplitClient: true})
+	if strings.Contains(client, "static async init") {
+		t.Error("split client should not contain static init")
+	}
+	if strings.Contains(client, "new Worker(") {
+		t.Error("split client should not create the worker")
+	}
+	for _, want := range []string{
+		"  constructor(worker: Worker) {",
+		"handleMessage(data: any): void {",
+		"const callback = this.callbacks.get(callbackId);",
+		"const handler = this.pending.get(id);",
+		`return this.call<string>("greet", [name], options?.signal)`,
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("split client missing %q", want)
+		}
+	}
+
+	init := GenerateClientInit(parsed, "go-wasm-init.ts", "GoWasm", "./go-wasm", Options{})
+	for _, want := range []string{
+		"// go-wasm-init.ts - Generated by gowasm-bindgen",
+		"import { GoWasm } from './go-wasm';",
+		"export async function init(workerUrl: string): Promise<GoWasm> {",
+		"const instance = new GoWasm(worker);",
+		"instance.handleMessage(event.data);",
+	} {
+		if !strings.Contains(init, want) {
+			t.Errorf("init module missing %q", want)
+		}
+	}
+}
+
 func TestGenerateWorkerClassMethod(t *testing.T) {
 	tests := []struct {
 		name string
@@ -176,7 +499,7 @@ func TestGenerateWorkerClassMethod(t *testing.T) {
 					{Name: "string", Kind: parser.KindPrimitive},
 				},
 			},
-			want: "greet(name: string): Promise<string>",
+			want: "greet(name: string, options?: { signal?: AbortSignal }): Promise<string>",
 		},
 		{
 			name: "multiple params",
@@ -191,7 +514,7 @@ func TestGenerateWorkerClassMethod(t *testing.T) {
 					{Name: "int", Kind: parser.KindPrimitive},
 				},
 			},
-			want: "calculate(a: number, b: number, op: string): Promise<number>",
+			want: "calculate(a: number, b: number, op: string, options?: { signal?: AbortSignal }): Promise<number>",
 		},
 		{
 			name: "object return",
@@ -210,7 +533,7 @@ func TestGenerateWorkerClassMethod(t *testing.T) {
 					},
 				},
 			},
-			want: "getUser(id: number): Promise<GetUserResult>",
+			want: "getUser(id: number, options?: { signal?: AbortSignal }): Promise<GetUserResult>",
 		},
 		{
 			name: "callback parameter",
@@ -223,6 +546,31 @@ func TestGenerateWorkerClassMethod(t *testing.T) {
 			},
 			want: "registerCallback",
 		},
+		{
+			name: "optional progress callback",
+			fn: parser.GoFunction{
+				Name: "Crunch",
+				Params: []parser.GoParameter{
+					{Name: "n", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+					{Name: "onProgress", Type: parser.GoType{Kind: parser.KindFunction, CallbackParams: []parser.GoType{{Name: "int", Kind: parser.KindPrimitive}}, CallbackNames: []string{"percent"}}},
+				},
+				Returns: []parser.GoType{{Name: "int", Kind: parser.KindPrimitive}},
+			},
+			want: `crunch(n: number, onProgress?: (percent: number) => void, options?: { signal?: AbortSignal }): Promise<number> {
+    const onProgressId = onProgress ? this.registerCallback(onProgress as (...args: unknown[]) => void) : 0;`,
+		},
+		{
+			name: "callback before other parameters stays required",
+			fn: parser.GoFunction{
+				Name: "Each",
+				Params: []parser.GoParameter{
+					{Name: "cb", Type: parser.GoType{Kind: parser.KindFunction}},
+					{Name: "n", Type: parser.GoType{Name: "int", Kind: parser.KindPrimitive}},
+				},
+			},
+			want: `each(cb: () => void, n: number, options?: { signal?: AbortSignal }): Promise<void> {
+    const cbId = this.registerCallback(cb as (...args: unknown[]) => void);`,
+		},
 		{
 			name: "with documentation",
 			fn: parser.GoFunction{
@@ -242,7 +590,75 @@ func TestGenerateWorkerClassMethod(t *testing.T) {
 			fn: parser.GoFunction{
 				Name: "DoSomething",
 			},
-			want: "Promise<void>",
+			want: "doSomething(options?: { signal?: AbortSignal }): Promise<void>",
+		},
+		{
+			name: "signal passed to call",
+			fn: parser.GoFunction{
+				Name: "ForEach",
+				Params: []parser.GoParameter{
+					{Name: "cb", Type: parser.GoType{Kind: parser.KindFunction, CallbackParams: []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}}}},
+				},
+			},
+			want: `return this.call<void>("forEach", [cbId], options?.signal).finally(() => {`,
+		},
+		{
+			name: "options name clash",
+			fn: parser.GoFunction{
+				Name: "Configure",
+				Params: []parser.GoParameter{
+					{Name: "options", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+				},
+			},
+			want: `configure(options: string, _options?: { signal?: AbortSignal }): Promise<void> {
+    return this.call<void>("configure", [options], _options?.signal);`,
+		},
+		{
+			name: "byte slice transferred",
+			fn: parser.GoFunction{
+				Name: "Hash",
+				Params: []parser.GoParameter{
+					{Name: "data", Type: parser.GoType{Name: "[]byte", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "byte", Kind: parser.KindPrimitive}}},
+				},
+			},
+			want: `  /**
+   * Transferred to the worker, leaving the caller's array detached (empty): data
+   */
+  hash(data: Uint8Array, options?: { signal?: AbortSignal }): Promise<void> {
+    return this.call<void>("hash", [data], options?.signal, [data]);`,
+		},
+		{
+			name: "transfer note follows documentation",
+			fn: parser.GoFunction{
+				Name: "Hash",
+				Doc:  "Hashes data.",
+				Params: []parser.GoParameter{
+					{Name: "data", Type: parser.GoType{Name: "[]byte", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "byte", Kind: parser.KindPrimitive}}},
+				},
+			},
+			want: "   * Hashes data.\n   * @param data\n   *\n   * Transferred to the worker, leaving the caller's array detached (empty): data\n   */\n",
+		},
+		{
+			name: "variadic transfers without signal",
+			fn: parser.GoFunction{
+				Name: "Concat",
+				Params: []parser.GoParameter{
+					{Name: "data", Type: parser.GoType{Name: "[]byte", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "byte", Kind: parser.KindPrimitive}}},
+					{Name: "sep", IsVariadic: true, Type: parser.GoType{Name: "[]string", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "string", Kind: parser.KindPrimitive}}},
+				},
+			},
+			want: `return this.call<void>("concat", [data, ...sep], undefined, [data]);`,
+		},
+		{
+			name: "variadic takes no options",
+			fn: parser.GoFunction{
+				Name: "Sum",
+				Params: []parser.GoParameter{
+					{Name: "nums", IsVariadic: true, Type: parser.GoType{Name: "[]int", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "int", Kind: parser.KindPrimitive}}},
+				},
+			},
+			want: `sum(...nums: number[]): Promise<void> {
+    return this.call<void>("sum", [...nums]);`,
 		},
 	}
 
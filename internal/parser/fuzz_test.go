@@ -40,6 +40,8 @@ func FuzzParseSourceFile(f *testing.F) {
 		`package main; func Hash(data []byte) []byte { return data }`,
 		// Interface return
 		`package main; func Any() interface{} { return nil }`,
+		// The any alias
+		`package main; func Echo(v any, vs []any) map[string]any { return nil }`,
 		// Complex comments
 		"package main\n// Greet returns a greeting.\n// It takes a name parameter.\nfunc Greet(name string) string { return name }",
 		// Recursive struct
@@ -86,6 +88,8 @@ func FuzzHasSelectInMain(f *testing.F) {
 		`package main; func main() { if true { select {} } }`,
 		// Nested in for
 		`package main; func main() { for { select {} } }`,
+		// Blocking receive
+		`package main; func main() { <-make(chan struct{}) }`,
 		// In goroutine (shouldn't count)
 		`package main; func main() { go func() { select {} }() }`,
 		// Multiple selects
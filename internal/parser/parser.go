@@ -6,7 +6,12 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -14,24 +19,30 @@ import (
 // primitiveTypes is a lookup table for Go primitive types.
 // Defined at package level to avoid allocation on each isPrimitive call.
 var primitiveTypes = map[string]bool{
-	"string":     true,
-	"int":        true,
-	"int8":       true,
-	"int16":      true,
-	"int32":      true,
-	"int64":      true,
-	"uint":       true,
-	"uint8":      true,
-	"uint16":     true,
-	"uint32":     true,
-	"uint64":     true,
-	"float32":    true,
-	"float64":    true,
-	"bool":       true,
-	"byte":       true,
-	"rune":       true,
+	"string":  true,
+	"int":     true,
+	"int8":    true,
+	"int16":   true,
+	"int32":   true,
+	"int64":   true,
+	"uint":    true,
+	"uint8":   true,
+	"uint16":  true,
+	"uint32":  true,
+	"uint64":  true,
+	"float32": true,
+	"float64": true,
+	"bool":    true,
+	"byte":    true,
+	"rune":    true,
+}
+
+// unsupportedBuiltins are predeclared types with no JavaScript counterpart.
+// They resolve to KindUnsupported so validation rejects them by name.
+var unsupportedBuiltins = map[string]bool{
 	"complex64":  true,
 	"complex128": true,
+	"uintptr":    true,
 }
 
 // ParseSourceFile parses a Go source file and extracts exported functions and types
@@ -42,58 +53,585 @@ func ParseSourceFile(path string) (*ParsedFile, error) {
 		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
 	}
 
+	return parseFiles(fset, []*ast.File{file}), nil
+}
+
+// ParseSource parses Go source read from r, such as standard input. The
+// name is only used in error messages.
+func ParseSource(r io.Reader, name string) (*ParsedFile, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return parseFiles(fset, []*ast.File{file}), nil
+}
+
+// ParsePackageDir parses every non-test Go file in dir and merges their
+// exported functions and types into a single ParsedFile. Functions keep
+// their declaration order, with files visited in name order.
+func ParsePackageDir(dir string) (*ParsedFile, error) {
+	paths, err := PackageFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(paths))
+	packages := make(map[string]bool)
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files = append(files, file)
+		packages[file.Name.Name] = true
+	}
+	if len(packages) != 1 {
+		names := make([]string, 0, len(packages))
+		for name := range packages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%s must contain exactly one package, found: %s", dir, strings.Join(names, ", "))
+	}
+
+	return parseFiles(fset, files), nil
+}
+
+// PackageFiles returns the sorted paths of the Go files in dir that
+// ParsePackageDir considers: test files and generated bindings are skipped.
+// Bindings are recognized by their header, so a custom name is skipped too.
+func PackageFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && isPackageFile(entry.Name()) && !IsGeneratedBindings(path) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no Go source files in %s", dir)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// generatedHeader is the comment the generator puts at the top of the Go
+// bindings it writes.
+const generatedHeader = "// Code generated by gowasm-bindgen. DO NOT EDIT."
+
+// IsGeneratedBindings reports whether the Go file at path is bindings written
+// by gowasm-bindgen. Unreadable or unparseable files are not.
+func IsGeneratedBindings(path string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false
+	}
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if c.Text == generatedHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPackageFile reports whether a file name belongs to the package sources.
+func isPackageFile(name string) bool {
+	return strings.HasSuffix(name, ".go") &&
+		!strings.HasSuffix(name, "_test.go") &&
+		name != "bindings_gen.go"
+}
+
+// parseFiles extracts exported functions and types from already-parsed files
+// of one package. Types are collected from every file before any function is
+// resolved so signatures may reference types declared in sibling files. fset
+// is the file set the files were parsed with, for declaration positions.
+func parseFiles(fset *token.FileSet, files []*ast.File) *ParsedFile {
 	result := &ParsedFile{
-		Package:   file.Name.Name,
+		Package:   files[0].Name.Name,
 		Functions: []GoFunction{},
 		Types:     make(map[string]*GoType),
+		Methods:   make(map[string][]GoFunction),
 	}
 
-	// First pass: collect all type definitions
-	for _, decl := range file.Decls {
-		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
-			for _, spec := range genDecl.Specs {
-				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-					if isExported(typeSpec.Name.Name) {
-						goType := resolveType(typeSpec.Type, result.Types)
-						goType.Name = typeSpec.Name.Name
-						result.Types[typeSpec.Name.Name] = &goType
+	// First pass: collect all type definitions. Each is resolved on first
+	// reference, so types may use ones declared later or refer to themselves.
+	r := &typeResolver{
+		types:     result.Types,
+		specs:     make(map[string]*ast.TypeSpec),
+		aliases:   make(map[string]GoType),
+		resolving: make(map[string]bool),
+		recursive: make(map[string]bool),
+	}
+	var names []string
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
+				for _, spec := range genDecl.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						if isExported(typeSpec.Name.Name) {
+							r.specs[typeSpec.Name.Name] = typeSpec
+							names = append(names, typeSpec.Name.Name)
+						}
 					}
 				}
 			}
 		}
 	}
+	for _, name := range names {
+		_, defined := result.Types[name]
+		_, aliased := r.aliases[name]
+		if !defined && !aliased {
+			r.define(name)
+		}
+	}
 
-	// Second pass: collect exported functions
-	for _, decl := range file.Decls {
-		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
-			// Only exported functions (no methods)
-			if funcDecl.Recv == nil && isExported(funcDecl.Name.Name) {
-				fn := extractFunction(funcDecl, result.Types)
+	// Collect constants declared with named primitive types as enum values
+	collectEnumValues(files, result.Types)
+
+	// Second pass: collect exported functions, and exported methods by
+	// receiver type in case they are bound with UseMethodsOf. Functions
+	// marked //gowasm:ignore are left out.
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || !isExported(funcDecl.Name.Name) || hasIgnoreDirective(funcDecl.Doc) {
+				continue
+			}
+			recv := ""
+			if funcDecl.Recv != nil {
+				if recv = receiverType(funcDecl.Recv); recv == "" {
+					continue
+				}
+			}
+			fn := extractFunction(funcDecl, r)
+			pos := fset.Position(funcDecl.Name.Pos())
+			fn.Pos = fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+			if recv != "" {
+				result.Methods[recv] = append(result.Methods[recv], fn)
+			} else {
 				result.Functions = append(result.Functions, fn)
 			}
 		}
 	}
 
-	return result, nil
+	return result
+}
+
+// ignoreDirective excludes the function whose doc comment contains it from
+// the bindings, e.g. an exported test helper.
+const ignoreDirective = "//gowasm:ignore"
+
+// nameDirectivePrefix starts a directive that sets the JavaScript name of a
+// function, e.g. "//gowasm:name httpGet" for HTTPGet.
+const nameDirectivePrefix = "//gowasm:name "
+
+// hasIgnoreDirective reports whether doc contains ignoreDirective on a line
+// of its own. Like other Go directives, it has no space after the slashes.
+func hasIgnoreDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if strings.TrimSpace(comment.Text) == ignoreDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// nameDirective returns the JavaScript name set with a //gowasm:name
+// directive in doc, or "" if there is none. The name is checked by the
+// validator.
+func nameDirective(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, comment := range doc.List {
+		if name, ok := strings.CutPrefix(comment.Text, nameDirectivePrefix); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
+
+// receiverType returns the type name of a method receiver, T or *T, or ""
+// for receivers of generic types.
+func receiverType(recv *ast.FieldList) string {
+	if len(recv.List) != 1 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// UseMethodsOf binds the exported methods of typeName as if they were
+// functions. The generated bindings call them on a package-level instance
+// of the type, so state kept in its fields lasts across calls.
+func UseMethodsOf(parsed *ParsedFile, typeName string) error {
+	methods := parsed.Methods[typeName]
+	if len(methods) == 0 {
+		return fmt.Errorf("type %s has no exported methods", typeName)
+	}
+	for _, fn := range methods {
+		fn.Receiver = typeName
+		parsed.Functions = append(parsed.Functions, fn)
+	}
+	return nil
+}
+
+// UseBigInt marks every int64 and uint64 value in parsed, including named
+// types over them, to cross the JS boundary as bigint so values above 2^53
+// keep their precision. Map keys are left as is since JS object keys are
+// strings.
+func UseBigInt(parsed *ParsedFile) {
+	markTypes(parsed, func(t *GoType) {
+		if t.Kind == KindPrimitive {
+			name := primitiveName(*t)
+			t.BigInt = name == "int64" || name == "uint64"
+		}
+	})
+}
+
+// UseBase64Bytes marks every []byte value in parsed to cross the JS boundary
+// as a base64 string instead of a Uint8Array, for callers that pass results
+// straight on to JSON.
+func UseBase64Bytes(parsed *ParsedFile) {
+	markTypes(parsed, func(t *GoType) {
+		t.Base64 = IsByteSlice(*t)
+	})
+}
+
+// UsePlainArrays marks every numeric slice and array in parsed that would
+// be a typed array, such as []float64 or []int32, to cross the JS boundary
+// as a plain number[] instead, for libraries that expect arrays. []byte is
+// left to UseBase64Bytes.
+func UsePlainArrays(parsed *ParsedFile) {
+	markTypes(parsed, func(t *GoType) {
+		t.PlainArray = (t.Kind == KindSlice || t.Kind == KindArray) && !IsByteSlice(*t) &&
+			t.Elem != nil && t.Elem.Kind == KindPrimitive && goElemToTypedArray(primitiveName(*t.Elem)) != ""
+	})
+}
+
+// UseTypedArrayBytes marks the numeric slice parameters of every function in
+// parsed that would take a typed array, such as []int32 or []float64, to be
+// decoded from the bytes of any typed array or DataView in byteOrder,
+// "little" or "big", instead of element by element. Data from a source with
+// a fixed byte order, such as a file format or network protocol, then
+// needn't be swapped in JS first. Results are unaffected.
+func UseTypedArrayBytes(parsed *ParsedFile, byteOrder string) {
+	for i := range parsed.Functions {
+		for j := range parsed.Functions[i].Params {
+			p := &parsed.Functions[i].Params[j]
+			if p.Type.Kind == KindSlice && !p.IsVariadic && !IsByteSlice(p.Type) && !p.Type.PlainArray &&
+				p.Type.Elem != nil && p.Type.Elem.Kind == KindPrimitive && goElemToTypedArray(primitiveName(*p.Type.Elem)) != "" {
+				p.Type.ByteOrder = byteOrder
+			}
+		}
+	}
+}
+
+// UseStructHelpers marks every named struct in parsed to be converted through
+// generated helper functions, one pair per type, instead of inline code.
+func UseStructHelpers(parsed *ParsedFile) {
+	markTypes(parsed, func(t *GoType) {
+		t.Helpers = t.Kind == KindStruct && t.Named
+	})
+}
+
+// UseJSONStructs marks every struct in parsed to be converted with
+// encoding/json, honoring its tags, methods, and every field type it can
+// encode, instead of field by field. Types nested in structs are marked too
+// and take on the TypeScript types of their JSON form: []byte is a base64
+// string, other numeric slices are number[], time.Time is a string, and
+// 64-bit integers are numbers.
+func UseJSONStructs(parsed *ParsedFile) {
+	markTypes(parsed, func(t *GoType) {
+		if t.Kind != KindStruct {
+			return
+		}
+		walkType(t, func(nested *GoType) {
+			nested.JSON = true
+			nested.BigInt = false
+			nested.Base64 = IsByteSlice(*nested)
+			nested.PlainArray = (nested.Kind == KindSlice || nested.Kind == KindArray) && !nested.Base64
+			nested.Nullable = nested.Kind == KindSlice || nested.Kind == KindMap
+		})
+	})
+}
+
+// UseNilAsNull marks every slice and map in parsed to cross the JS boundary
+// as null when nil, so callers can tell no result from an empty one. Null
+// and undefined arguments become nil.
+func UseNilAsNull(parsed *ParsedFile) {
+	markTypes(parsed, func(t *GoType) {
+		t.Nullable = t.Kind == KindSlice || t.Kind == KindMap
+	})
+}
+
+// UseResultUnion marks every function in parsed whose last result is an
+// error to return a discriminated { ok: true, value } or { ok: false, error }
+// object to JS instead of rejecting.
+func UseResultUnion(parsed *ParsedFile) {
+	for i := range parsed.Functions {
+		fn := &parsed.Functions[i]
+		fn.ResultUnion = len(fn.Returns) > 0 && fn.Returns[len(fn.Returns)-1].IsError
+	}
+}
+
+// UseReadonlyReturns marks every function in parsed to declare its result
+// with readonly TypeScript types (see GoTypeToReadonlyTS), since callers
+// should not mutate values returned from WASM.
+func UseReadonlyReturns(parsed *ParsedFile) {
+	for i := range parsed.Functions {
+		parsed.Functions[i].ReadonlyReturn = true
+	}
+}
+
+// markTypes calls mark on every type in parsed's type table and function
+// signatures, and on each type nested within them except map keys.
+func markTypes(parsed *ParsedFile, mark func(*GoType)) {
+	for _, t := range parsed.Types {
+		walkType(t, mark)
+	}
+	for i := range parsed.Functions {
+		fn := &parsed.Functions[i]
+		for j := range fn.Params {
+			walkType(&fn.Params[j].Type, mark)
+		}
+		for j := range fn.Returns {
+			walkType(&fn.Returns[j], mark)
+		}
+	}
+}
+
+// walkType calls mark on t and then on the types nested within it.
+func walkType(t *GoType, mark func(*GoType)) {
+	mark(t)
+	switch t.Kind {
+	case KindSlice, KindArray, KindPointer:
+		if t.Elem != nil {
+			walkType(t.Elem, mark)
+		}
+	case KindMap:
+		if t.Value != nil {
+			walkType(t.Value, mark)
+		}
+	case KindStruct:
+		for i := range t.Fields {
+			walkType(&t.Fields[i].Type, mark)
+		}
+	case KindFunction:
+		for i := range t.CallbackParams {
+			walkType(&t.CallbackParams[i], mark)
+		}
+		for i := range t.CallbackResults {
+			walkType(&t.CallbackResults[i], mark)
+		}
+	}
+}
+
+// collectEnumValues records the constants declared with each named primitive
+// type. Constants without an explicit type inherit the type of the previous
+// spec in the block when they also omit values (the iota repetition form).
+// String types whose constants are all literals also get EnumLiterals.
+// Types whose constants are all string literals or plain iota counters are
+// marked EnumClosed.
+func collectEnumValues(files []*ast.File, types map[string]*GoType) {
+	nonLiteral := make(map[*GoType]bool)
+	open := make(map[*GoType]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+
+			var typeName string
+			var values []ast.Expr // repeated by specs that omit their values
+			for _, spec := range genDecl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				if valueSpec.Type != nil {
+					typeName = ""
+					if ident, ok := valueSpec.Type.(*ast.Ident); ok {
+						typeName = ident.Name
+					}
+				} else if len(valueSpec.Values) > 0 {
+					typeName = conversionType(valueSpec.Values[0])
+				}
+				if len(valueSpec.Values) > 0 {
+					values = valueSpec.Values
+				}
+
+				goType, ok := types[typeName]
+				if !ok || goType.Kind != KindPrimitive || goType.Underlying == "" {
+					continue
+				}
+				for i, name := range valueSpec.Names {
+					if name.Name == "_" {
+						continue
+					}
+					goType.EnumValues = append(goType.EnumValues, name.Name)
+					var value ast.Expr
+					if i < len(values) {
+						value = values[i]
+					}
+					if goType.Underlying != "string" {
+						if !isIotaCounter(value) {
+							open[goType] = true
+						}
+						continue
+					}
+					if literal, ok := stringLiteral(value); ok {
+						goType.EnumLiterals = append(goType.EnumLiterals, literal)
+					} else {
+						nonLiteral[goType] = true
+					}
+				}
+			}
+		}
+	}
+
+	for goType := range nonLiteral {
+		goType.EnumLiterals = nil
+	}
+	for _, goType := range types {
+		if len(goType.EnumValues) == 0 || open[goType] || nonLiteral[goType] {
+			continue
+		}
+		goType.EnumClosed = goType.Underlying == "string" || isIntegerPrimitive(goType.Underlying)
+	}
+}
+
+// isIotaCounter reports whether expr is a constant expression counting with
+// iota, such as iota or iota + 1. Bit flags (1 << iota) are not counters:
+// their OR-ed combinations are valid values too.
+func isIotaCounter(expr ast.Expr) bool {
+	if expr == nil {
+		return false
+	}
+	usesIota, flags := false, false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			usesIota = usesIota || n.Name == "iota"
+		case *ast.BinaryExpr:
+			switch n.Op {
+			case token.SHL, token.SHR, token.OR, token.XOR, token.AND, token.AND_NOT:
+				flags = true
+			}
+		}
+		return true
+	})
+	return usesIota && !flags
+}
+
+// isIntegerPrimitive reports whether name is a builtin integer type.
+func isIntegerPrimitive(name string) bool {
+	switch name {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return true
+	}
+	return false
+}
+
+// stringLiteral returns the value of a string literal constant expression,
+// either "x" or a conversion such as Status("x").
+func stringLiteral(expr ast.Expr) (string, bool) {
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 1 && conversionType(call) != "" {
+		expr = call.Args[0]
+	}
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// isContextType reports whether expr is context.Context.
+func isContextType(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "context" && sel.Sel.Name == "Context"
+}
+
+// conversionType returns the type name of a conversion such as Color(1),
+// or "" if the expression is not a conversion to a named type.
+func conversionType(expr ast.Expr) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return ""
+	}
+	if ident, ok := call.Fun.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
 }
 
 // extractFunction extracts function signature from AST
-func extractFunction(fn *ast.FuncDecl, types map[string]*GoType) GoFunction {
+func extractFunction(fn *ast.FuncDecl, r *typeResolver) GoFunction {
 	function := GoFunction{
 		Name:    fn.Name.Name,
 		Params:  []GoParameter{},
 		Returns: []GoType{},
 		Doc:     extractDocComment(fn.Doc),
+		JSName:  nameDirective(fn.Doc),
 	}
 
 	// Extract parameters
 	if fn.Type.Params != nil {
-		for _, field := range fn.Type.Params.List {
-			paramType := resolveType(field.Type, types)
+		for i, field := range fn.Type.Params.List {
+			// A leading context.Context is supplied by the bindings, not JS
+			if i == 0 && len(field.Names) <= 1 && isContextType(field.Type) {
+				function.Context = true
+				continue
+			}
+			// A variadic ...T parameter arrives in the function body as []T
+			typeExpr := field.Type
+			ellipsis, variadic := typeExpr.(*ast.Ellipsis)
+			if variadic {
+				typeExpr = &ast.ArrayType{Elt: ellipsis.Elt}
+			}
+			paramType := r.resolve(typeExpr)
 			for _, name := range field.Names {
 				function.Params = append(function.Params, GoParameter{
-					Name: name.Name,
-					Type: paramType,
+					Name:       name.Name,
+					Type:       paramType,
+					IsVariadic: variadic,
 				})
 			}
 		}
@@ -102,7 +640,7 @@ func extractFunction(fn *ast.FuncDecl, types map[string]*GoType) GoFunction {
 	// Extract return types
 	if fn.Type.Results != nil {
 		for _, field := range fn.Type.Results.List {
-			returnType := resolveType(field.Type, types)
+			returnType := r.resolve(field.Type)
 			function.Returns = append(function.Returns, returnType)
 		}
 	}
@@ -110,14 +648,41 @@ func extractFunction(fn *ast.FuncDecl, types map[string]*GoType) GoFunction {
 	return function
 }
 
-// resolveType converts an AST type expression to GoType
-func resolveType(expr ast.Expr, types map[string]*GoType) GoType {
-	return resolveTypeWithVisited(expr, types, make(map[string]bool))
+// typeResolver converts AST type expressions to GoTypes, resolving the
+// package's type declarations on demand.
+type typeResolver struct {
+	types     map[string]*GoType       // resolved type declarations
+	specs     map[string]*ast.TypeSpec // exported type declarations by name
+	aliases   map[string]GoType        // resolved alias declarations (type A = B)
+	resolving map[string]bool          // declarations currently being resolved
+	recursive map[string]bool          // structs that refer back to themselves
+}
+
+// define resolves the declaration of the named type and records it in types.
+// An alias is recorded in aliases instead: it is the same type as its target,
+// so it resolves to the target and gets no declaration of its own.
+func (r *typeResolver) define(name string) {
+	r.resolving[name] = true
+	goType := r.resolve(r.specs[name].Type)
+	delete(r.resolving, name)
+
+	if r.specs[name].Assign.IsValid() {
+		r.aliases[name] = goType
+		return
+	}
+
+	if goType.Kind == KindPrimitive && goType.Underlying == "" && IsPrimitive(goType.Name) {
+		// Named primitive type (e.g., type Score int32)
+		goType.Underlying = goType.Name
+	}
+	goType.Name = name
+	goType.Named = goType.Kind == KindStruct
+	goType.Recursive = r.recursive[name]
+	r.types[name] = &goType
 }
 
-// resolveTypeWithVisited is the internal implementation that tracks visited types
-// to detect cycles in type definitions (e.g., type A B; type B A).
-func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map[string]bool) GoType {
+// resolve converts an AST type expression to GoType.
+func (r *typeResolver) resolve(expr ast.Expr) GoType {
 	switch t := expr.(type) {
 	case *ast.Ident:
 		// Check for error type
@@ -129,26 +694,58 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 			}
 		}
 
+		if unsupportedBuiltins[t.Name] {
+			return GoType{
+				Name: t.Name,
+				Kind: KindUnsupported,
+			}
+		}
+
 		// Check for known primitives
-		if isPrimitive(t.Name) {
+		if IsPrimitive(t.Name) {
 			return GoType{
 				Name: t.Name,
 				Kind: KindPrimitive,
 			}
 		}
 
-		// Detect cycles in type references
-		if visited[t.Name] {
+		// Check if this is a defined type in the file
+		if knownType, ok := r.types[t.Name]; ok {
+			return *knownType
+		}
+		if target, ok := r.aliases[t.Name]; ok {
+			return target
+		}
+
+		if spec, ok := r.specs[t.Name]; ok {
+			if !r.resolving[t.Name] {
+				r.define(t.Name)
+				return r.resolve(t)
+			}
+			// A reference back to a declaration still being resolved. Structs
+			// are referenced by name and converted through helper functions;
+			// other cycles (e.g., type List []List) can't be represented.
+			if _, ok := spec.Type.(*ast.StructType); ok {
+				r.recursive[t.Name] = true
+				return GoType{
+					Name:      t.Name,
+					Kind:      KindStruct,
+					Named:     true,
+					Recursive: true,
+				}
+			}
 			return GoType{
 				Name: t.Name,
 				Kind: KindUnsupported,
 			}
 		}
 
-		// Check if this is a defined type in the file
-		if knownType, ok := types[t.Name]; ok {
-			visited[t.Name] = true
-			return *knownType
+		// The builtin alias, resolved exactly like the type it stands for
+		if t.Name == "any" {
+			return GoType{
+				Name: "interface{}",
+				Kind: KindAny,
+			}
 		}
 
 		// Unknown type, treat as primitive
@@ -158,7 +755,7 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 		}
 
 	case *ast.ArrayType:
-		elemType := resolveTypeWithVisited(t.Elt, types, visited)
+		elemType := r.resolve(t.Elt)
 		if t.Len == nil {
 			// Slice
 			return GoType{
@@ -175,8 +772,8 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 		}
 
 	case *ast.MapType:
-		keyType := resolveTypeWithVisited(t.Key, types, visited)
-		valueType := resolveTypeWithVisited(t.Value, types, visited)
+		keyType := r.resolve(t.Key)
+		valueType := r.resolve(t.Value)
 		return GoType{
 			Name:  fmt.Sprintf("map[%s]%s", keyType.Name, valueType.Name),
 			Kind:  KindMap,
@@ -185,7 +782,7 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 		}
 
 	case *ast.StarExpr:
-		elemType := resolveTypeWithVisited(t.X, types, visited)
+		elemType := r.resolve(t.X)
 		return GoType{
 			Name: "*" + elemType.Name,
 			Kind: KindPointer,
@@ -201,21 +798,32 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 
 		if t.Fields != nil {
 			for _, field := range t.Fields.List {
-				fieldType := resolveTypeWithVisited(field.Type, types, visited)
-				jsonTag := extractJSONTag(field.Tag)
+				fieldType := r.resolve(field.Type)
+				jsonTag, jsonOpts := extractJSONTag(field.Tag)
+				skip := isJSONSkipped(field.Tag)
+				doc := extractDocComment(field.Doc)
+				if doc == "" {
+					doc = extractDocComment(field.Comment)
+				}
 
 				if len(field.Names) == 0 {
 					// Anonymous/embedded field - add with empty name for validator to catch
 					structType.Fields = append(structType.Fields, GoField{
 						Name: "",
 						Type: fieldType,
+						Skip: skip,
 					})
 				} else {
 					for _, name := range field.Names {
 						structType.Fields = append(structType.Fields, GoField{
-							Name:    name.Name,
-							Type:    fieldType,
-							JSONTag: jsonTag,
+							Name:       name.Name,
+							Type:       fieldType,
+							JSONTag:    jsonTag,
+							TSName:     extractTSTag(field.Tag),
+							JSONString: hasTagOption(jsonOpts, "string"),
+							OmitEmpty:  hasTagOption(jsonOpts, "omitempty"),
+							Skip:       skip,
+							Doc:        doc,
 						})
 					}
 				}
@@ -227,6 +835,12 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 	case *ast.SelectorExpr:
 		// Handle qualified identifiers (e.g., time.Time, sql.NullString)
 		if x, ok := t.X.(*ast.Ident); ok {
+			if x.Name == "time" && t.Sel.Name == "Time" {
+				return GoType{
+					Name: "time.Time",
+					Kind: KindTime,
+				}
+			}
 			return GoType{
 				Name: x.Name + "." + t.Sel.Name,
 				Kind: KindUnsupported,
@@ -240,28 +854,44 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 	case *ast.FuncType:
 		// Parse callback parameters
 		var params []GoType
+		var names []string
 		if t.Params != nil {
 			for _, field := range t.Params.List {
-				paramType := resolveTypeWithVisited(field.Type, types, visited)
+				paramType := r.resolve(field.Type)
 				// Functions can have unnamed params like func(string, int)
 				if len(field.Names) == 0 {
 					params = append(params, paramType)
 				} else {
-					for range field.Names {
+					for _, name := range field.Names {
 						params = append(params, paramType)
+						names = append(names, name.Name)
 					}
 				}
 			}
 		}
 
-		// Check for return values
-		hasReturns := t.Results != nil && len(t.Results.List) > 0
+		// Parse callback return values
+		var results []GoType
+		if t.Results != nil {
+			for _, field := range t.Results.List {
+				resultType := r.resolve(field.Type)
+				count := len(field.Names)
+				if count == 0 {
+					count = 1
+				}
+				for i := 0; i < count; i++ {
+					results = append(results, resultType)
+				}
+			}
+		}
 
 		return GoType{
-			Name:           "func",
-			Kind:           KindFunction,
-			CallbackParams: params,
-			IsVoid:         !hasReturns,
+			Name:            "func",
+			Kind:            KindFunction,
+			CallbackParams:  params,
+			CallbackNames:   names,
+			CallbackResults: results,
+			IsVoid:          len(results) == 0,
 		}
 
 	case *ast.ChanType:
@@ -271,6 +901,12 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 		}
 
 	case *ast.InterfaceType:
+		if t.Methods == nil || len(t.Methods.List) == 0 {
+			return GoType{
+				Name: "interface{}",
+				Kind: KindAny,
+			}
+		}
 		return GoType{
 			Name: "interface",
 			Kind: KindUnsupported,
@@ -284,10 +920,11 @@ func resolveTypeWithVisited(expr ast.Expr, types map[string]*GoType, visited map
 	}
 }
 
-// extractJSONTag extracts the JSON tag value from a field tag
-func extractJSONTag(tag *ast.BasicLit) string {
+// extractJSONTag extracts the JSON tag name and its comma-separated options
+// (e.g., "omitempty,string") from a field tag
+func extractJSONTag(tag *ast.BasicLit) (name, options string) {
 	if tag == nil {
-		return ""
+		return "", ""
 	}
 
 	// Parse tag string (remove backticks)
@@ -295,12 +932,39 @@ func extractJSONTag(tag *ast.BasicLit) string {
 	tags := reflect.StructTag(tagStr)
 	jsonTag := tags.Get("json")
 
-	// Extract just the name, ignore options like omitempty
 	if idx := strings.Index(jsonTag, ","); idx != -1 {
-		return jsonTag[:idx]
+		return jsonTag[:idx], jsonTag[idx+1:]
+	}
+
+	return jsonTag, ""
+}
+
+// extractTSTag extracts the ts tag from a field tag, which renames the field
+// in the generated TypeScript without changing its JSON key
+func extractTSTag(tag *ast.BasicLit) string {
+	if tag == nil {
+		return ""
+	}
+	return reflect.StructTag(strings.Trim(tag.Value, "`")).Get("ts")
+}
+
+// isJSONSkipped reports whether a field tag is json:"-", which encoding/json
+// skips. json:"-," instead names the key "-".
+func isJSONSkipped(tag *ast.BasicLit) bool {
+	if tag == nil {
+		return false
 	}
+	return reflect.StructTag(strings.Trim(tag.Value, "`")).Get("json") == "-"
+}
 
-	return jsonTag
+// hasTagOption reports whether a comma-separated tag option list contains option
+func hasTagOption(options, option string) bool {
+	for _, opt := range strings.Split(options, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
 }
 
 // extractDocComment extracts documentation from comment group
@@ -312,6 +976,10 @@ func extractDocComment(doc *ast.CommentGroup) string {
 	var lines []string
 	for _, comment := range doc.List {
 		text := comment.Text
+		// gowasm directives configure the bindings and aren't documentation
+		if strings.HasPrefix(text, "//gowasm:") {
+			continue
+		}
 		text = strings.TrimPrefix(text, "//")
 		text = strings.TrimPrefix(text, "/*")
 		text = strings.TrimSuffix(text, "*/")
@@ -332,12 +1000,13 @@ func isExported(name string) bool {
 	return unicode.IsUpper(rune(name[0]))
 }
 
-// isPrimitive checks if a type name is a Go primitive
-func isPrimitive(name string) bool {
+// IsPrimitive checks if a type name is a Go primitive
+func IsPrimitive(name string) bool {
 	return primitiveTypes[name]
 }
 
-// HasSelectInMain checks if a Go source file has a main function containing select {}.
+// HasSelectInMain checks if a Go source file has a main function that blocks
+// forever with select {} or a channel receive such as <-make(chan struct{}).
 // This is required for WASM modules to stay alive and receive JavaScript calls.
 func HasSelectInMain(path string) (bool, error) {
 	fset := token.NewFileSet()
@@ -345,7 +1014,29 @@ func HasSelectInMain(path string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to parse %s: %w", path, err)
 	}
+	return hasSelectInMain(file), nil
+}
 
+// HasSelectInMainSource is HasSelectInMain for source read from r. The name
+// is only used in error messages.
+func HasSelectInMainSource(r io.Reader, name string) (bool, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return hasSelectInMain(file), nil
+}
+
+// hasSelectInMain reports whether the file's main function contains an
+// empty select statement or a receive statement (<-ch). Function literals
+// are skipped, since blocking in a goroutine doesn't keep main running.
+func hasSelectInMain(file *ast.File) bool {
 	// Find main function
 	var mainFunc *ast.FuncDecl
 	for _, decl := range file.Decls {
@@ -359,24 +1050,40 @@ func HasSelectInMain(path string) (bool, error) {
 		}
 	}
 	if mainFunc == nil {
-		return false, nil
+		return false
 	}
 
 	// Function declaration without body (e.g., "func main()" with no braces)
 	if mainFunc.Body == nil {
-		return false, nil
+		return false
 	}
 
-	// Use ast.Inspect to find empty select statements
+	// Use ast.Inspect to find empty select statements and receives
 	found := false
-	ast.Inspect(mainFunc.Body, func(n ast.Node) bool {
-		if sel, ok := n.(*ast.SelectStmt); ok {
-			if sel.Body == nil || len(sel.Body.List) == 0 {
+	var visit func(ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CommClause:
+			// A case receive belongs to its select, which only counts when empty
+			for _, stmt := range n.Body {
+				ast.Inspect(stmt, visit)
+			}
+			return false
+		case *ast.SelectStmt:
+			if n.Body == nil || len(n.Body.List) == 0 {
 				found = true
 				return false // stop inspection
 			}
+		case *ast.ExprStmt:
+			if recv, ok := n.X.(*ast.UnaryExpr); ok && recv.Op == token.ARROW {
+				found = true
+				return false
+			}
 		}
 		return true // continue
-	})
-	return found, nil
+	}
+	ast.Inspect(mainFunc.Body, visit)
+	return found
 }
@@ -3,6 +3,7 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -152,6 +153,45 @@ func MultiReturn(x int) (string, error) {
 	}
 }
 
+func TestParseSourceFile_Variadic(t *testing.T) {
+	src := `package main
+
+func Join(sep string, parts ...string) string { return "" }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "variadic.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+
+	params := parsed.Functions[0].Params
+	if params[0].IsVariadic {
+		t.Error("sep should not be variadic")
+	}
+	parts := params[1]
+	if !parts.IsVariadic {
+		t.Error("parts should be variadic")
+	}
+	if parts.Type.Kind != KindSlice || parts.Type.Elem == nil || parts.Type.Elem.Name != "string" {
+		t.Errorf("parts type = %+v, want []string", parts.Type)
+	}
+	if got := GoParamToTS(parts); got != "...parts: string[]" {
+		t.Errorf("GoParamToTS(parts) = %q, want %q", got, "...parts: string[]")
+	}
+
+	// Rest parameters can't be typed arrays
+	data := GoParameter{Name: "data", IsVariadic: true, Type: GoType{Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}}}
+	if got := GoParamToTS(data); got != "...data: number[]" {
+		t.Errorf("GoParamToTS(data) = %q, want %q", got, "...data: number[]")
+	}
+}
+
 func TestParseSourceFile_ErrorReturn(t *testing.T) {
 	src := `package main
 
@@ -195,7 +235,11 @@ func TestParseSourceFile_JSONTags(t *testing.T) {
 type Data struct {
 	FirstName string ` + "`json:\"first_name\"`" + `
 	LastName  string ` + "`json:\"last_name,omitempty\"`" + `
+	Count     int    ` + "`json:\"count,string\"`" + `
 	NoTag     int
+	Secret    string ` + "`json:\"-\"`" + `
+	Dash      string ` + "`json:\"-,\"`" + `
+	Renamed   string ` + "`json:\"renamed_field\" ts:\"renamedField\"`" + `
 }
 
 func GetData() Data {
@@ -218,11 +262,18 @@ func GetData() Data {
 	if !ok {
 		t.Fatal("expected Data type")
 	}
+	if !dataType.Named || !parsed.Functions[0].Returns[0].Named {
+		t.Error("expected Data to be marked as a named struct")
+	}
 
 	expectedTags := map[string]string{
 		"FirstName": "first_name",
 		"LastName":  "last_name",
+		"Count":     "count",
 		"NoTag":     "",
+		"Secret":    "-",
+		"Dash":      "-",
+		"Renamed":   "renamed_field",
 	}
 
 	for _, field := range dataType.Fields {
@@ -230,6 +281,22 @@ func GetData() Data {
 		if field.JSONTag != want {
 			t.Errorf("field %s: JSONTag = %q, want %q", field.Name, field.JSONTag, want)
 		}
+		if wantString := field.Name == "Count"; field.JSONString != wantString {
+			t.Errorf("field %s: JSONString = %v, want %v", field.Name, field.JSONString, wantString)
+		}
+		if wantOmit := field.Name == "LastName"; field.OmitEmpty != wantOmit {
+			t.Errorf("field %s: OmitEmpty = %v, want %v", field.Name, field.OmitEmpty, wantOmit)
+		}
+		if wantSkip := field.Name == "Secret"; field.Skip != wantSkip {
+			t.Errorf("field %s: Skip = %v, want %v", field.Name, field.Skip, wantSkip)
+		}
+		wantTS := ""
+		if field.Name == "Renamed" {
+			wantTS = "renamedField"
+		}
+		if field.TSName != wantTS {
+			t.Errorf("field %s: TSName = %q, want %q", field.Name, field.TSName, wantTS)
+		}
 	}
 }
 
@@ -330,6 +397,10 @@ func OnComplete(callback func()) {
 // WithNamedParams has named callback params
 func WithNamedParams(callback func(item string, index int)) {
 }
+
+// Filter takes a predicate callback
+func Filter(items []string, keep func(string) bool) {
+}
 `
 
 	tmpDir := t.TempDir()
@@ -386,6 +457,17 @@ func WithNamedParams(callback func(item string, index int)) {
 	if len(cbParam.Type.CallbackParams) != 2 {
 		t.Errorf("WithNamedParams callback: got %d params, want 2", len(cbParam.Type.CallbackParams))
 	}
+	if got := strings.Join(cbParam.Type.CallbackNames, ","); got != "item,index" {
+		t.Errorf("WithNamedParams callback names: got %q, want %q", got, "item,index")
+	}
+	// Check Filter predicate callback result
+	keep := funcMap["Filter"].Params[1].Type
+	if keep.IsVoid || len(keep.CallbackResults) != 1 || keep.CallbackResults[0].Name != "bool" {
+		t.Errorf("Filter callback: got IsVoid=%v results=%v, want one bool result", keep.IsVoid, keep.CallbackResults)
+	}
+	if len(forEachFn.Params[1].Type.CallbackNames) != 0 {
+		t.Errorf("ForEach callback names: got %v, want none", forEachFn.Params[1].Type.CallbackNames)
+	}
 }
 
 func TestIsByteSlice(t *testing.T) {
@@ -405,9 +487,9 @@ func TestIsByteSlice(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isByteSlice(tt.goType)
+			result := IsByteSlice(tt.goType)
 			if result != tt.expected {
-				t.Errorf("isByteSlice(%+v) = %v, want %v", tt.goType, result, tt.expected)
+				t.Errorf("IsByteSlice(%+v) = %v, want %v", tt.goType, result, tt.expected)
 			}
 		})
 	}
@@ -427,6 +509,9 @@ func TestGoTypeToTS(t *testing.T) {
 		// Typed arrays
 		{"byte slice", GoType{Name: "[]byte", Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}}, "Uint8Array"},
 		{"uint8 slice", GoType{Name: "[]uint8", Kind: KindSlice, Elem: &GoType{Name: "uint8", Kind: KindPrimitive}}, "Uint8Array"},
+		{"rune slice", GoType{Name: "[]rune", Kind: KindSlice, Elem: &GoType{Name: "rune", Kind: KindPrimitive}}, "string"},
+		{"rune slices", GoType{Name: "[][]rune", Kind: KindSlice, Elem: &GoType{Name: "[]rune", Kind: KindSlice, Elem: &GoType{Name: "rune", Kind: KindPrimitive}}}, "string[]"},
+		{"json rune slice", GoType{Name: "[]rune", Kind: KindSlice, Elem: &GoType{Name: "rune", Kind: KindPrimitive, JSON: true}, JSON: true}, "number[]"},
 		{"int8 slice", GoType{Name: "[]int8", Kind: KindSlice, Elem: &GoType{Name: "int8", Kind: KindPrimitive}}, "Int8Array"},
 		{"int16 slice", GoType{Name: "[]int16", Kind: KindSlice, Elem: &GoType{Name: "int16", Kind: KindPrimitive}}, "Int16Array"},
 		{"int32 slice", GoType{Name: "[]int32", Kind: KindSlice, Elem: &GoType{Name: "int32", Kind: KindPrimitive}}, "Int32Array"},
@@ -438,6 +523,8 @@ func TestGoTypeToTS(t *testing.T) {
 		{"int slice", GoType{Name: "[]int", Kind: KindSlice, Elem: &GoType{Name: "int", Kind: KindPrimitive}}, "number[]"},
 		{"string slice", GoType{Name: "[]string", Kind: KindSlice, Elem: &GoType{Name: "string", Kind: KindPrimitive}}, "string[]"},
 		{"string map", GoType{Name: "map[string]int", Kind: KindMap, Key: &GoType{Name: "string", Kind: KindPrimitive}, Value: &GoType{Name: "int", Kind: KindPrimitive}}, "{[key: string]: number}"},
+		{"slice of maps", GoType{Name: "[]map[string]int", Kind: KindSlice, Elem: &GoType{Name: "map[string]int", Kind: KindMap, Key: &GoType{Name: "string", Kind: KindPrimitive}, Value: &GoType{Name: "int", Kind: KindPrimitive}}}, "{[key: string]: number}[]"},
+		{"map of slices", GoType{Name: "map[string][]int", Kind: KindMap, Key: &GoType{Name: "string", Kind: KindPrimitive}, Value: &GoType{Name: "[]int", Kind: KindSlice, Elem: &GoType{Name: "int", Kind: KindPrimitive}}}, "{[key: string]: number[]}"},
 		{"error", GoType{Name: "error", Kind: KindError, IsError: true}, "string"},
 		// Callbacks
 		{"void callback no params", GoType{Kind: KindFunction, IsVoid: true, CallbackParams: []GoType{}}, "() => void"},
@@ -446,6 +533,13 @@ func TestGoTypeToTS(t *testing.T) {
 			{Name: "string", Kind: KindPrimitive},
 			{Name: "int", Kind: KindPrimitive},
 		}}, "(arg0: string, arg1: number) => void"},
+		{"predicate callback", GoType{Kind: KindFunction, CallbackNames: []string{"s"}, CallbackParams: []GoType{
+			{Name: "string", Kind: KindPrimitive},
+		}, CallbackResults: []GoType{{Name: "bool", Kind: KindPrimitive}}}, "(s: string) => boolean"},
+		{"node-style callback", GoType{Kind: KindFunction, IsVoid: true, CallbackNames: []string{"err", "data"}, CallbackParams: []GoType{
+			{Name: "error", Kind: KindError, IsError: true},
+			{Name: "[]byte", Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}},
+		}}, "(err: string | null, data: Uint8Array) => void"},
 		// Struct inline interface
 		{"struct with fields", GoType{
 			Kind: KindStruct,
@@ -455,16 +549,63 @@ func TestGoTypeToTS(t *testing.T) {
 				{Name: "Age", JSONTag: "", Type: GoType{Name: "int", Kind: KindPrimitive}},
 			},
 		}, "{name: string, Age: number}"},
+		{"struct with ts tags", GoType{
+			Kind: KindStruct,
+			Name: "Event",
+			Fields: []GoField{
+				{Name: "CreatedAt", JSONTag: "created_at", TSName: "createdAt", Type: GoType{Name: "string", Kind: KindPrimitive}},
+				{Name: "Kind", TSName: "type", Type: GoType{Name: "string", Kind: KindPrimitive}},
+			},
+		}, "{createdAt: string, type: string}"},
+		{"struct with skipped fields", GoType{
+			Kind: KindStruct,
+			Name: "Session",
+			Fields: []GoField{
+				{Name: "Token", JSONTag: "-", Skip: true, Type: GoType{Name: "string", Kind: KindPrimitive}},
+				{Name: "ID", JSONTag: "id", Type: GoType{Name: "int", Kind: KindPrimitive}},
+			},
+		}, "{id: number}"},
+		{"struct with omitempty fields", GoType{
+			Kind: KindStruct,
+			Name: "Page",
+			Fields: []GoField{
+				{Name: "Next", JSONTag: "next", OmitEmpty: true, Type: GoType{Name: "string", Kind: KindPrimitive}},
+				// Structs are never empty, so the field stays required
+				{Name: "At", JSONTag: "at", OmitEmpty: true, Type: GoType{Name: "time.Time", Kind: KindTime}},
+			},
+		}, "{next?: string, at: Date}"},
+		{"struct with only skipped fields", GoType{
+			Kind:   KindStruct,
+			Name:   "Hidden",
+			Fields: []GoField{{Name: "Token", JSONTag: "-", Skip: true, Type: GoType{Name: "string", Kind: KindPrimitive}}},
+		}, "any"},
+		{"named struct", GoType{
+			Kind:   KindStruct,
+			Name:   "User",
+			Named:  true,
+			Fields: []GoField{{Name: "Name", Type: GoType{Name: "string", Kind: KindPrimitive}}},
+		}, "User"},
+		{"struct with string-tagged field", GoType{
+			Kind: KindStruct,
+			Name: "Counter",
+			Fields: []GoField{
+				{Name: "Count", JSONTag: "count", JSONString: true, Type: GoType{Name: "int", Kind: KindPrimitive}},
+			},
+		}, "{count: string}"},
 		{"empty struct", GoType{Kind: KindStruct, Fields: []GoField{}}, "any"},
 		// Pointer
 		{"pointer to string", GoType{Kind: KindPointer, Elem: &GoType{Name: "string", Kind: KindPrimitive}}, "string"},
 		{"pointer nil elem", GoType{Kind: KindPointer, Elem: nil}, "any"},
 		// Map with non-string key
 		{"map[int]string", GoType{Kind: KindMap, Key: &GoType{Name: "int", Kind: KindPrimitive}, Value: &GoType{Name: "string", Kind: KindPrimitive}}, "Record<number, string>"},
+		{"map[bool]int", GoType{Kind: KindMap, Key: &GoType{Name: "bool", Kind: KindPrimitive}, Value: &GoType{Name: "int", Kind: KindPrimitive}}, "Partial<Record<'true' | 'false', number>>"},
+		{"interface{}", GoType{Name: "interface{}", Kind: KindAny}, "unknown"},
 		// Unknown kind
 		{"unknown kind", GoType{Kind: 999}, "any"},
 		// Slice with nil elem
 		{"slice nil elem", GoType{Kind: KindSlice, Elem: nil}, "any[]"},
+		// Nil pointer elements are null
+		{"slice of pointers", GoType{Kind: KindSlice, Elem: &GoType{Name: "*User", Kind: KindPointer, Elem: &GoType{Name: "User", Kind: KindStruct, Named: true}}}, "(User | null)[]"},
 		// Map with nil key/value
 		{"map nil parts", GoType{Kind: KindMap, Key: nil, Value: nil}, "any"},
 	}
@@ -479,6 +620,50 @@ func TestGoTypeToTS(t *testing.T) {
 	}
 }
 
+func TestGoFieldToTS_Error(t *testing.T) {
+	field := GoField{Name: "Err", Type: GoType{Name: "error", Kind: KindError, IsError: true}}
+	if got := GoFieldToTS(field); got != "string | null" {
+		t.Errorf("GoFieldToTS(Err) = %q, want %q", got, "string | null")
+	}
+	field.OmitEmpty = true
+	if !IsOptionalField(field) {
+		t.Error("error fields with omitempty should be optional")
+	}
+}
+
+func TestGoTypeToReadonlyTS(t *testing.T) {
+	intType := GoType{Name: "int", Kind: KindPrimitive}
+	ints := GoType{Name: "[]int", Kind: KindSlice, Elem: &intType}
+	user := GoType{Name: "User", Kind: KindStruct, Named: true}
+	tests := []struct {
+		name     string
+		goType   GoType
+		expected string
+	}{
+		{"primitive", intType, "number"},
+		{"slice", ints, "ReadonlyArray<number>"},
+		{"nested slice", GoType{Name: "[][]int", Kind: KindSlice, Elem: &ints}, "ReadonlyArray<ReadonlyArray<number>>"},
+		{"typed array", GoType{Name: "[]float64", Kind: KindSlice, Elem: &GoType{Name: "float64", Kind: KindPrimitive}}, "Readonly<Float64Array>"},
+		{"base64 bytes", GoType{Name: "[]byte", Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}, Base64: true}, "string"},
+		{"rune slice", GoType{Name: "[]rune", Kind: KindSlice, Elem: &GoType{Name: "rune", Kind: KindPrimitive}}, "string"},
+		{"rune slices", GoType{Name: "[][]rune", Kind: KindSlice, Elem: &GoType{Name: "[]rune", Kind: KindSlice, Elem: &GoType{Name: "rune", Kind: KindPrimitive}}}, "ReadonlyArray<string>"},
+		{"map of slices", GoType{Name: "map[string][]int", Kind: KindMap, Key: &GoType{Name: "string", Kind: KindPrimitive}, Value: &ints}, "Readonly<{[key: string]: ReadonlyArray<number>}>"},
+		{"named struct", user, "Readonly<User>"},
+		{"struct pointer", GoType{Name: "*User", Kind: KindPointer, Elem: &user}, "Readonly<User>"},
+		{"slice of structs", GoType{Name: "[]User", Kind: KindSlice, Elem: &user}, "ReadonlyArray<Readonly<User>>"},
+		{"slice of pointers", GoType{Name: "[]*User", Kind: KindSlice, Elem: &GoType{Name: "*User", Kind: KindPointer, Elem: &user}}, "ReadonlyArray<Readonly<User> | null>"},
+		{"inline struct", GoType{Kind: KindStruct, Fields: []GoField{{Name: "X", Type: intType}}}, "Readonly<{X: number}>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := GoTypeToReadonlyTS(tt.goType); result != tt.expected {
+				t.Errorf("GoTypeToReadonlyTS(%+v) = %q, want %q", tt.goType, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestGoTypeToJSExtraction(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -510,9 +695,16 @@ func TestGoTypeToJSExtraction(t *testing.T) {
 
 		// Non-byte slice (element by element)
 		{"int slice", GoType{Kind: KindSlice, Elem: &GoType{Name: "int", Kind: KindPrimitive}}, "args[0]", false,
-			[]string{"make([]int, length)", "args[0].Index(i)", ".Int()"}},
+			[]string{"arr := args[0]", "make([]int, length)", "arr.Index(i).Int()"}},
 		{"string slice", GoType{Kind: KindSlice, Elem: &GoType{Name: "string", Kind: KindPrimitive}}, "args[0]", false,
-			[]string{"make([]string, length)", "args[0].Index(i)", ".String()"}},
+			[]string{"arr := args[0]", "make([]string, length)", "arr.Index(i).String()"}},
+		// Numeric slice (typed array bulk copy, element-by-element fallback)
+		{"float64 slice", GoType{Kind: KindSlice, Elem: &GoType{Name: "float64", Kind: KindPrimitive}}, "args[0]", false,
+			[]string{"arr.InstanceOf(js.Global().Get(\"Float64Array\"))", "unsafe.Sizeof(result[0])",
+				"js.CopyBytesToGo(dst, js.Global().Get(\"Uint8Array\").New(arr.Get(\"buffer\"), arr.Get(\"byteOffset\"), arr.Get(\"byteLength\")))",
+				"arr.Index(i).Float()"}},
+		{"named int16 slice", GoType{Kind: KindSlice, Elem: &GoType{Name: "Score", Kind: KindPrimitive, Underlying: "int16"}}, "args[0]", false,
+			[]string{"make([]Score, length)", "arr.InstanceOf(js.Global().Get(\"Int16Array\"))"}},
 		{"nil elem slice", GoType{Kind: KindSlice, Elem: nil}, "args[0]", false, []string{"nil"}},
 
 		// Map extraction
@@ -523,11 +715,18 @@ func TestGoTypeToJSExtraction(t *testing.T) {
 		}, "args[0]", false,
 			[]string{"make(map[string]int)", "Object", "keys", ".Get(key)", ".Int()"}},
 		{"map nil parts", GoType{Kind: KindMap, Key: nil, Value: nil}, "args[0]", false, []string{"nil"}},
-		{"map[int]string unsupported", GoType{
+		{"map[int]string", GoType{
 			Kind:  KindMap,
 			Key:   &GoType{Name: "int", Kind: KindPrimitive},
 			Value: &GoType{Name: "string", Kind: KindPrimitive},
-		}, "args[0]", false, []string{"nil"}},
+		}, "args[0]", false,
+			[]string{"make(map[int]string)", "strconv.ParseInt(key, 10, 64)", "return int(v)", ".Get(key).String()"}},
+		{"map[bool]int", GoType{
+			Kind:  KindMap,
+			Key:   &GoType{Name: "bool", Kind: KindPrimitive},
+			Value: &GoType{Name: "int", Kind: KindPrimitive},
+		}, "args[0]", false,
+			[]string{"make(map[bool]int)", "strconv.ParseBool(key)"}},
 
 		// Struct extraction
 		{"struct", GoType{
@@ -539,10 +738,35 @@ func TestGoTypeToJSExtraction(t *testing.T) {
 			},
 		}, "args[0]", false,
 			[]string{"User{", "Name: ", ".Get(\"name\")", ".String()", "Age: ", ".Get(\"Age\")", ".Int()"}},
+		{"struct with ts tags keeps json keys", GoType{
+			Kind: KindStruct,
+			Name: "Event",
+			Fields: []GoField{
+				{Name: "CreatedAt", JSONTag: "created_at", TSName: "createdAt", Type: GoType{Name: "string", Kind: KindPrimitive}},
+			},
+		}, "args[0]", false,
+			[]string{"CreatedAt: ", ".Get(\"created_at\")"}},
+		{"struct with string-tagged fields", GoType{
+			Kind: KindStruct,
+			Name: "Stats",
+			Fields: []GoField{
+				{Name: "Count", JSONTag: "count", JSONString: true, Type: GoType{Name: "int", Kind: KindPrimitive}},
+				{Name: "Total", JSONTag: "total", JSONString: true, Type: GoType{Name: "uint64", Kind: KindPrimitive}},
+				{Name: "Ratio", JSONTag: "ratio", JSONString: true, Type: GoType{Name: "float32", Kind: KindPrimitive}},
+				{Name: "Valid", JSONTag: "valid", JSONString: true, Type: GoType{Name: "bool", Kind: KindPrimitive}},
+			},
+		}, "args[0]", false,
+			[]string{
+				"strconv.ParseInt(args[0].Get(\"count\").String(), 10, 64)", "return int(v)",
+				"strconv.ParseUint(args[0].Get(\"total\").String(), 10, 64)", "return uint64(v)",
+				"strconv.ParseFloat(args[0].Get(\"ratio\").String(), 64)", "return float32(v)",
+				"strconv.ParseBool(args[0].Get(\"valid\").String())",
+				"panic(err)",
+			}},
 
 		// Pointer extraction
-		{"pointer to int", GoType{Kind: KindPointer, Elem: &GoType{Name: "int", Kind: KindPrimitive}}, "args[0]", false,
-			[]string{"args[0].Int()"}},
+		{"pointer to int", GoType{Name: "*int", Kind: KindPointer, Elem: &GoType{Name: "int", Kind: KindPrimitive}}, "args[0]", false,
+			[]string{"func() *int {", "args[0].IsNull() || args[0].IsUndefined()", "return nil", "elem := args[0].Int()", "return &elem"}},
 		{"pointer nil elem", GoType{Kind: KindPointer, Elem: nil}, "args[0]", false, []string{"args[0]"}},
 
 		// Callback (sync mode)
@@ -572,7 +796,7 @@ func TestGoTypeToJSExtraction(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GoTypeToJSExtraction(tt.goType, tt.argExpr, tt.workerMode)
+			result := GoTypeToJSExtraction(tt.goType, tt.argExpr, tt.workerMode, nil)
 			for _, substr := range tt.contains {
 				if !strings.Contains(result, substr) {
 					t.Errorf("GoTypeToJSExtraction() = %q, should contain %q", result, substr)
@@ -616,11 +840,11 @@ func TestGoTypeToJSReturn(t *testing.T) {
 		{"float64 slice", GoType{Kind: KindSlice, Elem: &GoType{Name: "float64", Kind: KindPrimitive}}, "result",
 			[]string{"Float64Array", "SetIndex"}},
 
-		// Non-typed array slices (returned directly)
+		// Non-typed array slices (copied into []interface{} for js.ValueOf)
 		{"int slice", GoType{Kind: KindSlice, Elem: &GoType{Name: "int", Kind: KindPrimitive}}, "result",
-			[]string{"result"}},
+			[]string{"make([]interface{}, len(result))", "out[i] = v"}},
 		{"string slice", GoType{Kind: KindSlice, Elem: &GoType{Name: "string", Kind: KindPrimitive}}, "result",
-			[]string{"result"}},
+			[]string{"make([]interface{}, len(result))", "out[i] = v"}},
 		{"nil elem slice", GoType{Kind: KindSlice, Elem: nil}, "result", []string{"nil"}},
 
 		// Struct slices (element conversion)
@@ -635,7 +859,11 @@ func TestGoTypeToJSReturn(t *testing.T) {
 
 		// Map return
 		{"map", GoType{Kind: KindMap, Key: &GoType{Name: "string"}, Value: &GoType{Name: "int"}}, "result",
+			[]string{"out := make(map[string]interface{}, len(result))", "out[k] = v"}},
+		{"map any values", GoType{Kind: KindMap, Key: &GoType{Name: "string"}, Value: &GoType{Name: "any"}}, "result",
 			[]string{"map[string]interface{}(result)"}},
+		{"map int keys", GoType{Kind: KindMap, Key: &GoType{Name: "int"}, Value: &GoType{Name: "string"}}, "result",
+			[]string{"out[fmt.Sprint(k)] = v"}},
 
 		// Struct return
 		{"struct", GoType{
@@ -647,10 +875,39 @@ func TestGoTypeToJSReturn(t *testing.T) {
 			},
 		}, "result",
 			[]string{"map[string]interface{}{", "\"name\": result.Name", "\"age\": result.Age"}},
+		{"struct with omitempty fields", GoType{
+			Kind: KindStruct,
+			Name: "Page",
+			Fields: []GoField{
+				{Name: "Items", JSONTag: "items", Type: GoType{Name: "int", Kind: KindPrimitive}},
+				{Name: "Next", JSONTag: "next", OmitEmpty: true, Type: GoType{Name: "string", Kind: KindPrimitive}},
+				{Name: "Done", JSONTag: "done", OmitEmpty: true, Type: GoType{Name: "bool", Kind: KindPrimitive}},
+			},
+		}, "result",
+			[]string{"out := map[string]interface{}{", "\"items\": result.Items", "if result.Next != \"\" {",
+				"out[\"next\"] = result.Next", "if result.Done {", "return out"}},
+		{"struct with string-tagged field", GoType{
+			Kind: KindStruct,
+			Name: "Counter",
+			Fields: []GoField{
+				{Name: "Count", JSONTag: "count", JSONString: true, Type: GoType{Name: "int", Kind: KindPrimitive}},
+			},
+		}, "result",
+			[]string{"\"count\": fmt.Sprint(result.Count)"}},
 
 		// Pointer return
 		{"pointer to int", GoType{Kind: KindPointer, Elem: &GoType{Name: "int", Kind: KindPrimitive}}, "result",
-			[]string{"result"}},
+			[]string{"if result == nil {", "return nil", "return (*result)"}},
+		{"pointer to struct", GoType{Kind: KindPointer, Elem: &GoType{
+			Kind: KindStruct,
+			Name: "User",
+			Fields: []GoField{
+				{Name: "Name", JSONTag: "name", Type: GoType{Name: "string", Kind: KindPrimitive}},
+			},
+		}}, "result.Lead",
+			[]string{"if result.Lead == nil {", "\"name\": (*result.Lead).Name"}},
+		{"recursive struct", GoType{Kind: KindStruct, Name: "Node", Named: true, Recursive: true}, "result",
+			[]string{"gowasmNodeToJS(result)"}},
 		{"pointer nil elem", GoType{Kind: KindPointer, Elem: nil}, "result", []string{"result"}},
 
 		// Error return
@@ -662,7 +919,7 @@ func TestGoTypeToJSReturn(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GoTypeToJSReturn(tt.goType, tt.valueExpr)
+			result := GoTypeToJSReturn(tt.goType, tt.valueExpr, nil)
 			for _, substr := range tt.contains {
 				if !strings.Contains(result, substr) {
 					t.Errorf("GoTypeToJSReturn() = %q, should contain %q", result, substr)
@@ -672,176 +929,1041 @@ func TestGoTypeToJSReturn(t *testing.T) {
 	}
 }
 
-func TestCallbackWrapperCode(t *testing.T) {
-	tests := []struct {
-		name     string
-		goType   GoType
-		argExpr  string
-		contains []string
-	}{
-		{"no params", GoType{
-			Kind:           KindFunction,
-			CallbackParams: []GoType{},
-		}, "cb", []string{"func()", "cb.Invoke()"}},
-
-		{"one param", GoType{
-			Kind: KindFunction,
-			CallbackParams: []GoType{
-				{Name: "string", Kind: KindPrimitive},
-			},
-		}, "cb", []string{"func(arg0 string)", "cb.Invoke(arg0)"}},
+func TestGoTypeToJSSharedReturn(t *testing.T) {
+	bytes := GoType{Name: "[]byte", Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}}
+	got := GoTypeToJSSharedReturn(bytes, "result", nil)
+	for _, want := range []string{"sab.New(len(result))", `Get("Uint8Array").New(len(result))`, "js.CopyBytesToJS(arr, result)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GoTypeToJSSharedReturn([]byte) = %q, should contain %q", got, want)
+		}
+	}
 
-		{"two params", GoType{
-			Kind: KindFunction,
-			CallbackParams: []GoType{
-				{Name: "string", Kind: KindPrimitive},
-				{Name: "int", Kind: KindPrimitive},
-			},
-		}, "cb", []string{"func(arg0 string, arg1 int)", "cb.Invoke(arg0, arg1)"}},
+	score := GoType{Name: "[]Score", Kind: KindSlice, Elem: &GoType{Name: "Score", Kind: KindPrimitive, Underlying: "int32"}}
+	got = GoTypeToJSSharedReturn(score, "result", nil)
+	for _, want := range []string{
+		`Get("Int32Array").New(sab.New(len(slice) * int(unsafe.Sizeof(slice[0]))))`,
+		`Get("Int32Array").New(len(slice))`,
+		"src := unsafe.Slice((*byte)(unsafe.Pointer(&slice[0])), len(slice)*int(unsafe.Sizeof(slice[0])))",
+		`js.CopyBytesToJS(js.Global().Get("Uint8Array").New(arr.Get("buffer")), src)`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GoTypeToJSSharedReturn([]Score) = %q, should contain %q", got, want)
+		}
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := callbackWrapperCode(tt.goType, tt.argExpr)
-			for _, substr := range tt.contains {
-				if !strings.Contains(result, substr) {
-					t.Errorf("callbackWrapperCode() = %q, should contain %q", result, substr)
-				}
-			}
-		})
+	// Types without a typed array are unchanged
+	for _, other := range []GoType{
+		{Name: "string", Kind: KindPrimitive},
+		{Name: "[]int", Kind: KindSlice, Elem: &GoType{Name: "int", Kind: KindPrimitive}},
+	} {
+		if got, want := GoTypeToJSSharedReturn(other, "result", nil), GoTypeToJSReturn(other, "result", nil); got != want {
+			t.Errorf("GoTypeToJSSharedReturn(%s) = %q, want %q", other.Name, got, want)
+		}
 	}
 }
 
-func TestWorkerCallbackCode(t *testing.T) {
-	tests := []struct {
-		name     string
-		goType   GoType
-		argExpr  string
-		contains []string
-	}{
-		{"no params", GoType{
-			Kind:           KindFunction,
-			CallbackParams: []GoType{},
-		}, "cb", []string{"func()", "invokeCallback", "cb.Int()"}},
+func TestParseSourceFile_Time(t *testing.T) {
+	src := `package main
 
-		{"one param", GoType{
-			Kind: KindFunction,
-			CallbackParams: []GoType{
-				{Name: "string", Kind: KindPrimitive},
-			},
-		}, "cb", []string{"func(arg0 string)", "cbArgs.Call(\"push\", arg0)", "invokeCallback"}},
+import "time"
 
-		{"two params", GoType{
-			Kind: KindFunction,
-			CallbackParams: []GoType{
-				{Name: "int", Kind: KindPrimitive},
-				{Name: "bool", Kind: KindPrimitive},
-			},
-		}, "cb", []string{"func(arg0 int, arg1 bool)", "cbArgs.Call(\"push\", arg0)", "cbArgs.Call(\"push\", arg1)"}},
+type Event struct {
+	At time.Time
+}
+
+func Later(e Event, ts []time.Time) time.Time { return e.At }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "time.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := workerCallbackCode(tt.goType, tt.argExpr)
-			for _, substr := range tt.contains {
-				if !strings.Contains(result, substr) {
-					t.Errorf("workerCallbackCode() = %q, should contain %q", result, substr)
-				}
-			}
-		})
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
 	}
-}
 
-func TestHasSelectInMain(t *testing.T) {
-	tests := []struct {
-		name     string
-		src      string
-		expected bool
-	}{
-		{
-			name: "main with select {}",
-			src: `package main
+	fn := parsed.Functions[0]
+	for name, got := range map[string]GoType{
+		"struct field":  fn.Params[0].Type.Fields[0].Type,
+		"slice element": *fn.Params[1].Type.Elem,
+		"return":        fn.Returns[0],
+	} {
+		if got.Kind != KindTime || got.Name != "time.Time" {
+			t.Errorf("%s: got %+v, want time.Time", name, got)
+		}
+	}
 
-func main() {
-	// setup code
-	select {}
+	if got := GoTypeToTS(fn.Params[1].Type); got != "Date[]" {
+		t.Errorf("GoTypeToTS([]time.Time) = %q, want Date[]", got)
+	}
 }
-`,
-			expected: true,
-		},
-		{
-			name: "main without select",
-			src: `package main
 
-func main() {
-	println("hello")
-}
-`,
-			expected: false,
-		},
-		{
-			name: "no main function",
-			src: `package main
+func TestParseSourceFile_Any(t *testing.T) {
+	src := `package main
 
-func other() {
-	select {}
-}
-`,
-			expected: false,
-		},
-		{
-			name: "select in nested if",
-			src: `package main
+type Stringer interface{ String() string }
 
-func main() {
-	if true {
-		select {}
+func Handle(v interface{}, opts map[string]any, s Stringer) []any { return nil }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "any.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
 	}
-}
-`,
-			expected: true,
-		},
-		{
-			name: "select in for loop",
-			src: `package main
 
-func main() {
-	for {
-		select {}
+	fn := parsed.Functions[0]
+	for name, got := range map[string]GoType{
+		"interface{} param": fn.Params[0].Type,
+		"any map value":     *fn.Params[1].Type.Value,
+		"any slice element": *fn.Returns[0].Elem,
+	} {
+		if got.Kind != KindAny {
+			t.Errorf("%s: got %+v, want KindAny", name, got)
+		}
+	}
+	if got := fn.Params[2].Type.Kind; got != KindUnsupported {
+		t.Errorf("interface with methods: got kind %v, want KindUnsupported", got)
 	}
-}
-`,
-			expected: true,
-		},
-		{
-			name: "select with cases (not empty)",
-			src: `package main
 
-func main() {
-	ch := make(chan int)
-	select {
-	case <-ch:
+	if got := GoTypeToTS(fn.Returns[0]); got != "unknown[]" {
+		t.Errorf("GoTypeToTS([]any) = %q, want unknown[]", got)
 	}
 }
-`,
-			expected: false,
-		},
-		{
-			name: "method named main ignored",
-			src: `package main
 
-type S struct{}
+func TestParseSourceFile_IgnoreDirective(t *testing.T) {
+	src := `package main
 
-func (s S) main() {
-	select {}
-}
+// Greet is bound as usual
+func Greet(name string) string { return name }
 
-func main() {
-	println("no select")
-}
-`,
-			expected: false,
-		},
+// ResetForTest is exported for tests only.
+//
+//gowasm:ignore
+func ResetForTest() {}
+
+type Counter struct{}
+
+//gowasm:ignore
+func (c *Counter) Reset() {}
+
+func (c *Counter) Inc() int { return 0 }
+
+func main() { select {} }
+`
+
+	tmpFile := filepath.Join(t.TempDir(), "ignore.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+
+	if len(parsed.Functions) != 1 || parsed.Functions[0].Name != "Greet" {
+		t.Errorf("Functions = %+v, want only Greet", parsed.Functions)
+	}
+	methods := parsed.Methods["Counter"]
+	if len(methods) != 1 || methods[0].Name != "Inc" {
+		t.Errorf("Counter methods = %+v, want only Inc", methods)
+	}
+}
+
+func TestParseSource_NameDirective(t *testing.T) {
+	src := `package main
+
+// HTTPGet fetches a URL.
+//
+//gowasm:name httpGet
+func HTTPGet(url string) string { return url }
+
+func Plain() {}
+
+func main() { select {} }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "name.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+
+	get := parsed.Functions[0]
+	if get.JSName != "httpGet" || get.BindingName() != "httpGet" {
+		t.Errorf("HTTPGet: JSName = %q, BindingName() = %q, want httpGet", get.JSName, get.BindingName())
+	}
+	if get.Doc != "HTTPGet fetches a URL." {
+		t.Errorf("HTTPGet: Doc = %q, want the directive left out", get.Doc)
+	}
+	if plain := parsed.Functions[1]; plain.JSName != "" || plain.BindingName() != "plain" {
+		t.Errorf("Plain: JSName = %q, BindingName() = %q, want the default name", plain.JSName, plain.BindingName())
+	}
+}
+
+func TestParseSourceFile_AnyAlias(t *testing.T) {
+	parse := func(typ string) GoFunction {
+		src := "package main\n\nfunc F(x " + typ + ", xs []" + typ + ", m map[string]" + typ + ") " + typ + " { return nil }\n"
+		parsed, err := ParseSource(strings.NewReader(src), "alias.go")
+		if err != nil {
+			t.Fatalf("ParseSource(%s) error: %v", typ, err)
+		}
+		return parsed.Functions[0]
+	}
+
+	viaAny, viaInterface := parse("any"), parse("interface{}")
+	if !reflect.DeepEqual(viaAny, viaInterface) {
+		t.Errorf("any and interface{} parse differently:\nany:         %+v\ninterface{}: %+v", viaAny, viaInterface)
+	}
+}
+
+func TestParseSourceFile_Context(t *testing.T) {
+	src := `package main
+
+import "context"
+
+func Lookup(ctx context.Context, id int) string { return "" }
+func Later(id int, ctx context.Context) string  { return "" }
+func Plain(id int) string                        { return "" }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "context.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+
+	lookup := parsed.Functions[0]
+	if !lookup.Context || len(lookup.Params) != 1 || lookup.Params[0].Name != "id" {
+		t.Errorf("Lookup: Context = %v, Params = %+v; want the context dropped", lookup.Context, lookup.Params)
+	}
+	later := parsed.Functions[1]
+	if later.Context || len(later.Params) != 2 || later.Params[1].Type.Name != "context.Context" {
+		t.Errorf("Later: Context = %v, Params = %+v; want the context kept for validation", later.Context, later.Params)
+	}
+	if parsed.Functions[2].Context {
+		t.Error("Plain: Context = true")
+	}
+}
+
+func TestParseSourceFile_Aliases(t *testing.T) {
+	src := `package main
+
+type UserID = int
+type IDs = []UserID
+type Celsius float64
+type Temp = Celsius
+type Point struct{ X, Y int }
+type P = Point
+
+func Get(id UserID, ids IDs, t Temp, p P) P { return p }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "aliases.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+
+	for _, alias := range []string{"UserID", "IDs", "Temp", "P"} {
+		if _, ok := parsed.Types[alias]; ok {
+			t.Errorf("alias %s should not be declared in Types", alias)
+		}
+	}
+
+	fn := parsed.Functions[0]
+	for name, tt := range map[string]struct {
+		got  GoType
+		want string
+	}{
+		"alias of int":           {fn.Params[0].Type, "number"},
+		"alias of slice":         {fn.Params[1].Type, "number[]"},
+		"alias of named type":    {fn.Params[2].Type, "Celsius"},
+		"alias of struct param":  {fn.Params[3].Type, "Point"},
+		"alias of struct return": {fn.Returns[0], "Point"},
+	} {
+		if got := GoTypeToTS(tt.got); got != tt.want {
+			t.Errorf("%s: GoTypeToTS() = %q, want %q", name, got, tt.want)
+		}
+	}
+	if fn.Params[0].Type.Kind != KindPrimitive || fn.Params[0].Type.Name != "int" {
+		t.Errorf("UserID should resolve to int, got %+v", fn.Params[0].Type)
+	}
+}
+
+func TestParseSourceFile_FieldDocs(t *testing.T) {
+	src := `package main
+
+type User struct {
+	// Name is shown in the header.
+	// It may be empty.
+	Name string // ignored when there is a doc comment
+	Age  int    // years
+	ID   int
+}
+
+func Get() User { return User{} }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "docs.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+
+	want := []string{"Name is shown in the header.\nIt may be empty.", "years", ""}
+	fields := parsed.Types["User"].Fields
+	if len(fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(fields), len(want))
+	}
+	for i, w := range want {
+		if fields[i].Doc != w {
+			t.Errorf("field %s Doc = %q, want %q", fields[i].Name, fields[i].Doc, w)
+		}
+	}
+}
+
+func TestParseSourceFile_UnsupportedBuiltins(t *testing.T) {
+	src := `package main
+
+func Mix(a complex64, b complex128, p uintptr) {}
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "builtins.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+	for i, want := range []string{"complex64", "complex128", "uintptr"} {
+		if got := parsed.Functions[0].Params[i].Type; got.Kind != KindUnsupported || got.Name != want {
+			t.Errorf("param %d: got %+v, want KindUnsupported %s", i, got, want)
+		}
+	}
+}
+
+func TestUseBigInt(t *testing.T) {
+	src := `package main
+
+type Hash uint64
+
+type Stats struct {
+	Count int64
+	Ratio float64
+}
+
+func Mix(a int64, ids []uint64, m map[int64]int64, n int) Hash { return 0 }
+
+func Sum(s Stats, cb func(int64)) int32 { return 0 }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "bigint.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+	UseBigInt(parsed)
+
+	mix, sum := parsed.Functions[0], parsed.Functions[1]
+	tests := []struct {
+		name string
+		got  GoType
+		want bool
+	}{
+		{"int64 param", mix.Params[0].Type, true},
+		{"uint64 slice element", *mix.Params[1].Type.Elem, true},
+		{"map key", *mix.Params[2].Type.Key, false},
+		{"map value", *mix.Params[2].Type.Value, true},
+		{"int param", mix.Params[3].Type, false},
+		{"named uint64 return", mix.Returns[0], true},
+		{"int64 struct field", sum.Params[0].Type.Fields[0].Type, true},
+		{"float64 struct field", sum.Params[0].Type.Fields[1].Type, false},
+		{"callback param", sum.Params[1].Type.CallbackParams[0], true},
+		{"int32 return", sum.Returns[0], false},
+		{"named type definition", *parsed.Types["Hash"], true},
+	}
+	for _, tt := range tests {
+		if tt.got.BigInt != tt.want {
+			t.Errorf("%s: BigInt = %v, want %v", tt.name, tt.got.BigInt, tt.want)
+		}
+	}
+}
+
+func TestBigIntConversions(t *testing.T) {
+	i64 := GoType{Name: "int64", Kind: KindPrimitive, BigInt: true}
+	u64 := GoType{Name: "uint64", Kind: KindPrimitive, BigInt: true}
+	hash := GoType{Name: "Hash", Kind: KindPrimitive, Underlying: "uint64", BigInt: true}
+
+	if got := GoTypeToTS(i64); got != "bigint" {
+		t.Errorf("GoTypeToTS(int64) = %q, want bigint", got)
+	}
+	if got := GoTypeToTS(GoType{Name: "[]int64", Kind: KindSlice, Elem: &i64}); got != "bigint[]" {
+		t.Errorf("GoTypeToTS([]int64) = %q, want bigint[]", got)
+	}
+	if got := GoTypeToTS(hash); got != "Hash" {
+		t.Errorf("GoTypeToTS(Hash) = %q, want Hash", got)
+	}
+
+	extractions := []struct {
+		t    GoType
+		want []string
+	}{
+		{i64, []string{`strconv.ParseInt(js.Global().Call("String", args[0]).String(), 10, 64)`, "return int64(v)"}},
+		{u64, []string{`strconv.ParseUint(js.Global().Call("String", args[0]).String(), 10, 64)`, "return uint64(v)"}},
+		{hash, []string{"strconv.ParseUint(", "return Hash(v)"}},
+	}
+	for _, tt := range extractions {
+		got := GoTypeToJSExtraction(tt.t, "args[0]", false, nil)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("GoTypeToJSExtraction(%s) = %q, should contain %q", tt.t.Name, got, want)
+			}
+		}
+	}
+
+	returns := []struct {
+		t    GoType
+		want string
+	}{
+		{i64, `js.Global().Get("BigInt").Invoke(strconv.FormatInt(int64(result), 10))`},
+		{u64, `js.Global().Get("BigInt").Invoke(strconv.FormatUint(uint64(result), 10))`},
+		{hash, `js.Global().Get("BigInt").Invoke(strconv.FormatUint(uint64(result), 10))`},
+	}
+	for _, tt := range returns {
+		if got := GoTypeToJSReturn(tt.t, "result", nil); got != tt.want {
+			t.Errorf("GoTypeToJSReturn(%s) = %q, want %q", tt.t.Name, got, tt.want)
+		}
+	}
+
+	// Slices convert element by element instead of passing through js.ValueOf
+	slice := GoTypeToJSReturn(GoType{Name: "[]int64", Kind: KindSlice, Elem: &i64}, "result", nil)
+	if !strings.Contains(slice, `out[i] = js.Global().Get("BigInt")`) {
+		t.Errorf("GoTypeToJSReturn([]int64) = %q, should convert each element", slice)
+	}
+}
+
+func TestUseBase64Bytes(t *testing.T) {
+	src := `package main
+
+type Blob struct {
+	Data []byte
+	Tags []string
+}
+
+func Pack(b []byte, parts [][]byte, m map[string][]byte, ints []int32) Blob { return Blob{} }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "base64.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+	UseBase64Bytes(parsed)
+
+	pack := parsed.Functions[0]
+	tests := []struct {
+		name string
+		got  GoType
+		want bool
+	}{
+		{"byte slice param", pack.Params[0].Type, true},
+		{"outer slice of byte slices", pack.Params[1].Type, false},
+		{"inner byte slice", *pack.Params[1].Type.Elem, true},
+		{"map value", *pack.Params[2].Type.Value, true},
+		{"int32 slice", pack.Params[3].Type, false},
+		{"byte slice struct field", pack.Returns[0].Fields[0].Type, true},
+		{"string slice struct field", pack.Returns[0].Fields[1].Type, false},
+	}
+	for _, tt := range tests {
+		if tt.got.Base64 != tt.want {
+			t.Errorf("%s: Base64 = %v, want %v", tt.name, tt.got.Base64, tt.want)
+		}
+	}
+}
+
+func TestUsePlainArrays(t *testing.T) {
+	src := `package main
+
+type Series struct {
+	Points []float64
+	Raw    []byte
+}
+
+func Sum(xs []int32, grid [][]float32, fixed [3]uint16, b []byte, names []string) Series { return Series{} }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "plain.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+	UsePlainArrays(parsed)
+
+	sum := parsed.Functions[0]
+	tests := []struct {
+		name string
+		got  GoType
+		want bool
+	}{
+		{"int32 slice", sum.Params[0].Type, true},
+		{"outer slice of float32 slices", sum.Params[1].Type, false},
+		{"inner float32 slice", *sum.Params[1].Type.Elem, true},
+		{"uint16 array", sum.Params[2].Type, true},
+		{"byte slice", sum.Params[3].Type, false},
+		{"string slice", sum.Params[4].Type, false},
+		{"float64 slice struct field", sum.Returns[0].Fields[0].Type, true},
+		{"byte slice struct field", sum.Returns[0].Fields[1].Type, false},
+	}
+	for _, tt := range tests {
+		if tt.got.PlainArray != tt.want {
+			t.Errorf("%s: PlainArray = %v, want %v", tt.name, tt.got.PlainArray, tt.want)
+		}
+	}
+}
+
+func TestUseTypedArrayBytes(t *testing.T) {
+	src := `package main
+
+type Score int32
+
+func Mix(xs []int32, scores []Score, b []byte, names []string, grid [][]float32, rest ...float64) []float64 { return nil }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "bytes.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+	UseTypedArrayBytes(parsed, "big")
+
+	mix := parsed.Functions[0]
+	tests := []struct {
+		name string
+		got  GoType
+		want string
+	}{
+		{"int32 slice", mix.Params[0].Type, "big"},
+		{"named int32 slice", mix.Params[1].Type, "big"},
+		{"byte slice", mix.Params[2].Type, ""},
+		{"string slice", mix.Params[3].Type, ""},
+		{"outer slice of float32 slices", mix.Params[4].Type, ""},
+		{"inner float32 slice", *mix.Params[4].Type.Elem, ""},
+		{"variadic float64", mix.Params[5].Type, ""},
+		{"float64 slice result", mix.Returns[0], ""},
+	}
+	for _, tt := range tests {
+		if tt.got.ByteOrder != tt.want {
+			t.Errorf("%s: ByteOrder = %q, want %q", tt.name, tt.got.ByteOrder, tt.want)
+		}
+	}
+
+	if got := GoParamToTS(mix.Params[0]); got != "xs: Int32Array | DataView" {
+		t.Errorf("GoParamToTS() = %q, want the typed array or a DataView", got)
+	}
+	if got := GoTypeToTS(mix.Returns[0]); got != "Float64Array" {
+		t.Errorf("GoTypeToTS(result) = %q, want Float64Array", got)
+	}
+}
+
+func TestUseJSONStructs(t *testing.T) {
+	src := `package main
+
+import "time"
+
+type Event struct {
+	At    time.Time
+	ID    int64
+	Data  []byte
+	Temps []float64
+	Err   error
+}
+
+func Log(e Event, at time.Time, id int64, data []byte) *Event { return nil }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "json.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+	UseBigInt(parsed)
+	UseJSONStructs(parsed)
+
+	log := parsed.Functions[0]
+	event := log.Params[0].Type
+	tests := []struct {
+		name string
+		got  GoType
+		want string
+	}{
+		{"time field", event.Fields[0].Type, "string"},
+		{"int64 field", event.Fields[1].Type, "number"},
+		{"byte slice field", event.Fields[2].Type, "string | null"},
+		{"float64 slice field", event.Fields[3].Type, "number[] | null"},
+		{"time param", log.Params[1].Type, "Date"},
+		{"int64 param", log.Params[2].Type, "bigint"},
+		{"byte slice param", log.Params[3].Type, "Uint8Array"},
+	}
+	for _, tt := range tests {
+		if got := GoTypeToTS(tt.got); got != tt.want {
+			t.Errorf("%s: GoTypeToTS() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := GoFieldToTS(event.Fields[4]); got != "unknown" {
+		t.Errorf("error field: GoFieldToTS() = %q, want unknown", got)
+	}
+	if !event.JSON || !log.Returns[0].Elem.JSON || !parsed.Types["Event"].JSON {
+		t.Error("struct types not marked JSON")
+	}
+	if log.Params[1].Type.JSON {
+		t.Error("time param marked JSON outside a struct")
+	}
+
+	if got := GoTypeToJSReturn(event, "result", nil); got != "gowasmEncodeAny(result)" {
+		t.Errorf("GoTypeToJSReturn(Event) = %q", got)
+	}
+	if got := GoTypeToJSExtraction(event, "args[0]", false, nil); !strings.Contains(got, "gowasmDecodeJSON(args[0], &out)") {
+		t.Errorf("GoTypeToJSExtraction(Event) = %q", got)
+	}
+}
+
+func TestUseNilAsNull(t *testing.T) {
+	src := `package main
+
+type Box struct {
+	Items []string
+}
+
+func Fill(grid [][]int, fixed [2]int, m map[string][]byte) (Box, []int32) { return Box{}, nil }
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "nil.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+	UseNilAsNull(parsed)
+
+	fill := parsed.Functions[0]
+	tests := []struct {
+		name string
+		got  GoType
+		want string
+	}{
+		{"nested slices", fill.Params[0].Type, "(number[] | null)[] | null"},
+		{"array", fill.Params[1].Type, "number[]"},
+		{"map of byte slices", fill.Params[2].Type, "{[key: string]: Uint8Array | null} | null"},
+		{"struct field", fill.Returns[0].Fields[0].Type, "string[] | null"},
+		{"typed array result", fill.Returns[1], "Int32Array | null"},
+	}
+	for _, tt := range tests {
+		if got := GoTypeToTS(tt.got); got != tt.want {
+			t.Errorf("%s: GoTypeToTS() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := GoTypeToReadonlyTS(fill.Params[0].Type); got != "ReadonlyArray<ReadonlyArray<number> | null> | null" {
+		t.Errorf("GoTypeToReadonlyTS([][]int) = %q", got)
+	}
+
+	ints := fill.Returns[1]
+	for name, got := range map[string]string{
+		"return":        GoTypeToJSReturn(ints, "result", nil),
+		"shared return": GoTypeToJSSharedReturn(ints, "result", nil),
+	} {
+		if !strings.HasPrefix(got, "func() interface{} {\n\t\tif result == nil {\n\t\t\treturn nil") {
+			t.Errorf("%s: missing nil check:\n%s", name, got)
+		}
+	}
+	got := GoTypeToJSExtraction(fill.Params[2].Type, "args[2]", false, nil)
+	if !strings.Contains(got, "if args[2].IsNull() || args[2].IsUndefined() {") {
+		t.Errorf("extraction missing null check:\n%s", got)
+	}
+}
+
+func TestPlainArrayConversions(t *testing.T) {
+	f := GoType{Name: "[]float64", Kind: KindSlice, Elem: &GoType{Name: "float64", Kind: KindPrimitive}, PlainArray: true}
+
+	if got := GoTypeToTS(f); got != "number[]" {
+		t.Errorf("GoTypeToTS([]float64) = %q, want number[]", got)
+	}
+
+	got := GoTypeToJSReturn(f, "result", nil)
+	if strings.Contains(got, "Float64Array") || !strings.Contains(got, "[]interface{}") {
+		t.Errorf("GoTypeToJSReturn([]float64) should build a plain array, got:\n%s", got)
+	}
+	if shared := GoTypeToJSSharedReturn(f, "result", nil); shared != got {
+		t.Errorf("GoTypeToJSSharedReturn([]float64) = %q, want %q", shared, got)
+	}
+}
+
+func TestUseResultUnion(t *testing.T) {
+	errType := GoType{Name: "error", Kind: KindError, IsError: true}
+	parsed := &ParsedFile{Functions: []GoFunction{
+		{Name: "Find", Returns: []GoType{{Name: "int", Kind: KindPrimitive}, errType}},
+		{Name: "Check", Returns: []GoType{errType}},
+		{Name: "Plain", Returns: []GoType{{Name: "int", Kind: KindPrimitive}}},
+		{Name: "Void"},
+	}}
+	UseResultUnion(parsed)
+
+	for i, want := range []bool{true, true, false, false} {
+		if got := parsed.Functions[i].ResultUnion; got != want {
+			t.Errorf("%s: ResultUnion = %v, want %v", parsed.Functions[i].Name, got, want)
+		}
+	}
+}
+
+func TestUseMethodsOf(t *testing.T) {
+	src := `package main
+
+type Counter struct{ total int }
+
+// Add adds n to the total.
+func (c *Counter) Add(n int) int { c.total += n; return c.total }
+func (c Counter) Total() int     { return c.total }
+func (c *Counter) reset()        { c.total = 0 }
+
+type List[T any] struct{ items []T }
+
+func (l *List[T]) Len() int { return len(l.items) }
+
+func Hello(name string) string { return "hi " + name }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "methods.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+	if len(parsed.Functions) != 1 {
+		t.Fatalf("methods should not be bound by default, got %d functions", len(parsed.Functions))
+	}
+	if _, ok := parsed.Methods["List"]; ok {
+		t.Error("methods of generic types should be skipped")
+	}
+
+	if err := UseMethodsOf(parsed, "Counter"); err != nil {
+		t.Fatalf("UseMethodsOf() error: %v", err)
+	}
+	var names []string
+	for _, fn := range parsed.Functions {
+		names = append(names, fn.Name+"/"+fn.Receiver)
+	}
+	if got, want := strings.Join(names, ","), "Hello/,Add/Counter,Total/Counter"; got != want {
+		t.Errorf("Functions = %s, want %s", got, want)
+	}
+	if add := parsed.Functions[1]; add.Doc != "Add adds n to the total." || len(add.Params) != 1 || add.Params[0].Name != "n" {
+		t.Errorf("Add = %+v", add)
+	}
+
+	if err := UseMethodsOf(parsed, "Missing"); err == nil || !strings.Contains(err.Error(), "type Missing has no exported methods") {
+		t.Errorf("UseMethodsOf(Missing) error = %v", err)
+	}
+}
+
+func TestUseStructHelpers(t *testing.T) {
+	src := `package main
+
+type Inner struct{ Label string }
+
+type Outer struct {
+	Inner  Inner
+	Others []Inner
+}
+
+func Echo(o Outer, n int, anon struct{ X int }) Outer { return o }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "helpers.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+	UseStructHelpers(parsed)
+
+	echo := parsed.Functions[0]
+	outer := echo.Params[0].Type
+	tests := []struct {
+		name string
+		got  GoType
+		want bool
+	}{
+		{"named struct param", outer, true},
+		{"named struct return", echo.Returns[0], true},
+		{"named struct field", outer.Fields[0].Type, true},
+		{"slice element", *outer.Fields[1].Type.Elem, true},
+		{"anonymous struct param", echo.Params[2].Type, false},
+		{"primitive param", echo.Params[1].Type, false},
+		{"type table entry", *parsed.Types["Inner"], true},
+	}
+	for _, tt := range tests {
+		if tt.got.Helpers != tt.want {
+			t.Errorf("%s: Helpers = %v, want %v", tt.name, tt.got.Helpers, tt.want)
+		}
+	}
+
+	if got := GoTypeToJSExtraction(outer, "args[0]", false, nil); got != "gowasmOuterFromJS(args[0])" {
+		t.Errorf("extraction = %q, want helper call", got)
+	}
+	if got := GoTypeToJSReturn(outer, "result", nil); got != "gowasmOuterToJS(result)" {
+		t.Errorf("return = %q, want helper call", got)
+	}
+	helpers := StructHelpers(outer, false, nil)
+	for _, want := range []string{
+		"Inner: gowasmInnerFromJS(v.Get(\"Inner\")),",
+		"\"inner\": gowasmInnerToJS(v.Inner),",
+		"out[i] = gowasmInnerToJS(v)",
+	} {
+		if !strings.Contains(helpers, want) {
+			t.Errorf("StructHelpers() missing %q:\n%s", want, helpers)
+		}
+	}
+}
+
+func TestBase64Conversions(t *testing.T) {
+	b := GoType{Name: "[]byte", Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}, Base64: true}
+
+	if got := GoTypeToTS(b); got != "string" {
+		t.Errorf("GoTypeToTS([]byte) = %q, want string", got)
+	}
+	if got := GoTypeToTS(GoType{Name: "[][]byte", Kind: KindSlice, Elem: &b}); got != "string[]" {
+		t.Errorf("GoTypeToTS([][]byte) = %q, want string[]", got)
+	}
+
+	got := GoTypeToJSExtraction(b, "args[0]", false, nil)
+	for _, want := range []string{"base64.StdEncoding.DecodeString(args[0].String())", "panic(err)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GoTypeToJSExtraction([]byte) = %q, should contain %q", got, want)
+		}
+	}
+
+	want := "base64.StdEncoding.EncodeToString(result)"
+	if got := GoTypeToJSReturn(b, "result", nil); got != want {
+		t.Errorf("GoTypeToJSReturn([]byte) = %q, want %q", got, want)
+	}
+	// Shared memory only applies to Uint8Array results
+	if got := GoTypeToJSSharedReturn(b, "result", nil); got != want {
+		t.Errorf("GoTypeToJSSharedReturn([]byte) = %q, want %q", got, want)
+	}
+}
+
+func TestCallbackWrapperCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		goType   GoType
+		argExpr  string
+		contains []string
+	}{
+		{"no params", GoType{
+			Kind:           KindFunction,
+			CallbackParams: []GoType{},
+		}, "cb", []string{"func()", "if cb.Truthy() {\n\t\t\tcb.Invoke()"}},
+
+		{"one param", GoType{
+			Kind: KindFunction,
+			CallbackParams: []GoType{
+				{Name: "string", Kind: KindPrimitive},
+			},
+		}, "cb", []string{"func(arg0 string)", "cb.Invoke(arg0)"}},
+
+		{"two params", GoType{
+			Kind: KindFunction,
+			CallbackParams: []GoType{
+				{Name: "string", Kind: KindPrimitive},
+				{Name: "int", Kind: KindPrimitive},
+			},
+		}, "cb", []string{"func(arg0 string, arg1 int)", "cb.Invoke(arg0, arg1)"}},
+
+		{"returns bool", GoType{
+			Kind:            KindFunction,
+			CallbackParams:  []GoType{{Name: "string", Kind: KindPrimitive}},
+			CallbackResults: []GoType{{Name: "bool", Kind: KindPrimitive}},
+		}, "cb", []string{"func(arg0 string) bool {", "ret := cb.Invoke(arg0)", "return ret.Bool()"}},
+
+		{"node-style error and bytes", GoType{
+			Kind: KindFunction,
+			CallbackParams: []GoType{
+				{Name: "error", Kind: KindError, IsError: true},
+				{Name: "[]byte", Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}},
+			},
+		}, "cb", []string{"func(arg0 error, arg1 []byte)", "if arg0 == nil", "return arg0.Error()", "Uint8Array"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callbackWrapperCode(tt.goType, tt.argExpr, nil)
+			for _, substr := range tt.contains {
+				if !strings.Contains(result, substr) {
+					t.Errorf("callbackWrapperCode() = %q, should contain %q", result, substr)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkerCallbackCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		goType   GoType
+		argExpr  string
+		contains []string
+	}{
+		{"no params", GoType{
+			Kind:           KindFunction,
+			CallbackParams: []GoType{},
+		}, "cb", []string{"func()", "if !cb.Truthy() {\n\t\t\treturn", "invokeCallback", "cb.Int()"}},
+
+		{"one param", GoType{
+			Kind: KindFunction,
+			CallbackParams: []GoType{
+				{Name: "string", Kind: KindPrimitive},
+			},
+		}, "cb", []string{"func(arg0 string)", "cbArgs.Call(\"push\", arg0)", "invokeCallback"}},
+
+		{"two params", GoType{
+			Kind: KindFunction,
+			CallbackParams: []GoType{
+				{Name: "int", Kind: KindPrimitive},
+				{Name: "bool", Kind: KindPrimitive},
+			},
+		}, "cb", []string{"func(arg0 int, arg1 bool)", "cbArgs.Call(\"push\", arg0)", "cbArgs.Call(\"push\", arg1)"}},
+
+		{"node-style error and bytes", GoType{
+			Kind: KindFunction,
+			CallbackParams: []GoType{
+				{Name: "error", Kind: KindError, IsError: true},
+				{Name: "[]byte", Kind: KindSlice, Elem: &GoType{Name: "byte", Kind: KindPrimitive}},
+			},
+		}, "cb", []string{"func(arg0 error, arg1 []byte)", "if arg0 == nil", "invokeCallback"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := workerCallbackCode(tt.goType, tt.argExpr, nil)
+			for _, substr := range tt.contains {
+				if !strings.Contains(result, substr) {
+					t.Errorf("workerCallbackCode() = %q, should contain %q", result, substr)
+				}
+			}
+		})
+	}
+}
+
+func TestHasSelectInMain(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected bool
+	}{
+		{
+			name: "main with select {}",
+			src: `package main
+
+func main() {
+	// setup code
+	select {}
+}
+`,
+			expected: true,
+		},
+		{
+			name: "main without select",
+			src: `package main
+
+func main() {
+	println("hello")
+}
+`,
+			expected: false,
+		},
+		{
+			name: "no main function",
+			src: `package main
+
+func other() {
+	select {}
+}
+`,
+			expected: false,
+		},
+		{
+			name: "select in nested if",
+			src: `package main
+
+func main() {
+	if true {
+		select {}
+	}
+}
+`,
+			expected: true,
+		},
+		{
+			name: "select in for loop",
+			src: `package main
+
+func main() {
+	for {
+		select {}
+	}
+}
+`,
+			expected: true,
+		},
+		{
+			name: "select with cases (not empty)",
+			src: `package main
+
+func main() {
+	ch := make(chan int)
+	select {
+	case <-ch:
+	}
+}
+`,
+			expected: false,
+		},
+		{
+			name: "method named main ignored",
+			src: `package main
+
+type S struct{}
+
+func (s S) main() {
+	select {}
+}
+
+func main() {
+	println("no select")
+}
+`,
+			expected: false,
+		},
 		{
 			name: "select in switch case",
 			src: `package main
@@ -909,6 +2031,55 @@ func main() {
 `,
 			expected: true,
 		},
+		{
+			name: "receive from new channel",
+			src: `package main
+
+func main() {
+	<-make(chan struct{})
+}
+`,
+			expected: true,
+		},
+		{
+			name: "receive from channel variable",
+			src: `package main
+
+func main() {
+	done := make(chan bool)
+	<-done
+}
+`,
+			expected: true,
+		},
+		{
+			name: "receive assigned is not a statement",
+			src: `package main
+
+func main() {
+	ch := make(chan int, 1)
+	ch <- 1
+	v := <-ch
+	_ = v
+}
+`,
+			expected: false,
+		},
+		{
+			name: "blocking only in goroutine",
+			src: `package main
+
+func main() {
+	go func() {
+		select {}
+	}()
+	go func() {
+		<-make(chan struct{})
+	}()
+}
+`,
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -927,10 +2098,39 @@ func main() {
 			if result != tt.expected {
 				t.Errorf("HasSelectInMain() = %v, want %v", result, tt.expected)
 			}
+
+			result, err = HasSelectInMainSource(strings.NewReader(tt.src), "<stdin>")
+			if err != nil {
+				t.Fatalf("HasSelectInMainSource() error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("HasSelectInMainSource() = %v, want %v", result, tt.expected)
+			}
 		})
 	}
 }
 
+func TestParseSource(t *testing.T) {
+	src := `package main
+
+// Greet says hello
+func Greet(name string) string { return "Hello, " + name }
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "<stdin>")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+	if len(parsed.Functions) != 1 || parsed.Functions[0].Name != "Greet" || parsed.Functions[0].Doc != "Greet says hello" {
+		t.Errorf("ParseSource() functions = %+v, want documented Greet", parsed.Functions)
+	}
+
+	_, err = ParseSource(strings.NewReader("not go"), "<stdin>")
+	if err == nil || !strings.Contains(err.Error(), "failed to parse <stdin>") {
+		t.Errorf("ParseSource() error = %v, want parse error naming <stdin>", err)
+	}
+}
+
 func TestParseSourceFile_RecursiveStruct(t *testing.T) {
 	// Self-referential struct should be parsed without infinite loop
 	src := `package main
@@ -999,6 +2199,355 @@ func GetTree() *Tree {
 	if len(parsed.Functions) != 2 {
 		t.Errorf("got %d functions, want 2", len(parsed.Functions))
 	}
+
+	// Self-references are resolved by name and converted through helpers
+	if !nodeType.Recursive || !treeType.Recursive {
+		t.Errorf("Recursive = %v, %v, want true for Node and Tree", nodeType.Recursive, treeType.Recursive)
+	}
+	next := nodeType.Fields[1].Type
+	if next.Kind != KindPointer || next.Elem.Kind != KindStruct || next.Elem.Name != "Node" || !next.Elem.Recursive {
+		t.Errorf("Node.Next = %+v, want pointer to recursive Node", next)
+	}
+	if got := GoTypeToTS(*parsed.Functions[0].Returns[0].Elem); got != "Node" {
+		t.Errorf("GoTypeToTS(Node) = %q, want Node", got)
+	}
+	if got := GoFieldToTS(nodeType.Fields[1]); got != "Node | null" {
+		t.Errorf("GoFieldToTS(Next) = %q, want %q", got, "Node | null")
+	}
+	helpers := StructHelpers(*nodeType, false, nil)
+	for _, want := range []string{
+		"func gowasmNodeFromJS(v js.Value) Node {",
+		`elem := gowasmNodeFromJS(v.Get("Next"))`,
+		"func gowasmNodeToJS(v Node) interface{} {",
+		"return gowasmNodeToJS((*v.Next))",
+	} {
+		if !strings.Contains(helpers, want) {
+			t.Errorf("StructHelpers(Node) missing %q in:\n%s", want, helpers)
+		}
+	}
+}
+
+func TestParseSourceFile_TypeReferences(t *testing.T) {
+	// Types may use types declared after them, and the same type more than once
+	src := `package main
+
+type Line struct {
+	From Point
+	To   Point
+	Lead *Team
+}
+
+type Point struct {
+	X int
+}
+
+type Team struct {
+	Members []Point
+}
+
+func Draw(l Line) Line {
+	return l
+}
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "line.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+
+	line := parsed.Functions[0].Params[0].Type
+	for _, field := range line.Fields[:2] {
+		if field.Type.Kind != KindStruct || field.Type.Name != "Point" || len(field.Type.Fields) != 1 {
+			t.Errorf("Line.%s = %+v, want the Point struct", field.Name, field.Type)
+		}
+	}
+	lead := line.Fields[2].Type
+	if lead.Kind != KindPointer || lead.Elem.Kind != KindStruct || lead.Elem.Name != "Team" {
+		t.Errorf("Line.Lead = %+v, want pointer to Team", lead)
+	}
+	if line.Recursive || lead.Elem.Recursive {
+		t.Error("non-recursive structs should not be marked Recursive")
+	}
+}
+
+func TestParseSourceFile_NamedPrimitive(t *testing.T) {
+	src := `package main
+
+type Score int32
+
+func Scores() []Score {
+	return nil
+}
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "named.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+
+	elem := parsed.Functions[0].Returns[0].Elem
+	if elem == nil || elem.Name != "Score" || elem.Kind != KindPrimitive || elem.Underlying != "int32" {
+		t.Fatalf("[]Score element = %+v, want named primitive Score with underlying int32", elem)
+	}
+	if got := GoTypeToTS(parsed.Functions[0].Returns[0]); got != "Score[]" {
+		t.Errorf("GoTypeToTS([]Score) = %q, want %q", got, "Score[]")
+	}
+	if got := GoTypeToJSReturn(parsed.Functions[0].Returns[0], "result", nil); !strings.Contains(got, "Int32Array") {
+		t.Errorf("GoTypeToJSReturn([]Score) = %q, should use Int32Array", got)
+	}
+}
+
+func TestParseSource_EnumClosed(t *testing.T) {
+	src := `package main
+
+type Color int
+
+const (
+	Red Color = iota + 1
+	Green
+)
+
+type Perm uint8
+
+const (
+	Read Perm = 1 << iota
+	Write
+)
+
+type Celsius float64
+
+const Freezing Celsius = 0
+
+type Level int
+
+const Low Level = 1
+
+type Size string
+
+const (
+	Small Size = "s"
+	Large Size = "l"
+)
+
+type Mode string
+
+const Fast Mode = "f" + "ast"
+`
+
+	parsed, err := ParseSource(strings.NewReader(src), "enum.go")
+	if err != nil {
+		t.Fatalf("ParseSource() error: %v", err)
+	}
+	for name, want := range map[string]bool{
+		"Color":   true,
+		"Perm":    false,
+		"Celsius": false,
+		"Level":   false,
+		"Size":    true,
+		"Mode":    false,
+	} {
+		if got := parsed.Types[name].EnumClosed; got != want {
+			t.Errorf("%s: EnumClosed = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseSourceFile_EnumValues(t *testing.T) {
+	src := `package main
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	_
+	Blue
+)
+
+type Size string
+
+const Small = Size("s")
+const Large Size = "l"
+
+const (
+	Limit = 10
+	Other
+)
+
+func Paint(c Color, s Size) {}
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "enum.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+
+	params := parsed.Functions[0].Params
+	if got := strings.Join(params[0].Type.EnumValues, ","); got != "Red,Green,Blue" {
+		t.Errorf("Color enum values = %q, want %q", got, "Red,Green,Blue")
+	}
+	if got := strings.Join(params[1].Type.EnumValues, ","); got != "Small,Large" {
+		t.Errorf("Size enum values = %q, want %q", got, "Small,Large")
+	}
+	if got := strings.Join(params[1].Type.EnumLiterals, ","); got != "s,l" {
+		t.Errorf("Size enum literals = %q, want %q", got, "s,l")
+	}
+	if params[0].Type.EnumLiterals != nil {
+		t.Errorf("Color enum literals = %v, want nil for a non-string type", params[0].Type.EnumLiterals)
+	}
+}
+
+func TestParseSourceFile_EnumLiteralsNonLiteral(t *testing.T) {
+	src := `package main
+
+type Status string
+
+const prefix = "st-"
+
+const (
+	Active   Status = "active"
+	Computed Status = prefix + "x"
+)
+
+func Set(s Status) {}
+`
+
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "status.go")
+	if err := os.WriteFile(tmpFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	parsed, err := ParseSourceFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseSourceFile() error: %v", err)
+	}
+
+	typ := parsed.Functions[0].Params[0].Type
+	if got := strings.Join(typ.EnumValues, ","); got != "Active,Computed" {
+		t.Errorf("Status enum values = %q, want %q", got, "Active,Computed")
+	}
+	if typ.EnumLiterals != nil {
+		t.Errorf("Status enum literals = %v, want nil when a constant is not a literal", typ.EnumLiterals)
+	}
+}
+
+func TestParsePackageDir(t *testing.T) {
+	files := map[string]string{
+		"main.go": `package main
+
+func main() {
+	select {}
+}
+
+func Greet(u User) string { return u.Name }
+`,
+		"types.go": `package main
+
+type User struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+`,
+		"paint.go":         "package main\n\nfunc Paint(c Color) {}\n",
+		"paint_test.go":    "package main\n\nfunc TestOnly() {}\n",
+		"bindings_gen.go":  "package main\n\nfunc Generated() {}\n",
+		"wasm_bindings.go": "//go:build js && wasm\n\n" + generatedHeader + "\n\npackage main\n\nfunc Renamed() {}\n",
+		"notes.txt":        "not Go",
+		"zz_other_test.go": "package main_test\n",
+	}
+
+	tmpDir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	parsed, err := ParsePackageDir(tmpDir)
+	if err != nil {
+		t.Fatalf("ParsePackageDir() error: %v", err)
+	}
+
+	if parsed.Package != "main" {
+		t.Errorf("Package = %q, want %q", parsed.Package, "main")
+	}
+
+	// Functions follow file name order: main.go, then paint.go
+	var names []string
+	for _, fn := range parsed.Functions {
+		names = append(names, fn.Name)
+	}
+	if got := strings.Join(names, ","); got != "Greet,Paint" {
+		t.Errorf("functions = %q, want %q", got, "Greet,Paint")
+	}
+
+	// Types declared in a sibling file resolve in signatures
+	if got := parsed.Functions[0].Params[0].Type; got.Kind != KindStruct || len(got.Fields) != 1 {
+		t.Errorf("Greet param type = %+v, want User struct", got)
+	}
+	if got := strings.Join(parsed.Functions[1].Params[0].Type.EnumValues, ","); got != "Red,Green" {
+		t.Errorf("Color enum values = %q, want %q", got, "Red,Green")
+	}
+
+	// Positions name the file each function was declared in
+	if got, want := parsed.Functions[1].Pos, filepath.Join(tmpDir, "paint.go")+":3"; got != want {
+		t.Errorf("Paint position = %q, want %q", got, want)
+	}
+}
+
+func TestParsePackageDir_Errors(t *testing.T) {
+	t.Run("empty directory", func(t *testing.T) {
+		_, err := ParsePackageDir(t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), "no Go source files") {
+			t.Errorf("ParsePackageDir() error = %v, want no Go source files", err)
+		}
+	})
+
+	t.Run("multiple packages", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for name, src := range map[string]string{
+			"a.go": "package a\n",
+			"b.go": "package b\n",
+		} {
+			if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0600); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+		_, err := ParsePackageDir(tmpDir)
+		if err == nil || !strings.Contains(err.Error(), "found: a, b") {
+			t.Errorf("ParsePackageDir() error = %v, want multiple package error", err)
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tmpDir, "bad.go"), []byte("package main\n\nfunc {\n"), 0600); err != nil {
+			t.Fatalf("failed to write bad.go: %v", err)
+		}
+		_, err := ParsePackageDir(tmpDir)
+		if err == nil || !strings.Contains(err.Error(), "failed to parse "+filepath.Join(tmpDir, "bad.go")) {
+			t.Errorf("ParsePackageDir() error = %v, want parse error naming bad.go", err)
+		}
+	})
 }
 
 func TestParseSourceFile_AnonymousField(t *testing.T) {
@@ -1098,7 +2647,7 @@ func TestPrimitiveToTS_AllTypes(t *testing.T) {
 		{"float64", "number"},
 		{"bool", "boolean"},
 		{"byte", "number"},
-		{"rune", "number"},
+		{"rune", "string"},
 		{"unknown", "any"},
 	}
 
@@ -5,52 +5,124 @@ import (
 	"strings"
 )
 
+// jsReservedWords can't name a binding in JavaScript modules or classes,
+// which are strict mode code: a //gowasm:name function in the ambient
+// declarations of --dts-only, or a parameter in the TypeScript client.
+var jsReservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"implements": true, "interface": true, "let": true, "package": true, "private": true,
+	"protected": true, "public": true, "static": true, "yield": true, "await": true,
+	"arguments": true, "eval": true,
+}
+
+// IsJSReservedWord reports whether name can't be used as a binding name in
+// strict mode JavaScript.
+func IsJSReservedWord(name string) bool {
+	return jsReservedWords[name]
+}
+
+// GoParamToTS renders a parameter declaration, using rest syntax for a
+// variadic parameter. Rest parameters must be plain arrays, so the element
+// type is never mapped to a typed array.
+func GoParamToTS(p GoParameter) string {
+	if p.IsVariadic && p.Type.Elem != nil {
+		return "..." + p.Name + ": " + GoTypeToTS(*p.Type.Elem) + "[]"
+	}
+	return p.Name + ": " + GoTypeToTS(p.Type)
+}
+
 // GoTypeToTS converts a GoType to TypeScript type string
 func GoTypeToTS(t GoType) string {
 	switch t.Kind {
 	case KindPrimitive:
+		if t.Underlying != "" {
+			// Named primitives are emitted as branded type aliases
+			return t.Name
+		}
+		if t.BigInt {
+			return "bigint"
+		}
+		if t.JSON && t.Name == "rune" {
+			// encoding/json writes a rune as its code point
+			return "number"
+		}
 		return primitiveToTS(t.Name)
 
 	case KindSlice, KindArray:
-		if t.Elem != nil && t.Elem.Kind == KindPrimitive {
+		if t.Nullable {
+			t.Nullable = false
+			return GoTypeToTS(t) + " | null"
+		}
+		if t.ByteOrder != "" {
+			t.ByteOrder = ""
+			return GoTypeToTS(t) + " | DataView"
+		}
+		if t.Base64 || (IsRuneSlice(t) && !t.JSON) {
+			return "string"
+		}
+		if t.Elem != nil && t.Elem.Kind == KindPrimitive && t.Elem.Underlying == "" && !t.PlainArray {
 			if tsType := goElemToTypedArray(t.Elem.Name); tsType != "" {
 				return tsType
 			}
 		}
+		if t.Elem != nil && t.Elem.Nullable {
+			return "(" + GoTypeToTS(*t.Elem) + ")[]"
+		}
+		if t.Elem != nil && t.Elem.Kind == KindPointer {
+			// Nil elements cross the boundary as null
+			return "(" + GoTypeToTS(*t.Elem) + " | null)[]"
+		}
 		if t.Elem != nil {
 			return GoTypeToTS(*t.Elem) + "[]"
 		}
 		return "any[]"
 
 	case KindMap:
+		if t.Nullable {
+			t.Nullable = false
+			return GoTypeToTS(t) + " | null"
+		}
 		if t.Key != nil && t.Value != nil {
-			keyType := GoTypeToTS(*t.Key)
-			valueType := GoTypeToTS(*t.Value)
-			if keyType == "string" {
-				return fmt.Sprintf("{[key: string]: %s}", valueType)
-			}
-			return "Record<" + keyType + ", " + valueType + ">"
+			return mapToTS(GoTypeToTS(*t.Key), GoTypeToTS(*t.Value))
 		}
 		return "any"
 
 	case KindStruct:
-		// Generate inline interface
-		if len(t.Fields) == 0 {
-			return "any"
+		// Named structs are emitted as interfaces by the generator
+		if t.Named {
+			return t.Name
 		}
+		// Generate inline interface
 		var b strings.Builder
 		b.WriteString("{")
-		for i, field := range t.Fields {
-			if i > 0 {
+		for _, field := range t.Fields {
+			if field.Skip {
+				continue
+			}
+			if b.Len() > 1 {
 				b.WriteString(", ")
 			}
-			fieldName := field.JSONTag
+			fieldName := field.TSName
+			if fieldName == "" {
+				fieldName = field.JSONTag
+			}
 			if fieldName == "" {
 				fieldName = field.Name
 			}
 			b.WriteString(fieldName)
+			if IsOptionalField(field) {
+				b.WriteString("?")
+			}
 			b.WriteString(": ")
-			b.WriteString(GoTypeToTS(field.Type))
+			b.WriteString(GoFieldToTS(field))
+		}
+		if b.Len() == 1 {
+			return "any"
 		}
 		b.WriteString("}")
 		return b.String()
@@ -64,28 +136,159 @@ func GoTypeToTS(t GoType) string {
 	case KindError:
 		return "string"
 
+	case KindTime:
+		if t.JSON {
+			// encoding/json writes RFC 3339 text
+			return "string"
+		}
+		return "Date"
+
+	case KindAny:
+		return "unknown"
+
 	case KindFunction:
 		// Generate TypeScript callback type: (arg0: T, arg1: U) => void
+		// Named Go params keep their names, e.g. (err: string | null, data: Uint8Array) => void
 		var params []string
 		for i, p := range t.CallbackParams {
-			params = append(params, fmt.Sprintf("arg%d: %s", i, GoTypeToTS(p)))
+			name := fmt.Sprintf("arg%d", i)
+			if i < len(t.CallbackNames) && t.CallbackNames[i] != "_" {
+				name = t.CallbackNames[i]
+			}
+			params = append(params, name+": "+callbackParamToTS(p))
 		}
-		return "(" + strings.Join(params, ", ") + ") => void"
+		result := "void"
+		if len(t.CallbackResults) == 1 {
+			result = GoTypeToTS(t.CallbackResults[0])
+		}
+		return "(" + strings.Join(params, ", ") + ") => " + result
 
 	default:
 		return "any"
 	}
 }
 
+// mapToTS formats a map type from its TypeScript key and value types.
+func mapToTS(keyType, valueType string) string {
+	if keyType == "string" {
+		return fmt.Sprintf("{[key: string]: %s}", valueType)
+	}
+	if keyType == "boolean" {
+		// Record keys must be strings or numbers; JS stringifies bool keys
+		return "Partial<Record<'true' | 'false', " + valueType + ">>"
+	}
+	return "Record<" + keyType + ", " + valueType + ">"
+}
+
+// GoTypeToReadonlyTS is GoTypeToTS for function results marked with
+// UseReadonlyReturns. Arrays become ReadonlyArray and maps, structs, and
+// typed arrays are wrapped in Readonly, recursing into array elements and
+// map values. Named interfaces are wrapped as a whole, so Readonly only
+// applies to their own fields.
+func GoTypeToReadonlyTS(t GoType) string {
+	if t.Nullable {
+		t.Nullable = false
+		return GoTypeToReadonlyTS(t) + " | null"
+	}
+	tsType := GoTypeToTS(t)
+	switch t.Kind {
+	case KindSlice, KindArray:
+		if t.Base64 || (IsRuneSlice(t) && !t.JSON) {
+			return tsType
+		}
+		if t.Elem != nil && t.Elem.Kind == KindPointer {
+			return "ReadonlyArray<" + GoTypeToReadonlyTS(*t.Elem) + " | null>"
+		}
+		if t.Elem != nil && strings.HasSuffix(tsType, "[]") {
+			return "ReadonlyArray<" + GoTypeToReadonlyTS(*t.Elem) + ">"
+		}
+		return "Readonly<" + tsType + ">"
+	case KindMap:
+		if t.Key != nil && t.Value != nil {
+			return "Readonly<" + mapToTS(GoTypeToTS(*t.Key), GoTypeToReadonlyTS(*t.Value)) + ">"
+		}
+	case KindStruct:
+		if tsType != "any" {
+			return "Readonly<" + tsType + ">"
+		}
+	case KindPointer:
+		if t.Elem != nil {
+			return GoTypeToReadonlyTS(*t.Elem)
+		}
+	}
+	return tsType
+}
+
+// IsTSAny reports whether GoTypeToTS renders t as TypeScript any. Only t
+// itself is checked, not the element, key, value, or field types inside it.
+func IsTSAny(t GoType) bool {
+	switch t.Kind {
+	case KindPrimitive:
+		return t.Underlying == "" && !t.BigInt && primitiveToTS(t.Name) == "any"
+	case KindSlice, KindArray:
+		return !t.Base64 && t.Elem == nil
+	case KindMap:
+		return t.Key == nil || t.Value == nil
+	case KindStruct:
+		if t.Named {
+			return false
+		}
+		for _, field := range t.Fields {
+			if !field.Skip {
+				return false
+			}
+		}
+		return true
+	case KindPointer:
+		return t.Elem == nil
+	case KindError, KindTime, KindAny, KindFunction:
+		return false
+	default:
+		return true
+	}
+}
+
+// callbackParamToTS converts a callback parameter type to TypeScript.
+// Error params follow the Node-style convention of null on success.
+func callbackParamToTS(t GoType) string {
+	if t.Kind == KindError {
+		return "string | null"
+	}
+	return GoTypeToTS(t)
+}
+
+// GoFieldToTS converts a struct field's type to TypeScript.
+// Primitive fields tagged with the JSON ",string" option are encoded as strings.
+// Pointer and error fields are nullable since a nil value crosses as null.
+func GoFieldToTS(field GoField) string {
+	if isStringTagged(field) {
+		return "string"
+	}
+	if field.Type.JSON && field.Type.Kind == KindError {
+		// encoding/json encodes whatever the error value holds
+		return "unknown"
+	}
+	if field.Type.Kind == KindPointer || field.Type.Kind == KindError {
+		return GoTypeToTS(field.Type) + " | null"
+	}
+	return GoTypeToTS(field.Type)
+}
+
+// isStringTagged returns true if the field is a primitive with the JSON ",string" option.
+func isStringTagged(field GoField) bool {
+	return field.JSONString && field.Type.Kind == KindPrimitive
+}
+
 // primitiveToTS converts Go primitive type names to TypeScript
 func primitiveToTS(name string) string {
 	switch name {
-	case "string":
+	case "string", "rune":
+		// A rune crosses as a single-character string
 		return "string"
 	case "int", "int8", "int16", "int32", "int64",
 		"uint", "uint8", "uint16", "uint32", "uint64",
 		"float32", "float64",
-		"byte", "rune":
+		"byte":
 		return "number"
 	case "bool":
 		return "boolean"
@@ -118,42 +321,108 @@ func goElemToTypedArray(elemName string) string {
 	return ""
 }
 
-// isByteSlice returns true if the type is []byte or []uint8.
-func isByteSlice(t GoType) bool {
+// primitiveName returns the builtin primitive name for t, resolving named
+// primitive types (e.g., type Score int32) to their underlying type.
+func primitiveName(t GoType) string {
+	if t.Underlying != "" {
+		return t.Underlying
+	}
+	return t.Name
+}
+
+// IsByteSlice returns true if the type is []byte or []uint8.
+func IsByteSlice(t GoType) bool {
 	if t.Kind != KindSlice || t.Elem == nil {
 		return false
 	}
 	return t.Elem.Kind == KindPrimitive && (t.Elem.Name == "byte" || t.Elem.Name == "uint8")
 }
 
+// IsRuneSlice returns true if the type is []rune, which crosses the JS
+// boundary as a string rather than an array of one-character strings.
+func IsRuneSlice(t GoType) bool {
+	return t.Kind == KindSlice && t.Elem != nil && t.Elem.Kind == KindPrimitive && t.Elem.Name == "rune"
+}
+
+// Imports records the packages that generated Go code refers to, besides fmt
+// and syscall/js, which the bindings always import. The code builders below
+// add each package as they emit a reference to it. A nil Imports records
+// nothing.
+type Imports map[string]bool
+
+// Add records that the generated code refers to the package at path.
+func (imports Imports) Add(path string) {
+	if imports != nil {
+		imports[path] = true
+	}
+}
+
+// addTypeRefs records the packages that the Go type name of t refers to, for
+// code that spells the type out rather than only converting its values.
+func (imports Imports) addTypeRefs(t GoType) {
+	if t.Kind == KindTime {
+		imports.Add("time")
+	}
+	for _, elem := range []*GoType{t.Elem, t.Key, t.Value} {
+		if elem != nil {
+			imports.addTypeRefs(*elem)
+		}
+	}
+	for _, param := range t.CallbackParams {
+		imports.addTypeRefs(param)
+	}
+	for _, result := range t.CallbackResults {
+		imports.addTypeRefs(result)
+	}
+}
+
 // GoTypeToJSExtraction generates JavaScript code to extract a value from js.Value
 // argExpr is the expression representing the js.Value argument (e.g., "args[0]")
 // workerMode determines whether to generate worker-compatible callback code
-func GoTypeToJSExtraction(t GoType, argExpr string, workerMode bool) string {
+func GoTypeToJSExtraction(t GoType, argExpr string, workerMode bool, imports Imports) string {
 	switch t.Kind {
 	case KindPrimitive:
+		if t.BigInt {
+			// js.Value has no bigint accessor and can't call methods on one,
+			// so have JS's String() produce the decimal digits
+			return parsePrimitive(t.Name, primitiveName(t), `js.Global().Call("String", `+argExpr+`).String()`, imports)
+		}
+		if t.Underlying != "" {
+			return t.Name + "(" + primitiveExtraction(t.Underlying, argExpr) + ")"
+		}
 		return primitiveExtraction(t.Name, argExpr)
 
 	case KindSlice, KindArray:
-		return sliceExtraction(t, argExpr, workerMode)
+		return sliceExtraction(t, argExpr, workerMode, imports)
 
 	case KindMap:
-		return mapExtraction(t, argExpr, workerMode)
+		return mapExtraction(t, argExpr, workerMode, imports)
 
 	case KindStruct:
-		return structExtraction(t, argExpr, workerMode)
+		return structExtraction(t, argExpr, workerMode, imports)
 
 	case KindPointer:
 		if t.Elem != nil {
-			return GoTypeToJSExtraction(*t.Elem, argExpr, workerMode)
+			return pointerExtraction(t, argExpr, workerMode, imports)
 		}
 		return argExpr
 
+	case KindTime:
+		// Millisecond precision, matching what a JS Date can represent
+		imports.Add("time")
+		return "time.UnixMilli(int64(" + argExpr + `.Call("getTime").Float()))`
+
+	case KindAny:
+		// Round-trips through JSON (see AnyDecoder) so the Go side sees what
+		// encoding/json would decode: float64, string, bool, nil, []interface{},
+		// or map[string]interface{}
+		return AnyDecoder + "(" + argExpr + ")"
+
 	case KindFunction:
 		if workerMode {
-			return workerCallbackCode(t, argExpr)
+			return workerCallbackCode(t, argExpr, imports)
 		}
-		return callbackWrapperCode(t, argExpr)
+		return callbackWrapperCode(t, argExpr, imports)
 
 	default:
 		return argExpr
@@ -191,38 +460,66 @@ func primitiveExtraction(typeName, argExpr string) string {
 		return "float32(" + argExpr + ".Float())"
 	case "bool":
 		return argExpr + ".Bool()"
+	case "rune":
+		// The first character of the string; an empty string panics, which
+		// the wrapper's recover turns into an error
+		return "[]rune(" + argExpr + ".String())[0]"
 	default:
 		return argExpr
 	}
 }
 
 // sliceExtraction generates extraction code for slices
-func sliceExtraction(t GoType, argExpr string, workerMode bool) string {
+func sliceExtraction(t GoType, argExpr string, workerMode bool, imports Imports) string {
 	if t.Elem == nil {
 		return "nil"
 	}
+	if t.Nullable {
+		t.Nullable = false
+		return nullToNil(t.Name, argExpr, sliceExtraction(t, argExpr, workerMode, imports))
+	}
+
+	if t.Base64 {
+		return base64Extraction(argExpr, imports)
+	}
 
 	// Use js.CopyBytesToGo for byte slices (efficient bulk copy)
-	if isByteSlice(t) {
+	if IsByteSlice(t) {
 		return byteSliceExtraction(argExpr)
 	}
 
-	// Element-by-element extraction for other types
+	if IsRuneSlice(t) {
+		return "[]rune(" + argExpr + ".String())"
+	}
+
+	// Element-by-element extraction for other types. argExpr is read into
+	// arr before the loop: it may refer to the loop variable of an enclosing
+	// slice (e.g., [][]int), which i would shadow.
 	elemType := t.Elem
 	var b strings.Builder
 
 	b.WriteString("func() []")
 	b.WriteString(elemType.Name)
 	b.WriteString(" {\n")
-	b.WriteString("\t\tlength := ")
+	b.WriteString("\t\tarr := ")
 	b.WriteString(argExpr)
-	b.WriteString(".Length()\n")
+	b.WriteString("\n")
+	if t.ByteOrder != "" {
+		// Before reading length, which a DataView doesn't have
+		b.WriteString(typedArrayBytes(*elemType, t.ByteOrder, imports))
+	}
+	b.WriteString("\t\tlength := arr.Length()\n")
 	b.WriteString("\t\tresult := make([]")
 	b.WriteString(elemType.Name)
 	b.WriteString(", length)\n")
+	if elemType.Kind == KindPrimitive && t.ByteOrder == "" {
+		if jsTypedArray := goElemToTypedArray(primitiveName(*elemType)); jsTypedArray != "" {
+			b.WriteString(typedArrayCopy(jsTypedArray, imports))
+		}
+	}
 	b.WriteString("\t\tfor i := 0; i < length; i++ {\n")
 	b.WriteString("\t\t\tresult[i] = ")
-	b.WriteString(GoTypeToJSExtraction(*elemType, argExpr+".Index(i)", workerMode))
+	b.WriteString(GoTypeToJSExtraction(*elemType, "arr.Index(i)", workerMode, imports))
 	b.WriteString("\n\t\t}\n")
 	b.WriteString("\t\treturn result\n")
 	b.WriteString("\t}()")
@@ -230,6 +527,30 @@ func sliceExtraction(t GoType, argExpr string, workerMode bool) string {
 	return b.String()
 }
 
+// GoTypeToJSVariadicExtraction generates code collecting args[start:] into
+// the slice type of a variadic parameter, converting each argument in turn.
+func GoTypeToJSVariadicExtraction(t GoType, start int, workerMode bool, imports Imports) string {
+	if t.Elem == nil {
+		return "nil"
+	}
+
+	var b strings.Builder
+	b.WriteString("func() []")
+	b.WriteString(t.Elem.Name)
+	b.WriteString(" {\n")
+	b.WriteString("\t\tvar result []")
+	b.WriteString(t.Elem.Name)
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "\t\tfor i := %d; i < len(args); i++ {\n", start)
+	b.WriteString("\t\t\tresult = append(result, ")
+	b.WriteString(GoTypeToJSExtraction(*t.Elem, "args[i]", workerMode, imports))
+	b.WriteString(")\n\t\t}\n")
+	b.WriteString("\t\treturn result\n")
+	b.WriteString("\t}()")
+
+	return b.String()
+}
+
 // byteSliceExtraction generates extraction code for byte slices using js.CopyBytesToGo.
 // This is ~10-100x faster than element-by-element extraction for large arrays.
 func byteSliceExtraction(argExpr string) string {
@@ -241,31 +562,115 @@ func byteSliceExtraction(argExpr string) string {
 	}()`
 }
 
+// typedArrayCopy generates the fast path of sliceExtraction for numeric
+// slices: when the argument is a typed array of the matching element type,
+// its bytes are copied straight into result's backing array with
+// js.CopyBytesToGo, as for byte slices. Both sides are little-endian with the
+// same element size, so the bytes need no conversion. Plain arrays fall
+// through to the element-by-element loop.
+func typedArrayCopy(jsTypedArray string, imports Imports) string {
+	imports.Add("unsafe")
+	return `		if length > 0 && arr.InstanceOf(js.Global().Get("` + jsTypedArray + `")) {
+			dst := unsafe.Slice((*byte)(unsafe.Pointer(&result[0])), length*int(unsafe.Sizeof(result[0])))
+			js.CopyBytesToGo(dst, js.Global().Get("Uint8Array").New(arr.Get("buffer"), arr.Get("byteOffset"), arr.Get("byteLength")))
+			return result
+		}
+`
+}
+
+// typedArrayBytes generates the path of sliceExtraction for parameters
+// marked with GoType.ByteOrder: when the argument is any typed array or
+// DataView, its bytes are copied with js.CopyBytesToGo through a Uint8Array
+// view and decoded element by element with encoding/binary in byteOrder.
+// A byte length that doesn't divide into whole elements panics, which the
+// wrapper's recover turns into an error. Plain arrays fall through to the
+// element-by-element loop.
+func typedArrayBytes(elem GoType, byteOrder string, imports Imports) string {
+	imports.Add("encoding/binary")
+	order := "binary.LittleEndian"
+	if byteOrder == "big" {
+		order = "binary.BigEndian"
+	}
+
+	var size int
+	var decode string
+	switch primitiveName(elem) {
+	case "int8":
+		size, decode = 1, "raw[i]"
+	case "int16", "uint16":
+		size, decode = 2, order+".Uint16(raw[i*2:])"
+	case "int32", "uint32":
+		size, decode = 4, order+".Uint32(raw[i*4:])"
+	case "float32":
+		size, decode = 4, "math.Float32frombits("+order+".Uint32(raw[i*4:]))"
+		imports.Add("math")
+	case "float64":
+		size, decode = 8, "math.Float64frombits("+order+".Uint64(raw[i*8:]))"
+		imports.Add("math")
+	}
+
+	return fmt.Sprintf(`		if js.Global().Get("ArrayBuffer").Call("isView", arr).Bool() {
+			raw := make([]byte, arr.Get("byteLength").Int())
+			js.CopyBytesToGo(raw, js.Global().Get("Uint8Array").New(arr.Get("buffer"), arr.Get("byteOffset"), arr.Get("byteLength")))
+			if len(raw)%%%[1]d != 0 {
+				panic(fmt.Sprintf("%%d bytes is not a whole number of %[2]s values", len(raw)))
+			}
+			result := make([]%[3]s, len(raw)/%[1]d)
+			for i := range result {
+				result[i] = %[3]s(%[4]s)
+			}
+			return result
+		}
+`, size, primitiveName(elem), elem.Name, decode)
+}
+
+// base64Extraction generates extraction code decoding a base64 string into a
+// byte slice. Invalid input panics, which the wrapper's recover turns into an
+// error.
+func base64Extraction(argExpr string, imports Imports) string {
+	imports.Add("encoding/base64")
+	return `func() []byte {
+		b, err := base64.StdEncoding.DecodeString(` + argExpr + `.String())
+		if err != nil {
+			panic(err)
+		}
+		return b
+	}()`
+}
+
 // mapExtraction generates extraction code for maps
-func mapExtraction(t GoType, argExpr string, workerMode bool) string {
+func mapExtraction(t GoType, argExpr string, workerMode bool, imports Imports) string {
 	if t.Key == nil || t.Value == nil {
 		return "nil"
 	}
-
-	// For now, only support map[string]T
-	if t.Key.Name != "string" {
-		return "nil"
+	if t.Nullable {
+		t.Nullable = false
+		return nullToNil(t.Name, argExpr, mapExtraction(t, argExpr, workerMode, imports))
 	}
 
+	// JS object keys are always strings; parse them back into the Go key
+	// type. argExpr is read into obj before the loop, as in sliceExtraction.
 	var b strings.Builder
-	b.WriteString("func() map[string]")
+	b.WriteString("func() map[")
+	b.WriteString(t.Key.Name)
+	b.WriteString("]")
 	b.WriteString(t.Value.Name)
 	b.WriteString(" {\n")
-	b.WriteString("\t\tresult := make(map[string]")
+	b.WriteString("\t\tresult := make(map[")
+	b.WriteString(t.Key.Name)
+	b.WriteString("]")
 	b.WriteString(t.Value.Name)
 	b.WriteString(")\n")
-	b.WriteString("\t\tkeys := js.Global().Get(\"Object\").Call(\"keys\", ")
+	b.WriteString("\t\tobj := ")
 	b.WriteString(argExpr)
-	b.WriteString(")\n")
+	b.WriteString("\n")
+	b.WriteString("\t\tkeys := js.Global().Get(\"Object\").Call(\"keys\", obj)\n")
 	b.WriteString("\t\tfor i := 0; i < keys.Length(); i++ {\n")
 	b.WriteString("\t\t\tkey := keys.Index(i).String()\n")
-	b.WriteString("\t\t\tresult[key] = ")
-	b.WriteString(GoTypeToJSExtraction(*t.Value, argExpr+".Get(key)", workerMode))
+	b.WriteString("\t\t\tresult[")
+	b.WriteString(parsePrimitive(t.Key.Name, primitiveName(*t.Key), "key", imports))
+	b.WriteString("] = ")
+	b.WriteString(GoTypeToJSExtraction(*t.Value, "obj.Get(key)", workerMode, imports))
 	b.WriteString("\n\t\t}\n")
 	b.WriteString("\t\treturn result\n")
 	b.WriteString("\t}()")
@@ -273,52 +678,225 @@ func mapExtraction(t GoType, argExpr string, workerMode bool) string {
 	return b.String()
 }
 
-// structExtraction generates extraction code for structs
-func structExtraction(t GoType, argExpr string, workerMode bool) string {
-	var b strings.Builder
+// pointerExtraction generates extraction code for pointers. JS null and
+// undefined become a nil pointer; other values are extracted as the element
+// type and their address taken.
+func pointerExtraction(t GoType, argExpr string, workerMode bool, imports Imports) string {
+	return "func() " + t.Name + " {\n" +
+		"\t\tif " + argExpr + ".IsNull() || " + argExpr + ".IsUndefined() {\n" +
+		"\t\t\treturn nil\n" +
+		"\t\t}\n" +
+		"\t\telem := " + GoTypeToJSExtraction(*t.Elem, argExpr, workerMode, imports) + "\n" +
+		"\t\treturn &elem\n" +
+		"\t}()"
+}
 
-	b.WriteString("func() ")
-	b.WriteString(t.Name)
-	b.WriteString(" {\n")
-	b.WriteString("\t\treturn ")
-	b.WriteString(t.Name)
-	b.WriteString("{\n")
+// StructHelpers generates the conversion functions that recursive structs
+// (see GoType.Recursive) are extracted and returned through, since their
+// conversion code can't be inlined. Structs marked with GoType.Helpers use
+// them too.
+func StructHelpers(t GoType, workerMode bool, imports Imports) string {
+	t.Recursive, t.Helpers = false, false
+	return "func " + structFromJS(t.Name) + "(v js.Value) " + t.Name + " {\n" +
+		"\treturn " + structExtraction(t, "v", workerMode, imports) + "\n" +
+		"}\n\n" +
+		"func " + structToJS(t.Name) + "(v " + t.Name + ") interface{} {\n" +
+		"\treturn " + structReturn(t, "v", imports) + "\n" +
+		"}\n"
+}
+
+// structFromJS and structToJS name the helper functions of a struct.
+func structFromJS(name string) string { return "gowasm" + name + "FromJS" }
+func structToJS(name string) string   { return "gowasm" + name + "ToJS" }
 
+// AnyDecoder and AnyEncoder name the helper functions that convert interface{}
+// values from and to JS through JSON. The generator emits them when used.
+// Structs marked with GoType.JSON are returned through AnyEncoder too, and
+// extracted through JSONDecoder, which decodes into a value of their type.
+const (
+	AnyDecoder  = "gowasmDecodeAny"
+	AnyEncoder  = "gowasmEncodeAny"
+	JSONDecoder = "gowasmDecodeJSON"
+)
+
+// structExtraction generates extraction code for structs. Optional fields
+// (see IsOptionalField) keep their zero value when the key is absent.
+func structExtraction(t GoType, argExpr string, workerMode bool, imports Imports) string {
+	if t.JSON {
+		return "func() " + t.Name + " {\n" +
+			"\t\tvar out " + t.Name + "\n" +
+			"\t\t" + JSONDecoder + "(" + argExpr + ", &out)\n" +
+			"\t\treturn out\n" +
+			"\t}()"
+	}
+	if t.Recursive || t.Helpers {
+		return structFromJS(t.Name) + "(" + argExpr + ")"
+	}
+
+	var fields, optional strings.Builder
 	for _, field := range t.Fields {
+		if field.Skip {
+			// Left at its zero value, as encoding/json does
+			continue
+		}
 		fieldKey := field.JSONTag
 		if fieldKey == "" {
 			fieldKey = field.Name
 		}
 
-		b.WriteString("\t\t\t")
-		b.WriteString(field.Name)
-		b.WriteString(": ")
-		b.WriteString(GoTypeToJSExtraction(field.Type, argExpr+".Get(\""+fieldKey+"\")", workerMode))
-		b.WriteString(",\n")
+		fieldExpr := argExpr + ".Get(\"" + fieldKey + "\")"
+		var value string
+		if isStringTagged(field) {
+			value = stringTagExtraction(field.Type, fieldExpr, imports)
+		} else if field.Type.Kind == KindError {
+			value = errorFromJS(fieldExpr, imports)
+		} else {
+			value = GoTypeToJSExtraction(field.Type, fieldExpr, workerMode, imports)
+		}
+		if IsOptionalField(field) {
+			optional.WriteString("\t\tif !" + fieldExpr + ".IsUndefined() {\n")
+			optional.WriteString("\t\t\tout." + field.Name + " = " + value + "\n")
+			optional.WriteString("\t\t}\n")
+			continue
+		}
+		fields.WriteString("\t\t\t" + field.Name + ": " + value + ",\n")
 	}
 
-	b.WriteString("\t\t}\n")
+	var b strings.Builder
+	b.WriteString("func() ")
+	b.WriteString(t.Name)
+	b.WriteString(" {\n")
+	if optional.Len() == 0 {
+		b.WriteString("\t\treturn ")
+		b.WriteString(t.Name)
+		b.WriteString("{\n")
+		b.WriteString(fields.String())
+		b.WriteString("\t\t}\n")
+	} else {
+		b.WriteString("\t\tout := ")
+		b.WriteString(t.Name)
+		b.WriteString("{\n")
+		b.WriteString(fields.String())
+		b.WriteString("\t\t}\n")
+		b.WriteString(optional.String())
+		b.WriteString("\t\treturn out\n")
+	}
 	b.WriteString("\t}()")
 
 	return b.String()
 }
 
+// IsOptionalField reports whether a struct field may be absent on the JS
+// side: it is tagged omitempty and has a type encoding/json considers empty
+// at its zero value. Struct and time.Time fields are never omitted.
+func IsOptionalField(field GoField) bool {
+	return field.OmitEmpty && !field.Skip && nonEmptyCheck(field.Type, "v") != ""
+}
+
+// nonEmptyCheck returns a Go condition that is true when valueExpr is not
+// empty in the encoding/json omitempty sense, or "" for types that are never
+// empty.
+func nonEmptyCheck(t GoType, valueExpr string) string {
+	switch t.Kind {
+	case KindPrimitive:
+		switch primitiveName(t) {
+		case "string":
+			return valueExpr + ` != ""`
+		case "bool":
+			return valueExpr
+		default:
+			return valueExpr + " != 0"
+		}
+	case KindSlice, KindArray, KindMap:
+		return "len(" + valueExpr + ") != 0"
+	case KindPointer, KindFunction, KindAny, KindError:
+		return valueExpr + " != nil"
+	default:
+		return ""
+	}
+}
+
+// stringTagExtraction generates extraction code for a primitive struct field
+// tagged with the JSON ",string" option, parsing the value from a JS string.
+// Named primitives are parsed as their builtin primitive and converted.
+// Unparseable input panics, which the wrapper's recover turns into an error.
+func stringTagExtraction(t GoType, argExpr string, imports Imports) string {
+	return parsePrimitive(t.Name, primitiveName(t), argExpr+".String()", imports)
+}
+
+// parsePrimitive generates code converting the Go string expression strExpr
+// to typeName, whose builtin primitive is primitive, using strconv.
+// Unparseable input panics, which the wrapper's recover turns into an error.
+func parsePrimitive(typeName, primitive, strExpr string, imports Imports) string {
+	var parse string
+	switch primitive {
+	case "rune":
+		if typeName != "rune" {
+			return typeName + "([]rune(" + strExpr + ")[0])"
+		}
+		return "[]rune(" + strExpr + ")[0]"
+	case "int", "int8", "int16", "int32", "int64":
+		parse = "strconv.ParseInt(" + strExpr + ", 10, 64)"
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		parse = "strconv.ParseUint(" + strExpr + ", 10, 64)"
+	case "float32", "float64":
+		parse = "strconv.ParseFloat(" + strExpr + ", 64)"
+	case "bool":
+		parse = "strconv.ParseBool(" + strExpr + ")"
+	default:
+		if typeName != "string" {
+			return typeName + "(" + strExpr + ")"
+		}
+		return strExpr
+	}
+
+	imports.Add("strconv")
+	return `func() ` + typeName + ` {
+		v, err := ` + parse + `
+		if err != nil {
+			panic(err)
+		}
+		return ` + typeName + `(v)
+	}()`
+}
+
 // callbackWrapperCode generates sync-mode callback wrapper (direct JS function invocation).
 // If the JavaScript callback throws an error, it panics in Go, which is caught
 // by the WASM error boundary and returned to TypeScript as a rejected Promise.
-func callbackWrapperCode(t GoType, argExpr string) string {
+// A callback with a return value converts the JS result back to its Go type.
+func callbackWrapperCode(t GoType, argExpr string, imports Imports) string {
+	// The wrapper spells out the callback's parameter and result types
+	imports.addTypeRefs(t)
 	var goParams []string
 	var jsArgs []string
 
 	for i, p := range t.CallbackParams {
 		paramName := fmt.Sprintf("arg%d", i)
 		goParams = append(goParams, fmt.Sprintf("%s %s", paramName, p.Name))
-		// Convert Go value to JS value using existing helper
-		jsArgs = append(jsArgs, GoTypeToJSReturn(p, paramName))
+		jsArgs = append(jsArgs, callbackArgToJS(p, paramName, imports))
+	}
+
+	invoke := argExpr + ".Invoke(" + strings.Join(jsArgs, ", ") + ")"
+	if len(t.CallbackResults) == 1 {
+		result := t.CallbackResults[0]
+		return "func(" + strings.Join(goParams, ", ") + ") " + result.Name + ` {
+		ret := ` + invoke + `
+		return ` + GoTypeToJSExtraction(result, "ret", false, imports) + `
+	}`
 	}
 
-	return "func(" + strings.Join(goParams, ", ") + ") { " +
-		argExpr + ".Invoke(" + strings.Join(jsArgs, ", ") + ") }"
+	// A void callback left out by the caller does nothing
+	return "func(" + strings.Join(goParams, ", ") + ") {\n" +
+		"\t\tif " + argExpr + ".Truthy() {\n" +
+		"\t\t\t" + invoke + "\n" +
+		"\t\t}\n" +
+		"\t}"
+}
+
+// IsVoidCallback reports whether t is a callback without results, which
+// callers may leave out (see callbackWrapperCode and workerCallbackCode).
+func IsVoidCallback(t GoType) bool {
+	return t.Kind == KindFunction && len(t.CallbackResults) == 0
 }
 
 // workerCallbackCode generates worker-mode callback wrapper (postMessage-based invocation).
@@ -326,7 +904,10 @@ func callbackWrapperCode(t GoType, argExpr string) string {
 // Panics if invokeCallback is not defined in the global scope (set by worker.js).
 // NOTE: Callbacks are only valid during the function's execution - they are unregistered
 // when the Go function returns, so callbacks must not be invoked from goroutines.
-func workerCallbackCode(t GoType, argExpr string) string {
+// The client passes 0 for a left-out callback, which makes calls to it no-ops.
+func workerCallbackCode(t GoType, argExpr string, imports Imports) string {
+	// The wrapper spells out the callback's parameter types
+	imports.addTypeRefs(t)
 	var params, pushes strings.Builder
 
 	for i, p := range t.CallbackParams {
@@ -335,85 +916,159 @@ func workerCallbackCode(t GoType, argExpr string) string {
 		}
 		fmt.Fprintf(&params, "arg%d %s", i, p.Name)
 		fmt.Fprintf(&pushes, "\t\tcbArgs.Call(\"push\", %s)\n",
-			GoTypeToJSReturn(p, fmt.Sprintf("arg%d", i)))
+			callbackArgToJS(p, fmt.Sprintf("arg%d", i), imports))
 	}
 
 	return fmt.Sprintf(`func(%s) {
+		if !%s.Truthy() {
+			return
+		}
 		cbArgs := js.Global().Get("Array").New()
 %s		js.Global().Call("invokeCallback", %s.Int(), cbArgs)
-	}`, params.String(), pushes.String(), argExpr)
+	}`, params.String(), argExpr, pushes.String(), argExpr)
+}
+
+// callbackArgToJS converts a Go callback argument to a JS value.
+// A nil error is passed as null so callbacks can use the Node-style
+// `if (err) ...` check; other types use the return conversion.
+func callbackArgToJS(t GoType, argExpr string, imports Imports) string {
+	if t.Kind == KindError {
+		return errorToJS(argExpr)
+	}
+	return GoTypeToJSReturn(t, argExpr, imports)
+}
+
+// errorToJS converts a Go error that may be nil to its message, or null.
+func errorToJS(valueExpr string) string {
+	return `func() interface{} {
+			if ` + valueExpr + ` == nil {
+				return nil
+			}
+			return ` + valueExpr + `.Error()
+		}()`
+}
+
+// errorFromJS converts a JS error message back to a Go error, with
+// anything but a string (null, undefined) becoming nil.
+func errorFromJS(argExpr string, imports Imports) string {
+	imports.Add("errors")
+	return `func() error {
+			if msg := ` + argExpr + `; msg.Type() == js.TypeString {
+				return errors.New(msg.String())
+			}
+			return nil
+		}()`
 }
 
 // GoTypeToJSReturn generates JavaScript return conversion code
 // valueExpr is the Go expression to convert (e.g., "result")
-func GoTypeToJSReturn(t GoType, valueExpr string) string {
+func GoTypeToJSReturn(t GoType, valueExpr string, imports Imports) string {
 	switch t.Kind {
 	case KindPrimitive:
+		if t.BigInt {
+			return bigIntReturn(primitiveName(t), valueExpr, imports)
+		}
+		if t.Underlying != "" {
+			// js.ValueOf only accepts builtin types, so convert named primitives
+			return primitiveReturn(t.Underlying, t.Underlying+"("+valueExpr+")")
+		}
 		return primitiveReturn(t.Name, valueExpr)
 
 	case KindSlice, KindArray:
-		return sliceReturn(t, valueExpr)
+		return sliceReturn(t, valueExpr, imports)
 
 	case KindMap:
-		return mapReturn(valueExpr)
+		return mapReturn(t, valueExpr, imports)
 
 	case KindStruct:
-		return structReturn(t, valueExpr)
+		return structReturn(t, valueExpr, imports)
 
 	case KindPointer:
 		if t.Elem != nil {
-			return GoTypeToJSReturn(*t.Elem, valueExpr)
+			return pointerReturn(t, valueExpr, imports)
 		}
 		return valueExpr
 
 	case KindError:
 		return valueExpr + ".Error()"
 
+	case KindTime:
+		return `js.Global().Get("Date").New(` + valueExpr + ".UnixMilli())"
+
+	case KindAny:
+		return AnyEncoder + "(" + valueExpr + ")"
+
 	default:
 		return valueExpr
 	}
 }
 
+// bigIntReturn generates code building a JS bigint from a 64-bit integer
+// via its decimal string, since js.ValueOf converts integers to number.
+func bigIntReturn(primitive, valueExpr string, imports Imports) string {
+	imports.Add("strconv")
+	if primitive == "uint64" {
+		return `js.Global().Get("BigInt").Invoke(strconv.FormatUint(uint64(` + valueExpr + `), 10))`
+	}
+	return `js.Global().Get("BigInt").Invoke(strconv.FormatInt(int64(` + valueExpr + `), 10))`
+}
+
 // primitiveReturn generates return conversion for primitives
 func primitiveReturn(typeName, valueExpr string) string {
-	// Most primitives can be returned directly in Go WASM
+	if typeName == "rune" {
+		return "string(" + valueExpr + ")"
+	}
+	// Other primitives can be returned directly in Go WASM
 	return valueExpr
 }
 
 // sliceReturn generates return conversion for slices
-func sliceReturn(t GoType, valueExpr string) string {
+func sliceReturn(t GoType, valueExpr string, imports Imports) string {
 	if t.Elem == nil {
 		return "nil"
 	}
+	if t.Nullable {
+		t.Nullable = false
+		return nilToNull(valueExpr, sliceReturn(t, valueExpr, imports))
+	}
+
+	if t.Base64 {
+		imports.Add("encoding/base64")
+		return "base64.StdEncoding.EncodeToString(" + valueExpr + ")"
+	}
 
 	// Use js.CopyBytesToJS for byte slices (efficient bulk copy)
-	if isByteSlice(t) {
+	if IsByteSlice(t) {
 		return byteSliceReturn(valueExpr)
 	}
 
-	// For typed array element types (int32, float64, etc.), create JS typed array
-	if jsTypedArray := goElemToTypedArray(t.Elem.Name); jsTypedArray != "" {
-		return typedArrayReturn(jsTypedArray, valueExpr)
+	if IsRuneSlice(t) {
+		return "string(" + valueExpr + ")"
 	}
 
-	// For other primitive element types (int, string, bool), return directly
-	if t.Elem.Kind == KindPrimitive {
-		return valueExpr
+	// For typed array element types (int32, float64, etc.), create JS typed array.
+	// Named primitives (e.g., type Score int32) use their underlying type's array.
+	if t.Elem.Kind == KindPrimitive && !t.PlainArray {
+		if jsTypedArray := goElemToTypedArray(primitiveName(*t.Elem)); jsTypedArray != "" {
+			return typedArrayReturn(jsTypedArray, valueExpr, *t.Elem, imports)
+		}
 	}
 
-	// For complex types, need to convert each element
+	// Convert each element: js.ValueOf only accepts []interface{}, so even
+	// []string and []int are copied
 	var b strings.Builder
+	// (named "out" so it never shadows valueExpr, which is often "result")
 	b.WriteString("func() []interface{} {\n")
-	b.WriteString("\t\tresult := make([]interface{}, len(")
+	b.WriteString("\t\tout := make([]interface{}, len(")
 	b.WriteString(valueExpr)
 	b.WriteString("))\n")
 	b.WriteString("\t\tfor i, v := range ")
 	b.WriteString(valueExpr)
 	b.WriteString(" {\n")
-	b.WriteString("\t\t\tresult[i] = ")
-	b.WriteString(GoTypeToJSReturn(*t.Elem, "v"))
+	b.WriteString("\t\t\tout[i] = ")
+	b.WriteString(GoTypeToJSReturn(*t.Elem, "v", imports))
 	b.WriteString("\n\t\t}\n")
-	b.WriteString("\t\treturn result\n")
+	b.WriteString("\t\treturn out\n")
 	b.WriteString("\t}()")
 
 	return b.String()
@@ -421,12 +1076,12 @@ func sliceReturn(t GoType, valueExpr string) string {
 
 // typedArrayReturn generates return code for typed arrays (Int32Array, Float64Array, etc.).
 // Creates a JS typed array and copies elements one by one.
-func typedArrayReturn(jsTypedArray, valueExpr string) string {
+func typedArrayReturn(jsTypedArray, valueExpr string, elem GoType, imports Imports) string {
 	return `func() js.Value {
 		slice := ` + valueExpr + `
 		arr := js.Global().Get("` + jsTypedArray + `").New(len(slice))
 		for i, v := range slice {
-			arr.SetIndex(i, v)
+			arr.SetIndex(i, ` + GoTypeToJSReturn(elem, "v", imports) + `)
 		}
 		return arr
 	}()`
@@ -442,30 +1097,166 @@ func byteSliceReturn(valueExpr string) string {
 	}()`
 }
 
-// mapReturn generates return conversion for maps
-func mapReturn(valueExpr string) string {
-	return "map[string]interface{}(" + valueExpr + ")"
+// GoTypeToJSSharedReturn is GoTypeToJSReturn for top-level function results
+// with shared memory enabled: byte slices and slices returned as typed
+// arrays (see goElemToTypedArray) are copied into a SharedArrayBuffer-backed
+// typed array so postMessage can share them across workers without another
+// copy. It falls back to a regular typed array when the page is not
+// cross-origin isolated. Other types are unchanged.
+func GoTypeToJSSharedReturn(t GoType, valueExpr string, imports Imports) string {
+	if t.Nullable && t.Kind == KindSlice {
+		t.Nullable = false
+		return nilToNull(valueExpr, GoTypeToJSSharedReturn(t, valueExpr, imports))
+	}
+	if IsByteSlice(t) && !t.Base64 {
+		return `func() js.Value {
+		var arr js.Value
+		if sab := js.Global().Get("SharedArrayBuffer"); sab.Truthy() && js.Global().Get("crossOriginIsolated").Truthy() {
+			arr = js.Global().Get("Uint8Array").New(sab.New(len(` + valueExpr + `)))
+		} else {
+			arr = js.Global().Get("Uint8Array").New(len(` + valueExpr + `))
+		}
+		js.CopyBytesToJS(arr, ` + valueExpr + `)
+		return arr
+	}()`
+	}
+	if t.Kind != KindSlice || t.Elem == nil || t.Elem.Kind != KindPrimitive || t.Base64 || t.PlainArray {
+		return GoTypeToJSReturn(t, valueExpr, imports)
+	}
+	jsTypedArray := goElemToTypedArray(primitiveName(*t.Elem))
+	if jsTypedArray == "" {
+		return GoTypeToJSReturn(t, valueExpr, imports)
+	}
+	// As in typedArrayCopy, both sides are little-endian with the same
+	// element size, so the slice's bytes are copied as is
+	imports.Add("unsafe")
+	return `func() js.Value {
+		slice := ` + valueExpr + `
+		var arr js.Value
+		if sab := js.Global().Get("SharedArrayBuffer"); sab.Truthy() && js.Global().Get("crossOriginIsolated").Truthy() {
+			arr = js.Global().Get("` + jsTypedArray + `").New(sab.New(len(slice) * int(unsafe.Sizeof(slice[0]))))
+		} else {
+			arr = js.Global().Get("` + jsTypedArray + `").New(len(slice))
+		}
+		if len(slice) > 0 {
+			src := unsafe.Slice((*byte)(unsafe.Pointer(&slice[0])), len(slice)*int(unsafe.Sizeof(slice[0])))
+			js.CopyBytesToJS(js.Global().Get("Uint8Array").New(arr.Get("buffer")), src)
+		}
+		return arr
+	}()`
+}
+
+// mapReturn generates return conversion for maps.
+// Keys are stringified since JS object keys are always strings.
+func mapReturn(t GoType, valueExpr string, imports Imports) string {
+	if t.Nullable {
+		t.Nullable = false
+		return nilToNull(valueExpr, mapReturn(t, valueExpr, imports))
+	}
+	if t.Key == nil || t.Value == nil || (t.Key.Name == "string" && isInterface(*t.Value)) {
+		return "map[string]interface{}(" + valueExpr + ")"
+	}
+
+	key := "k"
+	if primitiveName(*t.Key) == "rune" {
+		key = "string(k)"
+	} else if t.Key.Name != "string" {
+		key = "fmt.Sprint(k)"
+	}
+	return `func() map[string]interface{} {
+		out := make(map[string]interface{}, len(` + valueExpr + `))
+		for k, v := range ` + valueExpr + ` {
+			out[` + key + `] = ` + GoTypeToJSReturn(*t.Value, "v", imports) + `
+		}
+		return out
+	}()`
 }
 
-// structReturn generates return conversion for structs
-func structReturn(t GoType, valueExpr string) string {
-	var b strings.Builder
+// nilToNull wraps the return conversion of a nullable slice or map (see
+// GoType.Nullable) so that nil becomes JS null.
+func nilToNull(valueExpr, conversion string) string {
+	return "func() interface{} {\n" +
+		"\t\tif " + valueExpr + " == nil {\n" +
+		"\t\t\treturn nil\n" +
+		"\t\t}\n" +
+		"\t\treturn " + conversion + "\n" +
+		"\t}()"
+}
+
+// nullToNil wraps the extraction of a nullable slice or map of type
+// typeName so that null and undefined become nil.
+func nullToNil(typeName, argExpr, extraction string) string {
+	return "func() " + typeName + " {\n" +
+		"\t\tif " + argExpr + ".IsNull() || " + argExpr + ".IsUndefined() {\n" +
+		"\t\t\treturn nil\n" +
+		"\t\t}\n" +
+		"\t\treturn " + extraction + "\n" +
+		"\t}()"
+}
+
+// isInterface returns true for the empty interface types interface{} and any.
+func isInterface(t GoType) bool {
+	return t.Name == "interface{}" || t.Name == "any" || t.Name == "interface"
+}
+
+// pointerReturn generates return conversion for pointers, returning nil
+// (JS null) for a nil pointer.
+func pointerReturn(t GoType, valueExpr string, imports Imports) string {
+	return "func() interface{} {\n" +
+		"\t\tif " + valueExpr + " == nil {\n" +
+		"\t\t\treturn nil\n" +
+		"\t\t}\n" +
+		"\t\treturn " + GoTypeToJSReturn(*t.Elem, "(*"+valueExpr+")", imports) + "\n" +
+		"\t}()"
+}
 
-	b.WriteString("map[string]interface{}{\n")
+// structReturn generates return conversion for structs. Empty optional
+// fields (see IsOptionalField) are left out of the result, as encoding/json
+// does.
+func structReturn(t GoType, valueExpr string, imports Imports) string {
+	if t.JSON {
+		return AnyEncoder + "(" + valueExpr + ")"
+	}
+	if t.Recursive || t.Helpers {
+		return structToJS(t.Name) + "(" + valueExpr + ")"
+	}
+
+	var fields, optional strings.Builder
 	for _, field := range t.Fields {
+		if field.Skip {
+			continue
+		}
 		fieldKey := field.JSONTag
 		if fieldKey == "" {
 			// Use lowercase first letter for JSON key
 			fieldKey = strings.ToLower(field.Name[:1]) + field.Name[1:]
 		}
 
-		b.WriteString("\t\t\"")
-		b.WriteString(fieldKey)
-		b.WriteString("\": ")
-		b.WriteString(GoTypeToJSReturn(field.Type, valueExpr+"."+field.Name))
-		b.WriteString(",\n")
+		fieldExpr := valueExpr + "." + field.Name
+		var value string
+		if isStringTagged(field) {
+			// Match encoding/json's ",string" option
+			value = "fmt.Sprint(" + fieldExpr + ")"
+		} else if field.Type.Kind == KindError {
+			value = errorToJS(fieldExpr)
+		} else {
+			value = GoTypeToJSReturn(field.Type, fieldExpr, imports)
+		}
+		if IsOptionalField(field) {
+			optional.WriteString("\t\tif " + nonEmptyCheck(field.Type, fieldExpr) + " {\n")
+			optional.WriteString("\t\t\tout[\"" + fieldKey + "\"] = " + value + "\n")
+			optional.WriteString("\t\t}\n")
+			continue
+		}
+		fields.WriteString("\t\t\"" + fieldKey + "\": " + value + ",\n")
 	}
-	b.WriteString("\t}")
 
-	return b.String()
+	if optional.Len() == 0 {
+		return "map[string]interface{}{\n" + fields.String() + "\t}"
+	}
+	return "func() map[string]interface{} {\n" +
+		"\t\tout := map[string]interface{}{\n" + fields.String() + "\t\t}\n" +
+		optional.String() +
+		"\t\treturn out\n" +
+		"\t}()"
 }
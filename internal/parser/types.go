@@ -1,5 +1,7 @@
 package parser
 
+import "strings"
+
 // TypeKind represents the category of a Go type
 type TypeKind int
 
@@ -13,6 +15,8 @@ const (
 	KindPointer
 	KindError
 	KindFunction // function type (for callbacks)
+	KindTime     // time.Time, exchanged with JS as a Date
+	KindAny      // interface{} or any, exchanged with JS through its JSON encoding
 	KindUnsupported
 )
 
@@ -26,16 +30,84 @@ type GoType struct {
 	Fields  []GoField // Fields for struct types
 	IsError bool      // True if this is the error type
 
-	// For KindFunction (void callbacks only)
-	CallbackParams []GoType // Parameter types of the callback (nil if not a callback)
-	IsVoid         bool     // True if callback has no return value (for validator)
+	// Underlying is the builtin primitive behind a named primitive type
+	// (e.g., "int32" for type Score int32). Empty for builtin primitives.
+	Underlying string
+
+	// Named is true for struct types declared with a type name (e.g., type
+	// User struct{...}). TypeScript references them by name instead of inline.
+	Named bool
+
+	// Recursive is true for named structs that refer to themselves through
+	// a pointer, slice, or map field (e.g., Next *Node). Their conversions
+	// go through generated helper functions (see StructHelpers). References
+	// inside the declaration itself carry only the name.
+	Recursive bool
+
+	// Helpers is true for named structs converted through generated helper
+	// functions (see StructHelpers) instead of inline code, which keeps the
+	// bindings small when a type is used in many places (see UseStructHelpers).
+	Helpers bool
+
+	// BigInt is true for 64-bit integer primitives that cross the JS
+	// boundary as bigint instead of number (see UseBigInt).
+	BigInt bool
+
+	// Base64 is true for []byte values that cross the JS boundary as a
+	// base64 string instead of a Uint8Array (see UseBase64Bytes).
+	Base64 bool
+
+	// PlainArray is true for numeric slices and arrays that cross the JS
+	// boundary as number[] instead of a typed array (see UsePlainArrays).
+	PlainArray bool
+
+	// ByteOrder is "little" or "big" for numeric slice parameters decoded
+	// from the raw bytes of any typed array or DataView in that byte order
+	// (see UseTypedArrayBytes), and empty otherwise.
+	ByteOrder string
+
+	// JSON is true for structs that cross the JS boundary as their
+	// encoding/json encoding instead of field by field, and for the types
+	// nested in them, whose TypeScript types then describe that encoding
+	// (see UseJSONStructs).
+	JSON bool
+
+	// Nullable is true for slices and maps whose nil value crosses the JS
+	// boundary as null instead of an empty array or object (see
+	// UseNilAsNull).
+	Nullable bool
+
+	// EnumValues names the constants declared with a named primitive type
+	// in the source file (e.g., Red, Green, Blue for type Color int).
+	EnumValues []string
+
+	// EnumLiterals holds the string value of each of EnumValues, in order,
+	// for string types whose constants are all string literals. TypeScript
+	// then types them as a union of those literals. Nil otherwise.
+	EnumLiterals []string
+
+	// EnumClosed is set when EnumValues are the only valid values of the
+	// type: string constants that are all literals, or integer constants
+	// all counted with iota. Bindings reject other values of closed types.
+	EnumClosed bool
+
+	// For KindFunction (callbacks)
+	CallbackParams  []GoType // Parameter types of the callback (nil if not a callback)
+	CallbackNames   []string // Parameter names of the callback (nil if unnamed)
+	CallbackResults []GoType // Return types of the callback (nil if void)
+	IsVoid          bool     // True if callback has no return value (for validator)
 }
 
 // GoField represents a single field in a struct
 type GoField struct {
-	Name    string // Field name
-	Type    GoType // Field type
-	JSONTag string // JSON tag value (if present)
+	Name       string // Field name
+	Type       GoType // Field type
+	JSONTag    string // JSON tag value (if present)
+	TSName     string // ts tag value (if present), the field's TypeScript name in place of JSONTag
+	JSONString bool   // True if the JSON tag has the ",string" option
+	OmitEmpty  bool   // True if the JSON tag has the ",omitempty" option
+	Skip       bool   // True for json:"-" fields, which never cross the JS boundary
+	Doc        string // Documentation comment, or the trailing line comment if there is none
 }
 
 // GoFunction represents a parsed exported function
@@ -44,12 +116,44 @@ type GoFunction struct {
 	Params  []GoParameter // Function parameters
 	Returns []GoType      // Return types
 	Doc     string        // Documentation comment
+	Pos     string        // Declaration position as file:line, for messages
+
+	// Context is set when the first parameter is a context.Context. It is
+	// left out of Params, and the bindings pass context.Background().
+	Context bool
+
+	// JSName is the name set with a //gowasm:name directive, empty to use
+	// Name with its first letter lowercased (see BindingName).
+	JSName string
+
+	// Receiver is the type a method was declared on when it is bound with
+	// UseMethodsOf, and empty for functions.
+	Receiver string
+
+	// ResultUnion is set when the error result is reported to JS as part of
+	// an { ok, value } / { ok, error } object instead of a rejection (see
+	// UseResultUnion).
+	ResultUnion bool
+
+	// ReadonlyReturn is set when the result is declared with readonly
+	// TypeScript types (see UseReadonlyReturns).
+	ReadonlyReturn bool
+}
+
+// BindingName returns the name fn is registered under in JavaScript and
+// called by in the TypeScript client.
+func (fn GoFunction) BindingName() string {
+	if fn.JSName != "" || fn.Name == "" {
+		return fn.JSName
+	}
+	return strings.ToLower(fn.Name[:1]) + fn.Name[1:]
 }
 
 // GoParameter represents a single function parameter
 type GoParameter struct {
-	Name string // Parameter name
-	Type GoType // Parameter type
+	Name       string // Parameter name
+	Type       GoType // Parameter type (a slice for variadic parameters)
+	IsVariadic bool   // True for a final ...T parameter
 }
 
 // ParsedFile represents a parsed Go source file
@@ -57,4 +161,8 @@ type ParsedFile struct {
 	Package   string             // Package name
 	Functions []GoFunction       // Exported functions
 	Types     map[string]*GoType // Type definitions in the file
+
+	// Methods holds the exported methods of each receiver type, by type
+	// name. They are not bound unless moved into Functions by UseMethodsOf.
+	Methods map[string][]GoFunction
 }
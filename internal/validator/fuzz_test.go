@@ -89,6 +89,6 @@ func GetComplex() Complex { return Complex{} }`,
 
 		// Validate - should not panic regardless of input
 		// Validation errors are expected and fine
-		_ = ValidateFunctions(parsed)
+		_ = ValidateFunctions(parsed, Options{})
 	})
 }
@@ -4,6 +4,7 @@ package validator
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/13rac1/gowasm-bindgen/internal/parser"
 )
@@ -22,13 +23,34 @@ func (e ValidationError) Error() string {
 	return b.String()
 }
 
+// Options selects mode-dependent validation rules. The zero value validates
+// for worker mode, the strictest target.
+type Options struct {
+	// SyncMode allows callbacks with a return value, which worker mode cannot
+	// support because postMessage cannot return a value synchronously.
+	SyncMode bool
+
+	// AllowAny accepts interface{} and any, which are exchanged with JS
+	// through their JSON encoding.
+	AllowAny bool
+
+	// Strict rejects every type the generator would emit as TypeScript any,
+	// instead of letting it through untyped.
+	Strict bool
+}
+
 // ValidateFunctions runs all validation rules on parsed functions
-func ValidateFunctions(parsed *parser.ParsedFile) error {
+func ValidateFunctions(parsed *parser.ParsedFile, opts Options) error {
 	var errs []error
 
 	for _, fn := range parsed.Functions {
-		errs = append(errs, validateFunction(fn)...)
+		errs = append(errs, validateFunction(fn, opts)...)
+		errs = append(errs, validateTypeDefinitions(fn, parsed.Types)...)
+		if opts.Strict {
+			errs = append(errs, validateNoAny(fn, parsed.Types)...)
+		}
 	}
+	errs = append(errs, validateUniqueNames(parsed.Functions)...)
 
 	if len(errs) > 0 {
 		return ValidationError{Errors: errs}
@@ -36,13 +58,177 @@ func ValidateFunctions(parsed *parser.ParsedFile) error {
 	return nil
 }
 
+// validateUniqueNames reports functions that would register under the same
+// JavaScript name, where the later registration silently replaces the
+// earlier. This can happen when a package directory is parsed without type
+// checking.
+func validateUniqueNames(fns []parser.GoFunction) []error {
+	var order []string
+	byName := make(map[string][]parser.GoFunction)
+	for _, fn := range fns {
+		name := fn.BindingName()
+		if _, ok := byName[name]; !ok {
+			order = append(order, name)
+		}
+		byName[name] = append(byName[name], fn)
+	}
+
+	var errs []error
+	for _, name := range order {
+		dups := byName[name]
+		if len(dups) < 2 {
+			continue
+		}
+		decls := make([]string, len(dups))
+		for i, fn := range dups {
+			decls[i] = fn.Name
+			if fn.Pos != "" {
+				decls[i] += " (" + fn.Pos + ")"
+			}
+		}
+		errs = append(errs, fmt.Errorf(
+			"duplicate JavaScript name %q: declared by %s",
+			name, strings.Join(decls, ", ")))
+	}
+	return errs
+}
+
+// validateTypeDefinitions reports type names used by fn that have no
+// definition in types. The parser only resolves exported declarations of
+// the package, so anything else (unexported or missing types) arrives as an
+// unknown primitive the generator would silently treat as any.
+func validateTypeDefinitions(fn parser.GoFunction, types map[string]*parser.GoType) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	report := func(t parser.GoType, context string) {
+		for _, name := range undefinedTypes(t, types, nil) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			hint := "declare it in the package"
+			if !unicode.IsUpper(rune(name[0])) {
+				hint = "unexported types are not converted, rename it to " + strings.ToUpper(name[:1]) + name[1:]
+			}
+			errs = append(errs, fmt.Errorf(
+				"function %s: %s uses type %s, which has no exported type definition in the source (%s)",
+				fn.Name, context, name, hint))
+		}
+	}
+	for _, param := range fn.Params {
+		report(param.Type, "parameter "+param.Name)
+	}
+	for _, ret := range fn.Returns {
+		report(ret, "return type")
+	}
+	return errs
+}
+
+// undefinedTypes appends the names in t that are neither primitives nor
+// defined in types to names.
+func undefinedTypes(t parser.GoType, types map[string]*parser.GoType, names []string) []string {
+	switch t.Kind {
+	case parser.KindPrimitive:
+		if t.Underlying == "" && !parser.IsPrimitive(t.Name) && types[t.Name] == nil {
+			names = append(names, t.Name)
+		}
+	case parser.KindSlice, parser.KindArray, parser.KindPointer:
+		if t.Elem != nil {
+			names = undefinedTypes(*t.Elem, types, names)
+		}
+	case parser.KindMap:
+		if t.Key != nil {
+			names = undefinedTypes(*t.Key, types, names)
+		}
+		if t.Value != nil {
+			names = undefinedTypes(*t.Value, types, names)
+		}
+	case parser.KindStruct:
+		for _, field := range t.Fields {
+			if !field.Skip {
+				names = undefinedTypes(field.Type, types, names)
+			}
+		}
+	case parser.KindFunction:
+		for _, param := range t.CallbackParams {
+			names = undefinedTypes(param, types, names)
+		}
+		for _, result := range t.CallbackResults {
+			names = undefinedTypes(result, types, names)
+		}
+	}
+	return names
+}
+
+// validateNoAny reports every place in fn's signature that resolves to
+// TypeScript any. Type names without a definition are left to
+// validateTypeDefinitions, which already reports them.
+func validateNoAny(fn parser.GoFunction, types map[string]*parser.GoType) []error {
+	var errs []error
+	var walk func(t parser.GoType, context string)
+	walk = func(t parser.GoType, context string) {
+		if parser.IsTSAny(t) {
+			if t.Kind == parser.KindPrimitive && types[t.Name] == nil {
+				return
+			}
+			errs = append(errs, fmt.Errorf("function %s: %s resolved to any (--strict)", fn.Name, context))
+			return
+		}
+		switch t.Kind {
+		case parser.KindSlice, parser.KindArray:
+			walk(*t.Elem, context+" element")
+		case parser.KindPointer:
+			walk(*t.Elem, context+" (pointer)")
+		case parser.KindMap:
+			walk(*t.Key, context+" map key")
+			walk(*t.Value, context+" map value")
+		case parser.KindStruct:
+			for _, field := range t.Fields {
+				if !field.Skip {
+					walk(field.Type, context+" field "+field.Name)
+				}
+			}
+		case parser.KindFunction:
+			for i, param := range t.CallbackParams {
+				walk(param, fmt.Sprintf("%s callback param %d", context, i))
+			}
+			for _, result := range t.CallbackResults {
+				walk(result, context+" callback return")
+			}
+		}
+	}
+	for _, param := range fn.Params {
+		walk(param.Type, "parameter "+param.Name)
+	}
+	for i, ret := range fn.Returns {
+		switch {
+		case ret.IsError:
+		case i == 0 && ret.Kind == parser.KindStruct:
+			// A struct result gets its own interface, even when empty
+			for _, field := range ret.Fields {
+				if !field.Skip {
+					walk(field.Type, "return type field "+field.Name)
+				}
+			}
+		default:
+			walk(ret, "return type")
+		}
+	}
+	return errs
+}
+
 // validateFunction checks a single function for unsupported features
-func validateFunction(fn parser.GoFunction) []error {
+func validateFunction(fn parser.GoFunction, opts Options) []error {
 	var errs []error
 
+	if fn.JSName != "" && !isJSIdentifier(fn.JSName) {
+		errs = append(errs, fmt.Errorf(
+			"function %s: //gowasm:name %q is not a JavaScript identifier", fn.Name, fn.JSName))
+	}
+
 	// Check parameters for unsupported types
 	for _, param := range fn.Params {
-		if err := validateType(param.Type, fn.Name, "parameter "+param.Name); err != nil {
+		if err := validateType(param.Type, fn.Name, "parameter "+param.Name, opts); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -54,7 +240,7 @@ func validateFunction(fn parser.GoFunction) []error {
 				"function %s: error return type must be last", fn.Name))
 		}
 		if !ret.IsError {
-			if err := validateType(ret, fn.Name, "return type"); err != nil {
+			if err := validateType(ret, fn.Name, "return type", opts); err != nil {
 				errs = append(errs, err)
 			}
 		}
@@ -63,8 +249,25 @@ func validateFunction(fn parser.GoFunction) []error {
 	return errs
 }
 
+// isJSIdentifier reports whether name is an ASCII JavaScript identifier that
+// is not a reserved word.
+func isJSIdentifier(name string) bool {
+	if name == "" || parser.IsJSReservedWord(name) {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // validateType checks if a type is supported for WASM bindings
-func validateType(t parser.GoType, funcName, context string) error {
+func validateType(t parser.GoType, funcName, context string, opts Options) error {
 	switch t.Kind {
 	case parser.KindPrimitive:
 		// All primitives are supported
@@ -72,31 +275,35 @@ func validateType(t parser.GoType, funcName, context string) error {
 
 	case parser.KindSlice, parser.KindArray:
 		if t.Elem != nil {
-			return validateType(*t.Elem, funcName, context+" element")
+			return validateType(*t.Elem, funcName, context+" element", opts)
 		}
 		return nil
 
 	case parser.KindMap:
-		// Only map[string]T is supported
-		if t.Key == nil || t.Key.Name != "string" {
+		// Keys must round-trip through JS object key strings
+		if t.Key == nil || !isMapKeyType(*t.Key) {
 			return fmt.Errorf(
-				"function %s: %s uses unsupported map type %s (only map[string]T is supported)",
+				"function %s: %s uses unsupported map type %s (map keys must be string, integer, or bool)",
 				funcName, context, t.Name)
 		}
 		if t.Value != nil {
-			return validateType(*t.Value, funcName, context+" map value")
+			return validateType(*t.Value, funcName, context+" map value", opts)
 		}
 		return nil
 
 	case parser.KindStruct:
 		// Structs are supported, validate fields
 		for _, field := range t.Fields {
+			if field.Skip {
+				// Never converted, so any type is fine
+				continue
+			}
 			if field.Name == "" {
 				return fmt.Errorf(
 					"function %s: %s contains an anonymous/embedded field (embedded fields are not supported in WASM bindings)",
 					funcName, context)
 			}
-			if err := validateType(field.Type, funcName, context+" field "+field.Name); err != nil {
+			if err := validateType(field.Type, funcName, context+" field "+field.Name, opts); err != nil {
 				return err
 			}
 		}
@@ -105,7 +312,7 @@ func validateType(t parser.GoType, funcName, context string) error {
 	case parser.KindPointer:
 		// Pointers are supported, validate underlying type
 		if t.Elem != nil {
-			return validateType(*t.Elem, funcName, context+" (pointer)")
+			return validateType(*t.Elem, funcName, context+" (pointer)", opts)
 		}
 		return nil
 
@@ -113,6 +320,18 @@ func validateType(t parser.GoType, funcName, context string) error {
 		// Error is supported
 		return nil
 
+	case parser.KindTime:
+		// time.Time is exchanged as a JS Date
+		return nil
+
+	case parser.KindAny:
+		if !opts.AllowAny {
+			return fmt.Errorf(
+				"function %s: %s uses %s (enable --allow-any to exchange arbitrary JSON values)",
+				funcName, context, t.Name)
+		}
+		return nil
+
 	case parser.KindFunction:
 		// Callbacks are only supported as direct function parameters
 		if !strings.HasPrefix(context, "parameter ") {
@@ -128,22 +347,47 @@ func validateType(t parser.GoType, funcName, context string) error {
 				funcName, context)
 		}
 
-		// Reject callbacks with return values
-		if !t.IsVoid {
+		// Worker mode can't return callback values: postMessage is asynchronous
+		if !t.IsVoid && !opts.SyncMode {
+			return fmt.Errorf(
+				"function %s: %s has a return value (only void callbacks are supported in worker mode)",
+				funcName, context)
+		}
+		if len(t.CallbackResults) > 1 {
 			return fmt.Errorf(
-				"function %s: %s has a return value (only void callbacks are supported)",
+				"function %s: %s has multiple return values (callbacks may return at most one value)",
 				funcName, context)
 		}
+		for _, result := range t.CallbackResults {
+			if result.IsError || result.Kind == parser.KindFunction {
+				return fmt.Errorf(
+					"function %s: %s returns unsupported type %s",
+					funcName, context, result.Name)
+			}
+			if err := validateType(result, funcName, context+" callback return", opts); err != nil {
+				return err
+			}
+		}
 
 		// Validate callback parameter types recursively
 		for i, param := range t.CallbackParams {
-			if err := validateType(param, funcName, fmt.Sprintf("%s callback param %d", context, i)); err != nil {
+			if err := validateType(param, funcName, fmt.Sprintf("%s callback param %d", context, i), opts); err != nil {
 				return err
 			}
 		}
 		return nil
 
 	case parser.KindUnsupported:
+		switch t.Name {
+		case "complex64", "complex128", "uintptr":
+			return fmt.Errorf(
+				"function %s: %s uses %s, which is not supported (JavaScript has no matching type)",
+				funcName, context, t.Name)
+		case "context.Context":
+			return fmt.Errorf(
+				"function %s: %s uses context.Context, which is only supported as the first parameter",
+				funcName, context)
+		}
 		return fmt.Errorf(
 			"function %s: %s uses unsupported type %q (channels, interfaces, and external types are not supported)",
 			funcName, context, t.Name)
@@ -153,3 +397,22 @@ func validateType(t parser.GoType, funcName, context string) error {
 			"function %s: %s uses unknown type kind %v", funcName, context, t.Kind)
 	}
 }
+
+// mapKeyTypes are the primitives whose values survive conversion to and from
+// JS object key strings.
+var mapKeyTypes = map[string]bool{
+	"string": true, "bool": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "byte": true,
+}
+
+// isMapKeyType returns true if t can be used as a map key in WASM bindings.
+func isMapKeyType(t parser.GoType) bool {
+	if t.Kind != parser.KindPrimitive {
+		return false
+	}
+	if t.Underlying != "" {
+		return mapKeyTypes[t.Underlying]
+	}
+	return mapKeyTypes[t.Name]
+}
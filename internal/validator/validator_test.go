@@ -25,7 +25,7 @@ func TestValidateFunctions_Success(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -52,7 +52,7 @@ func TestValidateFunctions_AllPrimitives(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for primitives, got: %v", err)
 	}
@@ -83,7 +83,7 @@ func TestValidateFunctions_Slices(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for slices, got: %v", err)
 	}
@@ -111,41 +111,49 @@ func TestValidateFunctions_StringMap(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for string map, got: %v", err)
 	}
 }
 
 func TestValidateFunctions_NonStringMapKey(t *testing.T) {
-	parsed := &parser.ParsedFile{
-		Package: "wasm",
-		Functions: []parser.GoFunction{
-			{
-				Name: "ProcessMap",
-				Params: []parser.GoParameter{
-					{Name: "data", Type: parser.GoType{
-						Name:  "map[int]string",
-						Kind:  parser.KindMap,
-						Key:   &parser.GoType{Name: "int", Kind: parser.KindPrimitive},
-						Value: &parser.GoType{Name: "string", Kind: parser.KindPrimitive},
-					}},
-				},
-				Returns: []parser.GoType{
-					{Name: "bool", Kind: parser.KindPrimitive},
+	mapParam := func(key string) *parser.ParsedFile {
+		return &parser.ParsedFile{
+			Package: "wasm",
+			Functions: []parser.GoFunction{
+				{
+					Name: "ProcessMap",
+					Params: []parser.GoParameter{
+						{Name: "data", Type: parser.GoType{
+							Name:  "map[" + key + "]string",
+							Kind:  parser.KindMap,
+							Key:   &parser.GoType{Name: key, Kind: parser.KindPrimitive},
+							Value: &parser.GoType{Name: "string", Kind: parser.KindPrimitive},
+						}},
+					},
+					Returns: []parser.GoType{
+						{Name: "bool", Kind: parser.KindPrimitive},
+					},
 				},
 			},
-		},
-		Types: map[string]*parser.GoType{},
+			Types: map[string]*parser.GoType{},
+		}
+	}
+
+	for _, key := range []string{"int", "uint16", "bool"} {
+		if err := ValidateFunctions(mapParam(key), Options{}); err != nil {
+			t.Errorf("expected no error for map[%s]string, got: %v", key, err)
+		}
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(mapParam("float64"), Options{})
 	if err == nil {
-		t.Fatal("expected error for non-string map key")
+		t.Fatal("expected error for float map key")
 	}
 
 	errStr := err.Error()
-	if !strings.Contains(errStr, "only map[string]T is supported") {
+	if !strings.Contains(errStr, "map keys must be string, integer, or bool") {
 		t.Errorf("expected error about map key type, got: %s", errStr)
 	}
 }
@@ -168,7 +176,7 @@ func TestValidateFunctions_ErrorReturn(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for (T, error) return, got: %v", err)
 	}
@@ -190,7 +198,7 @@ func TestValidateFunctions_ErrorNotLast(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err == nil {
 		t.Fatal("expected error when error return is not last")
 	}
@@ -223,7 +231,7 @@ func TestValidateFunctions_Struct(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for struct return, got: %v", err)
 	}
@@ -250,12 +258,58 @@ func TestValidateFunctions_Pointer(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for pointer type, got: %v", err)
 	}
 }
 
+func TestValidateFunctions_Time(t *testing.T) {
+	timeType := parser.GoType{Name: "time.Time", Kind: parser.KindTime}
+	parsed := &parser.ParsedFile{
+		Package: "wasm",
+		Functions: []parser.GoFunction{
+			{
+				Name: "Later",
+				Params: []parser.GoParameter{
+					{Name: "t", Type: timeType},
+					{Name: "all", Type: parser.GoType{Name: "[]time.Time", Kind: parser.KindSlice, Elem: &timeType}},
+				},
+				Returns: []parser.GoType{timeType},
+			},
+		},
+		Types: map[string]*parser.GoType{},
+	}
+
+	if err := ValidateFunctions(parsed, Options{}); err != nil {
+		t.Errorf("expected no error for time.Time, got: %v", err)
+	}
+}
+
+func TestValidateFunctions_Any(t *testing.T) {
+	anyType := parser.GoType{Name: "interface{}", Kind: parser.KindAny}
+	parsed := &parser.ParsedFile{
+		Package: "wasm",
+		Functions: []parser.GoFunction{
+			{
+				Name:    "Handle",
+				Params:  []parser.GoParameter{{Name: "v", Type: anyType}},
+				Returns: []parser.GoType{anyType},
+			},
+		},
+		Types: map[string]*parser.GoType{},
+	}
+
+	err := ValidateFunctions(parsed, Options{})
+	if err == nil || !strings.Contains(err.Error(), "parameter v uses interface{} (enable --allow-any") {
+		t.Errorf("expected interface{} to be rejected by default, got: %v", err)
+	}
+
+	if err := ValidateFunctions(parsed, Options{AllowAny: true}); err != nil {
+		t.Errorf("expected no error with AllowAny, got: %v", err)
+	}
+}
+
 func TestValidateFunctions_UnsupportedTypes(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -264,7 +318,11 @@ func TestValidateFunctions_UnsupportedTypes(t *testing.T) {
 	}{
 		{"channel", "chan", "unsupported type"},
 		{"interface", "interface", "unsupported type"},
-		{"external type", "time.Time", "unsupported type"},
+		{"external type", "sql.NullString", "unsupported type"},
+		{"complex64", "complex64", "parameter x uses complex64, which is not supported"},
+		{"complex128", "complex128", "parameter x uses complex128, which is not supported"},
+		{"uintptr", "uintptr", "parameter x uses uintptr, which is not supported"},
+		{"context", "context.Context", "parameter x uses context.Context, which is only supported as the first parameter"},
 	}
 
 	for _, tt := range tests {
@@ -283,7 +341,7 @@ func TestValidateFunctions_UnsupportedTypes(t *testing.T) {
 				Types: map[string]*parser.GoType{},
 			}
 
-			err := ValidateFunctions(parsed)
+			err := ValidateFunctions(parsed, Options{})
 			if err == nil {
 				t.Fatalf("expected error for %s type", tt.name)
 			}
@@ -303,9 +361,9 @@ func TestValidateFunctions_MultipleErrors(t *testing.T) {
 				Name: "Func1",
 				Params: []parser.GoParameter{
 					{Name: "data", Type: parser.GoType{
-						Name:  "map[int]string",
+						Name:  "map[float64]string",
 						Kind:  parser.KindMap,
-						Key:   &parser.GoType{Name: "int", Kind: parser.KindPrimitive},
+						Key:   &parser.GoType{Name: "float64", Kind: parser.KindPrimitive},
 						Value: &parser.GoType{Name: "string", Kind: parser.KindPrimitive},
 					}},
 				},
@@ -315,9 +373,9 @@ func TestValidateFunctions_MultipleErrors(t *testing.T) {
 				Name: "Func2",
 				Params: []parser.GoParameter{
 					{Name: "data", Type: parser.GoType{
-						Name:  "map[bool]int",
+						Name:  "map[float32]int",
 						Kind:  parser.KindMap,
-						Key:   &parser.GoType{Name: "bool", Kind: parser.KindPrimitive},
+						Key:   &parser.GoType{Name: "float32", Kind: parser.KindPrimitive},
 						Value: &parser.GoType{Name: "int", Kind: parser.KindPrimitive},
 					}},
 				},
@@ -327,7 +385,7 @@ func TestValidateFunctions_MultipleErrors(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err == nil {
 		t.Fatal("expected errors")
 	}
@@ -371,7 +429,7 @@ func TestValidateFunctions_VoidCallback(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for void callback, got: %v", err)
 	}
@@ -398,7 +456,7 @@ func TestValidateFunctions_VoidCallbackNoParams(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for void callback with no params, got: %v", err)
 	}
@@ -466,7 +524,7 @@ func TestUnsupportedCallbackPatterns(t *testing.T) {
 				Functions: []parser.GoFunction{tt.fn},
 				Types:     map[string]*parser.GoType{},
 			}
-			err := ValidateFunctions(parsed)
+			err := ValidateFunctions(parsed, Options{})
 			if err == nil {
 				t.Fatal("expected error, got nil")
 			}
@@ -477,6 +535,50 @@ func TestUnsupportedCallbackPatterns(t *testing.T) {
 	}
 }
 
+func TestValidateFunctions_ReturningCallback(t *testing.T) {
+	callback := func(results ...parser.GoType) *parser.ParsedFile {
+		return &parser.ParsedFile{
+			Package: "wasm",
+			Functions: []parser.GoFunction{{
+				Name: "Filter",
+				Params: []parser.GoParameter{{Name: "keep", Type: parser.GoType{
+					Kind:            parser.KindFunction,
+					CallbackParams:  []parser.GoType{{Name: "string", Kind: parser.KindPrimitive}},
+					CallbackResults: results,
+					IsVoid:          len(results) == 0,
+				}}},
+			}},
+			Types: map[string]*parser.GoType{},
+		}
+	}
+	boolType := parser.GoType{Name: "bool", Kind: parser.KindPrimitive}
+
+	if err := ValidateFunctions(callback(boolType), Options{SyncMode: true}); err != nil {
+		t.Errorf("expected no error for returning callback in sync mode, got: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		parsed  *parser.ParsedFile
+		opts    Options
+		wantErr string
+	}{
+		{"worker mode", callback(boolType), Options{}, "only void callbacks are supported in worker mode"},
+		{"multiple results", callback(boolType, boolType), Options{SyncMode: true}, "callbacks may return at most one value"},
+		{"error result", callback(parser.GoType{Name: "error", Kind: parser.KindError, IsError: true}), Options{SyncMode: true}, "returns unsupported type error"},
+		{"unsupported result", callback(parser.GoType{Name: "chan", Kind: parser.KindUnsupported}), Options{SyncMode: true}, "callback return uses unsupported type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFunctions(tt.parsed, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestValidateFunctions_AnonymousFields(t *testing.T) {
 	// Struct with anonymous/embedded field should be rejected
 	parsed := &parser.ParsedFile{
@@ -498,7 +600,7 @@ func TestValidateFunctions_AnonymousFields(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err == nil {
 		t.Fatal("expected error for anonymous field, got nil")
 	}
@@ -507,6 +609,32 @@ func TestValidateFunctions_AnonymousFields(t *testing.T) {
 	}
 }
 
+func TestValidateFunctions_SkippedFields(t *testing.T) {
+	// json:"-" fields never cross the boundary, so their type doesn't matter
+	parsed := &parser.ParsedFile{
+		Package: "wasm",
+		Functions: []parser.GoFunction{
+			{
+				Name: "GetConn",
+				Returns: []parser.GoType{{
+					Name: "Conn",
+					Kind: parser.KindStruct,
+					Fields: []parser.GoField{
+						{Name: "Addr", Type: parser.GoType{Name: "string", Kind: parser.KindPrimitive}},
+						{Name: "Done", Skip: true, Type: parser.GoType{Name: "chan", Kind: parser.KindUnsupported}},
+						{Name: "", Skip: true, Type: parser.GoType{Name: "sync.Mutex", Kind: parser.KindUnsupported}},
+					},
+				}},
+			},
+		},
+		Types: map[string]*parser.GoType{},
+	}
+
+	if err := ValidateFunctions(parsed, Options{}); err != nil {
+		t.Errorf("expected no error for skipped fields, got: %v", err)
+	}
+}
+
 func TestValidateFunctions_ErrorReturnType(t *testing.T) {
 	// KindError should be valid when in return position
 	parsed := &parser.ParsedFile{
@@ -523,7 +651,7 @@ func TestValidateFunctions_ErrorReturnType(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for error return type, got: %v", err)
 	}
@@ -545,7 +673,7 @@ func TestValidateFunctions_UnknownTypeKind(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err == nil {
 		t.Fatal("expected error for unknown type kind, got nil")
 	}
@@ -589,8 +717,174 @@ func TestValidateFunctions_NilElemTypes(t *testing.T) {
 		Types: map[string]*parser.GoType{},
 	}
 
-	err := ValidateFunctions(parsed)
+	err := ValidateFunctions(parsed, Options{})
 	if err != nil {
 		t.Errorf("expected no error for nil elem types, got: %v", err)
 	}
 }
+
+func TestValidateFunctions_DuplicateNames(t *testing.T) {
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Process", Pos: "wasm/a.go:3"},
+			{Name: "Greet", Pos: "wasm/a.go:7"},
+			{Name: "Process", Pos: "wasm/b.go:5"},
+			{Name: "Other"},
+		},
+		Types: map[string]*parser.GoType{},
+	}
+
+	err := ValidateFunctions(parsed, Options{})
+	if err == nil {
+		t.Fatal("expected error for duplicate function names")
+	}
+	var verr ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 1 {
+		t.Fatalf("expected exactly one validation error, got: %v", err)
+	}
+	want := `duplicate JavaScript name "process": declared by Process (wasm/a.go:3), Process (wasm/b.go:5)`
+	if got := verr.Errors[0].Error(); got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
+
+func TestValidateFunctions_JSName(t *testing.T) {
+	tests := []struct {
+		name    string
+		jsName  string
+		wantErr bool
+	}{
+		{"identifier", "httpGet", false},
+		{"dollar and digits", "$get2", false},
+		{"leading digit", "2get", true},
+		{"hyphen", "http-get", true},
+		{"reserved word", "delete", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := &parser.ParsedFile{
+				Package:   "main",
+				Functions: []parser.GoFunction{{Name: "HTTPGet", JSName: tt.jsName}},
+				Types:     map[string]*parser.GoType{},
+			}
+			err := ValidateFunctions(parsed, Options{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateFunctions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "is not a JavaScript identifier") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// A rename that collides with another function's name is a duplicate
+	parsed := &parser.ParsedFile{
+		Package:   "main",
+		Functions: []parser.GoFunction{{Name: "Fetch"}, {Name: "HTTPGet", JSName: "fetch"}},
+		Types:     map[string]*parser.GoType{},
+	}
+	if err := ValidateFunctions(parsed, Options{}); err == nil || !strings.Contains(err.Error(), `duplicate JavaScript name "fetch"`) {
+		t.Errorf("expected a duplicate name error, got: %v", err)
+	}
+}
+
+func TestValidateFunctions_UndefinedTypes(t *testing.T) {
+	point := parser.GoType{Name: "point", Kind: parser.KindPrimitive}
+	shape := parser.GoType{Name: "Shape", Kind: parser.KindPrimitive}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{
+				Name: "Draw",
+				Params: []parser.GoParameter{
+					{Name: "p", Type: point},
+					{Name: "ps", Type: parser.GoType{Name: "[]point", Kind: parser.KindSlice, Elem: &point}},
+					{Name: "size", Type: parser.GoType{Name: "Size", Kind: parser.KindPrimitive, Underlying: "int"}},
+					{Name: "box", Type: parser.GoType{Name: "Box", Kind: parser.KindStruct, Named: true}},
+				},
+				Returns: []parser.GoType{
+					{Name: "*Shape", Kind: parser.KindPointer, Elem: &shape},
+				},
+			},
+		},
+		Types: map[string]*parser.GoType{
+			"Box": {Name: "Box", Kind: parser.KindStruct, Named: true},
+		},
+	}
+
+	err := ValidateFunctions(parsed, Options{})
+	var verr ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 2 {
+		t.Fatalf("expected two validation errors, got: %v", err)
+	}
+	want := []string{
+		"function Draw: parameter p uses type point, which has no exported type definition in the source (unexported types are not converted, rename it to Point)",
+		"function Draw: return type uses type Shape, which has no exported type definition in the source (declare it in the package)",
+	}
+	for i, w := range want {
+		if got := verr.Errors[i].Error(); got != w {
+			t.Errorf("error %d = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestValidateFunctions_Strict(t *testing.T) {
+	empty := parser.GoType{Name: "struct{}", Kind: parser.KindStruct}
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{
+				Name: "Get",
+				Params: []parser.GoParameter{
+					{Name: "ids", Type: parser.GoType{Name: "[]int", Kind: parser.KindSlice, Elem: &parser.GoType{Name: "int", Kind: parser.KindPrimitive}}},
+					{Name: "set", Type: parser.GoType{Name: "map[string]struct{}", Kind: parser.KindMap,
+						Key: &parser.GoType{Name: "string", Kind: parser.KindPrimitive}, Value: &empty}},
+				},
+				Returns: []parser.GoType{
+					{Name: "Box", Kind: parser.KindStruct, Named: true, Fields: []parser.GoField{
+						{Name: "Meta", Type: empty},
+						{Name: "Tags", Type: parser.GoType{Name: "[]struct{}", Kind: parser.KindSlice, Elem: &empty}},
+						{Name: "Internal", Type: empty, Skip: true},
+					}},
+					{Name: "error", Kind: parser.KindError, IsError: true},
+				},
+			},
+			{
+				// A struct result becomes its own interface, so struct{} is fine
+				Name:    "Ping",
+				Returns: []parser.GoType{empty},
+			},
+			{
+				Name:    "Opt",
+				Returns: []parser.GoType{{Name: "*struct{}", Kind: parser.KindPointer, Elem: &empty}},
+			},
+		},
+		Types: map[string]*parser.GoType{},
+	}
+
+	if err := ValidateFunctions(parsed, Options{}); err != nil {
+		t.Fatalf("expected no errors without Strict, got: %v", err)
+	}
+
+	err := ValidateFunctions(parsed, Options{Strict: true})
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got: %v", err)
+	}
+	want := []string{
+		"function Get: parameter set map value resolved to any (--strict)",
+		"function Get: return type field Meta resolved to any (--strict)",
+		"function Get: return type field Tags element resolved to any (--strict)",
+		"function Opt: return type (pointer) resolved to any (--strict)",
+	}
+	if len(verr.Errors) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(verr.Errors), len(want), err)
+	}
+	for i, w := range want {
+		if got := verr.Errors[i].Error(); got != w {
+			t.Errorf("error %d = %q, want %q", i, got, w)
+		}
+	}
+}
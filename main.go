@@ -1,12 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"go/format"
+	"go/token"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	flag "github.com/spf13/pflag"
 
@@ -17,18 +30,75 @@ import (
 
 // Config holds CLI configuration for testability.
 type Config struct {
-	SourceFile string
-	OutputDir  string
-	NoBuild    bool
-	Compiler   string
-	Mode       string
-	ClassName  string
-	Optimize   bool
-	Verbose    bool
-	Stdout     io.Writer
-	Stderr     io.Writer
+	SourceFile      string
+	OutputDir       string
+	NoBuild         bool
+	Compiler        string
+	Mode            string
+	ClassName       string
+	Optimize        bool
+	TinyGoFlags     []string // Appended to the tinygo build arguments
+	PostHook        []string // Command and arguments run in OutputDir once all files are written
+	Verbose         bool
+	EmitDiagnostics bool
+	SplitClient     bool
+	WorkerPool      int
+	EmitMock        bool
+	EmitDemo        bool
+	EmitIndex       bool
+	Manifest        bool
+	SharedMemory    bool
+	StreamBytes     bool
+	EmitChecksum    bool
+	CheckStale      bool
+	Check           bool // Compare the generated files with those on disk instead of writing them
+	Namespace       string
+	BigInt          bool
+	Base64Bytes     bool
+	NoTypedArrays   bool
+	TypedArrayBytes string // Byte order numeric slice parameters are decoded in: "little", "big", or "" for none
+	JSONStructs     bool
+	NilAsNull       bool
+	CommonJS        bool
+	Deno            bool
+	Node            bool
+	Minify          bool
+	ErrorKey        string
+	MethodsOf       string
+	AllowAny        bool
+	Strict          bool
+	StructHelpers   bool
+	ResultUnion     bool
+	ReadonlyReturns bool
+	DtsOnly         bool
+	DryRun          bool
+	List            bool // Print the parsed functions and types and stop
+	GoOutput        string
+	GoPackage       string
+	Entrypoint      string // Directory of the generated main package for a library
+	WasmExec        string
+	MultiSource     bool     // Other sources generate into OutputDir in the same run (see executeSources)
+	NoWasmExec      bool     // Skip copying wasm_exec.js, already copied for an earlier source
+	Summary         *Summary // Filled in by execute when non-nil (see --json)
+	Stdin           io.Reader
+	Stdout          io.Writer
+	Stderr          io.Writer
 }
 
+// Summary is the machine-readable result of a run, printed as JSON to
+// stdout by --json.
+type Summary struct {
+	OK        bool     `json:"ok"`
+	Files     []string `json:"files"`
+	Functions int      `json:"functions"`
+	Types     int      `json:"types"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// jsIdentifier matches names usable as a --namespace property on globalThis
+// and as the --error-key field.
+var jsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -46,51 +116,636 @@ func run() error {
 	var className string
 	var optimize bool
 	var verbose bool
+	var emitDiagnostics bool
+	var splitClient bool
+	var workerPool int
+	var emitMock bool
+	var emitDemo bool
+	var emitIndex bool
+	var manifest bool
+	var sharedMemory bool
+	var streamBytes bool
+	var emitChecksum bool
+	var checkStale bool
+	var check bool
+	var namespace string
+	var bigInt bool
+	var bytesMode string
+	var noTypedArrays bool
+	var typedArrayBytes string
+	var jsonStructs bool
+	var nilAsNull bool
+	var moduleFormat string
+	var target string
+	var minify bool
+	var errorKey string
+	var methodsOf string
+	var allowAny bool
+	var strict bool
+	var structHelpers bool
+	var resultUnion bool
+	var readonlyReturns bool
+	var watch bool
+	var dtsOnly bool
+	var configPath string
+	var dryRun bool
+	var goOutput string
+	var goPackage string
+	var entrypoint string
+	var wasmExec string
+	var tinygoFlags string
+	var postHook string
+	var jsonOutput bool
+	var list bool
 
 	flag.CommandLine.SetInterspersed(true) // Allow flags after positional arguments
 	flag.StringVarP(&outputDir, "output", "o", "generated", "Output directory for all artifacts")
+	flag.StringVar(&goOutput, "go-output", "", "Path of the generated Go bindings (default: bindings_gen.go in the source directory)")
+	flag.StringVar(&goPackage, "package", "", "Package name of the generated Go bindings (default: the source package)")
+	flag.StringVar(&entrypoint, "entrypoint", "", "Directory to generate a main package in that imports a library package, and to build the WASM module from")
 	flag.BoolVar(&noBuild, "no-build", false, "Skip WASM compilation (generate only)")
 	flag.StringVar(&compiler, "compiler", "tinygo", "Compiler: 'tinygo' or 'go'")
+	flag.StringVar(&wasmExec, "wasm-exec", "", "Copy wasm_exec.js from this path instead of asking the compiler for its location")
 	flag.StringVarP(&mode, "mode", "m", "worker", "Generation mode: 'sync' or 'worker'")
 	flag.StringVarP(&className, "class-name", "c", "", "TypeScript class name (default: Go<DirName>)")
 	flag.BoolVar(&optimize, "optimize", true, "Enable size optimizations (tinygo only)")
+	flag.StringVar(&tinygoFlags, "tinygo-flags", "", "Extra space-separated arguments for tinygo build, e.g. \"-scheduler=asyncify -gc=leaking\"")
+	flag.StringVar(&postHook, "post-hook", "", "Command to run in the output directory after all files are written, e.g. \"npx prettier --write .\"")
 	flag.BoolVarP(&verbose, "verbose", "v", false, "Enable verbose debug output")
+	flag.BoolVar(&emitDiagnostics, "emit-diagnostics", false, "Expose Go runtime stats via a stats() client method")
+	flag.BoolVar(&splitClient, "split-client", false, "Emit worker startup in a separate <client>-init.ts for code-splitting (worker mode only)")
+	flag.IntVar(&workerPool, "emit-worker-pool", 0, "Spread calls across a pool of N workers (worker mode only)")
+	flag.IntVar(&workerPool, "workers", 0, "Alias for --emit-worker-pool")
+	flag.BoolVar(&emitMock, "emit-mock", false, "Emit a <client>-mock.ts test double that runs without WASM")
+	flag.BoolVar(&emitDemo, "emit-demo", false, "Emit an index.html that exposes the client on window.wasm for manual testing")
+	flag.BoolVar(&manifest, "manifest", false, "Write a manifest.json listing each registered function with its TypeScript parameter and return types")
+	flag.BoolVar(&emitIndex, "emit-index", false, "Add the client to an index.ts barrel in the output directory, keeping other clients' exports")
+	flag.BoolVar(&sharedMemory, "shared-memory", false, "Return []byte and numeric slice results in SharedArrayBuffer-backed typed arrays when cross-origin isolated")
+	flag.BoolVar(&sharedMemory, "shared-buffer", false, "Alias for --shared-memory")
+	flag.BoolVar(&streamBytes, "stream-bytes", false, "Post []byte results over 1 MiB to the client in transferred chunks (worker mode only)")
+	flag.BoolVar(&emitChecksum, "emit-checksum", false, "Record a checksum of the source file in generated file headers")
+	flag.BoolVar(&checkStale, "check-stale", false, "Verify generated files match the source checksum without regenerating")
+	flag.BoolVar(&check, "check", false, "Generate in memory and fail if any generated file on disk differs, without writing or building")
+	flag.StringVar(&namespace, "namespace", "", "Register functions on this global object instead of the global scope")
+	flag.BoolVar(&bigInt, "bigint", false, "Map int64 and uint64 to TypeScript bigint instead of number")
+	flag.StringVar(&bytesMode, "bytes", "uint8array", "How []byte crosses to JS: 'uint8array' or 'base64' (string)")
+	flag.BoolVar(&noTypedArrays, "no-typed-arrays", false, "Exchange numeric slices such as []float64 as number[] instead of typed arrays ([]byte follows --bytes)")
+	flag.StringVar(&typedArrayBytes, "typed-array-bytes", "", "Decode numeric slice parameters such as []int32 from the bytes of any typed array or DataView in this byte order: 'little' or 'big'")
+	flag.BoolVar(&nilAsNull, "nil-as-null", false, "Return nil slices and maps as null instead of an empty array or object")
+	flag.BoolVar(&jsonStructs, "json-structs", false, "Convert structs with encoding/json instead of field by field")
+	flag.StringVar(&moduleFormat, "module", "esm", "Module format of the TypeScript client: 'esm' or 'commonjs' (module.exports)")
+	flag.StringVar(&target, "target", "web", "Runtime the client is for: 'web' (browsers, bundlers), 'deno', or 'node' (sync mode only)")
+	flag.BoolVar(&minify, "minify", false, "Strip comments and indentation from the generated client and worker.js, keeping the header")
+	flag.BoolVar(&allowAny, "allow-any", false, "Accept interface{}/any types, exchanged with JS as JSON values (TypeScript unknown)")
+	flag.BoolVar(&strict, "strict", false, "Fail on any type that would be emitted as TypeScript any, listing each one")
+	flag.StringVar(&errorKey, "error-key", generator.ErrorFieldName, "Field of the object that carries a Go error to the client")
+	flag.StringVar(&methodsOf, "methods-of", "", "Also bind the exported methods of this type, called on a package-level instance of it")
+	flag.BoolVar(&resultUnion, "result-union", false, "Return { ok, value } | { ok, error } objects from functions with an error result instead of rejecting")
+	flag.BoolVar(&readonlyReturns, "readonly-returns", false, "Declare function results with readonly TypeScript types (ReadonlyArray, Readonly<T>)")
+	flag.BoolVar(&structHelpers, "helpers", false, "Convert named structs through one helper function pair per type instead of inline code")
+	flag.BoolVar(&watch, "watch", false, "Regenerate whenever a .go file in the source directory changes")
+	flag.BoolVar(&dtsOnly, "dts-only", false, "Emit an ambient <client>.d.ts for the globals instead of a client class (sync mode only)")
+	flag.BoolVar(&list, "list", false, "Print the functions and types found in the source with their TypeScript signatures, without generating anything")
+	flag.BoolVar(&dryRun, "dry-run", false, "Generate and report the files that would be written, without writing or building")
+	flag.BoolVar(&jsonOutput, "json", false, "Print a JSON summary of the run to stdout, moving progress output to stderr")
+	flag.StringVar(&configPath, "config", "", "Read default flag values from this JSON file (default: "+configFileName+" in the source directory)")
 	flag.Parse()
 
 	// Validate flags
-	usage := "Usage: gowasm-bindgen <source.go> [-o generated] [--no-build] [--compiler tinygo|go] [-m sync|worker] [-c ClassName]"
+	usage := "Usage: gowasm-bindgen <source.go|dir|->... [-o generated] [--no-build] [--compiler tinygo|go] [-m sync|worker] [-c ClassName]"
 	if flag.NArg() == 0 {
 		return fmt.Errorf("missing source file argument\n\n%s", usage)
 	}
+
+	// Fill in flags not given on the command line from the config file
+	if configPath == "" {
+		configPath = findConfigFile(flag.Arg(0))
+	}
+	if configPath != "" {
+		if err := applyConfigFile(flag.CommandLine, configPath); err != nil {
+			return err
+		}
+	}
+	// With --json, stdout carries only the summary
+	stdout := io.Writer(os.Stdout)
+	if jsonOutput {
+		stdout = os.Stderr
+	}
+	if configPath != "" {
+		fmt.Fprintf(stdout, "Using config %s\n", configPath) //nolint:errcheck
+	}
 	if mode != "sync" && mode != "worker" {
 		return fmt.Errorf("--mode must be 'sync' or 'worker', got %q\n\n%s", mode, usage)
 	}
 	if compiler != "tinygo" && compiler != "go" {
 		return fmt.Errorf("--compiler must be 'tinygo' or 'go', got %q\n\n%s", compiler, usage)
 	}
+	if tinygoFlags != "" && compiler != "tinygo" {
+		return fmt.Errorf("--tinygo-flags requires --compiler tinygo\n\n%s", usage)
+	}
+	if err := validateTinyGoFlags(strings.Fields(tinygoFlags)); err != nil {
+		return fmt.Errorf("%w\n\n%s", err, usage)
+	}
+	if splitClient && mode != "worker" {
+		return fmt.Errorf("--split-client requires --mode worker\n\n%s", usage)
+	}
+	// Report pool errors under whichever spelling of the flag was given
+	poolFlag := "--emit-worker-pool"
+	if flag.CommandLine.Changed("workers") {
+		poolFlag = "--workers"
+	}
+	if workerPool < 0 {
+		return fmt.Errorf("%s must be positive, got %d\n\n%s", poolFlag, workerPool, usage)
+	}
+	if workerPool > 0 && mode != "worker" {
+		return fmt.Errorf("%s requires --mode worker\n\n%s", poolFlag, usage)
+	}
+	if workerPool > 0 && splitClient {
+		return fmt.Errorf("%s cannot be combined with --split-client\n\n%s", poolFlag, usage)
+	}
+	if dtsOnly && mode != "sync" {
+		return fmt.Errorf("--dts-only requires --mode sync\n\n%s", usage)
+	}
+	if dtsOnly && emitMock {
+		return fmt.Errorf("--dts-only cannot be combined with --emit-mock\n\n%s", usage)
+	}
+	if dtsOnly && emitIndex {
+		return fmt.Errorf("--dts-only cannot be combined with --emit-index\n\n%s", usage)
+	}
+	if dtsOnly && emitDemo {
+		return fmt.Errorf("--dts-only cannot be combined with --emit-demo\n\n%s", usage)
+	}
+	// Each source generates into the output directory under its own names
+	if flag.NArg() > 1 {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"--class-name", className != ""},
+			{"--go-output", goOutput != ""},
+			{"--entrypoint", entrypoint != ""},
+			{"--watch", watch},
+			{"--emit-demo", emitDemo},
+		} {
+			if f.set {
+				return fmt.Errorf("%s cannot be used with several sources\n\n%s", f.name, usage)
+			}
+		}
+		for _, source := range flag.Args() {
+			if source == stdinSource {
+				return fmt.Errorf("source from stdin cannot be combined with other sources\n\n%s", usage)
+			}
+		}
+	}
+	if watch && flag.Arg(0) == stdinSource {
+		return fmt.Errorf("--watch cannot be used when reading source from stdin\n\n%s", usage)
+	}
+	if watch && checkStale {
+		return fmt.Errorf("--watch cannot be combined with --check-stale\n\n%s", usage)
+	}
+	if watch && jsonOutput {
+		return fmt.Errorf("--watch cannot be combined with --json\n\n%s", usage)
+	}
+	if list && watch {
+		return fmt.Errorf("--list cannot be combined with --watch\n\n%s", usage)
+	}
+	if list && checkStale {
+		return fmt.Errorf("--list cannot be combined with --check-stale\n\n%s", usage)
+	}
+	if check && watch {
+		return fmt.Errorf("--check cannot be combined with --watch\n\n%s", usage)
+	}
+	if check && list {
+		return fmt.Errorf("--check cannot be combined with --list\n\n%s", usage)
+	}
+	if check && checkStale {
+		return fmt.Errorf("--check cannot be combined with --check-stale\n\n%s", usage)
+	}
+	if check && dryRun {
+		return fmt.Errorf("--check cannot be combined with --dry-run\n\n%s", usage)
+	}
+	if list && jsonOutput {
+		return fmt.Errorf("--list cannot be combined with --json\n\n%s", usage)
+	}
+	if bytesMode != "uint8array" && bytesMode != "base64" {
+		return fmt.Errorf("--bytes must be 'uint8array' or 'base64', got %q\n\n%s", bytesMode, usage)
+	}
+	// Report shared memory errors under whichever spelling of the flag was given
+	sharedFlag := "--shared-memory"
+	if flag.CommandLine.Changed("shared-buffer") {
+		sharedFlag = "--shared-buffer"
+	}
+	if bytesMode == "base64" && sharedMemory {
+		return fmt.Errorf("%s cannot be combined with --bytes base64\n\n%s", sharedFlag, usage)
+	}
+	if streamBytes && mode != "worker" {
+		return fmt.Errorf("--stream-bytes requires --mode worker\n\n%s", usage)
+	}
+	if streamBytes && sharedMemory {
+		return fmt.Errorf("--stream-bytes cannot be combined with %s\n\n%s", sharedFlag, usage)
+	}
+	if streamBytes && bytesMode == "base64" {
+		return fmt.Errorf("--stream-bytes cannot be combined with --bytes base64\n\n%s", usage)
+	}
+	if streamBytes && resultUnion {
+		return fmt.Errorf("--stream-bytes cannot be combined with --result-union\n\n%s", usage)
+	}
+	// A streamed result is reassembled into a Uint8Array even when nil
+	if streamBytes && nilAsNull {
+		return fmt.Errorf("--stream-bytes cannot be combined with --nil-as-null\n\n%s", usage)
+	}
+	if typedArrayBytes != "" && typedArrayBytes != "little" && typedArrayBytes != "big" {
+		return fmt.Errorf("--typed-array-bytes must be 'little' or 'big', got %q\n\n%s", typedArrayBytes, usage)
+	}
+	if typedArrayBytes != "" && noTypedArrays {
+		return fmt.Errorf("--typed-array-bytes cannot be combined with --no-typed-arrays\n\n%s", usage)
+	}
+	if moduleFormat != "esm" && moduleFormat != "commonjs" {
+		return fmt.Errorf("--module must be 'esm' or 'commonjs', got %q\n\n%s", moduleFormat, usage)
+	}
+	// These emit files that import or re-export the client with ESM syntax
+	if moduleFormat == "commonjs" {
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{{"--split-client", splitClient}, {"--emit-mock", emitMock}, {"--emit-index", emitIndex}, {"--emit-demo", emitDemo}, {"--dts-only", dtsOnly}} {
+			if f.set {
+				return fmt.Errorf("--module commonjs cannot be combined with %s\n\n%s", f.name, usage)
+			}
+		}
+	}
+	if target != "web" && target != "deno" && target != "node" {
+		return fmt.Errorf("--target must be 'web', 'deno', or 'node', got %q\n\n%s", target, usage)
+	}
+	// Deno loads ES modules only, and the demo page is for browsers
+	if target == "deno" && moduleFormat == "commonjs" {
+		return fmt.Errorf("--target deno cannot be combined with --module commonjs\n\n%s", usage)
+	}
+	if target == "deno" && emitDemo {
+		return fmt.Errorf("--target deno cannot be combined with --emit-demo\n\n%s", usage)
+	}
+	// Node.js has no Web Worker API, and the client loads wasm_exec.js itself
+	if target == "node" && mode != "sync" {
+		return fmt.Errorf("--target node requires --mode sync\n\n%s", usage)
+	}
+	if target == "node" && (emitDemo || dtsOnly) {
+		flagName := "--emit-demo"
+		if dtsOnly {
+			flagName = "--dts-only"
+		}
+		return fmt.Errorf("--target node cannot be combined with %s\n\n%s", flagName, usage)
+	}
+	if !jsIdentifier.MatchString(errorKey) {
+		return fmt.Errorf("--error-key must be a JavaScript identifier, got %q\n\n%s", errorKey, usage)
+	}
+	if methodsOf != "" && !token.IsIdentifier(methodsOf) {
+		return fmt.Errorf("--methods-of must be a Go type name, got %q\n\n%s", methodsOf, usage)
+	}
+	if goPackage != "" && (!token.IsIdentifier(goPackage) || goPackage == "_") {
+		return fmt.Errorf("--package must be a Go identifier, got %q\n\n%s", goPackage, usage)
+	}
+	// The entrypoint imports the bindings from the library package
+	if entrypoint != "" && goPackage != "" {
+		return fmt.Errorf("--entrypoint cannot be combined with --package\n\n%s", usage)
+	}
+	if namespace != "" && !jsIdentifier.MatchString(namespace) {
+		return fmt.Errorf("--namespace must be a JavaScript identifier, got %q\n\n%s", namespace, usage)
+	}
 
 	cfg := Config{
-		SourceFile: flag.Arg(0),
-		OutputDir:  outputDir,
-		NoBuild:    noBuild,
-		Compiler:   compiler,
-		Mode:       mode,
-		ClassName:  className,
-		Optimize:   optimize,
-		Verbose:    verbose,
-		Stdout:     os.Stdout,
-		Stderr:     os.Stderr,
+		SourceFile:      flag.Arg(0),
+		OutputDir:       outputDir,
+		NoBuild:         noBuild,
+		Compiler:        compiler,
+		Mode:            mode,
+		ClassName:       className,
+		Optimize:        optimize,
+		TinyGoFlags:     strings.Fields(tinygoFlags),
+		PostHook:        strings.Fields(postHook),
+		Verbose:         verbose,
+		EmitDiagnostics: emitDiagnostics,
+		SplitClient:     splitClient,
+		WorkerPool:      workerPool,
+		EmitMock:        emitMock,
+		EmitDemo:        emitDemo,
+		EmitIndex:       emitIndex,
+		Manifest:        manifest,
+		SharedMemory:    sharedMemory,
+		StreamBytes:     streamBytes,
+		EmitChecksum:    emitChecksum,
+		CheckStale:      checkStale,
+		Check:           check,
+		Namespace:       namespace,
+		BigInt:          bigInt,
+		Base64Bytes:     bytesMode == "base64",
+		NoTypedArrays:   noTypedArrays,
+		TypedArrayBytes: typedArrayBytes,
+		JSONStructs:     jsonStructs,
+		NilAsNull:       nilAsNull,
+		CommonJS:        moduleFormat == "commonjs",
+		Deno:            target == "deno",
+		Node:            target == "node",
+		Minify:          minify,
+		ErrorKey:        errorKey,
+		MethodsOf:       methodsOf,
+		AllowAny:        allowAny,
+		Strict:          strict,
+		StructHelpers:   structHelpers,
+		ResultUnion:     resultUnion,
+		ReadonlyReturns: readonlyReturns,
+		DryRun:          dryRun,
+		List:            list,
+		DtsOnly:         dtsOnly,
+		GoOutput:        goOutput,
+		GoPackage:       goPackage,
+		Entrypoint:      entrypoint,
+		WasmExec:        wasmExec,
+		Stdin:           os.Stdin,
+		Stdout:          stdout,
+		Stderr:          os.Stderr,
+	}
+
+	if watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return watchSource(ctx, cfg, watchInterval)
+	}
+	if jsonOutput {
+		return executeJSON(cfg, flag.Args(), os.Stdout)
+	}
+	return executeSources(cfg, flag.Args())
+}
+
+// executeSources runs execute for each source in turn, stopping at the
+// first error. Several sources share the output directory, so each needs a
+// directory name of its own, which names its module, client, and worker.
+func executeSources(cfg Config, sources []string) error {
+	if len(sources) == 1 {
+		cfg.SourceFile = sources[0]
+		return execute(cfg)
+	}
+
+	seen := make(map[string]string)
+	for _, source := range sources {
+		dir := source
+		if info, err := os.Stat(source); err != nil || !info.IsDir() {
+			dir = filepath.Dir(source)
+		}
+		name := moduleName(dir)
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("%s and %s would both generate %s.wasm: "+
+				"several sources must be in directories with different names", prev, source, name)
+		}
+		seen[name] = source
 	}
 
-	return execute(cfg)
+	for i, source := range sources {
+		c := cfg
+		c.SourceFile = source
+		c.MultiSource = true
+		c.NoWasmExec = i > 0
+		// Run the hook once, after the last source
+		if i < len(sources)-1 {
+			c.PostHook = nil
+		}
+		if err := execute(c); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+	}
+	return nil
+}
+
+// moduleName returns the name of the WASM module built from sourceDir,
+// which the client class name is also derived from.
+func moduleName(sourceDir string) string {
+	name := filepath.Base(sourceDir)
+	if name == "." || name == "" {
+		return "main"
+	}
+	return name
+}
+
+// executeJSON runs executeSources and writes its Summary to stdout as JSON,
+// also when it fails. The error is still returned for the exit code.
+func executeJSON(cfg Config, sources []string, stdout io.Writer) error {
+	summary := &Summary{Files: []string{}}
+	cfg.Summary = summary
+	err := executeSources(cfg, sources)
+	summary.OK = err == nil
+	if err != nil {
+		var verr validator.ValidationError
+		if errors.As(err, &verr) {
+			for _, e := range verr.Errors {
+				summary.Errors = append(summary.Errors, e.Error())
+			}
+		} else {
+			summary.Errors = []string{err.Error()}
+		}
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(summary); encErr != nil {
+		return fmt.Errorf("writing JSON summary: %w", encErr)
+	}
+	return err
+}
+
+// stdinSource is the source argument that reads Go source from stdin.
+const stdinSource = "-"
+
+// configFileName is the config file loaded from the source directory when
+// --config is not given.
+const configFileName = "gowasm-bindgen.json"
+
+// flagAliases pairs flags that set the same value, so a config file entry
+// for one doesn't override the other given on the command line.
+var flagAliases = map[string]string{
+	"emit-worker-pool": "workers",
+	"workers":          "emit-worker-pool",
+}
+
+// findConfigFile returns the path of the config file in the directory of
+// source (the working directory when reading stdin), or "" if there is none.
+func findConfigFile(source string) string {
+	dir := "."
+	if source != stdinSource {
+		dir = source
+		if info, err := os.Stat(source); err != nil || !info.IsDir() {
+			dir = filepath.Dir(source)
+		}
+	}
+	path := filepath.Join(dir, configFileName)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// applyConfigFile sets each flag named in the JSON object at path, unless it
+// was already given on the command line. Keys are long flag names without
+// dashes, e.g. {"mode": "sync", "workers": 4, "emit-mock": true}.
+func applyConfigFile(flags *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // path is the user's config file
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if flags.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("config %s: unknown option %q", path, name)
+		}
+		if flags.Changed(name) || (flagAliases[name] != "" && flags.Changed(flagAliases[name])) {
+			continue
+		}
+
+		var value string
+		switch v := values[name].(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Errorf("config %s: option %q must be a string, number, or boolean", path, name)
+		}
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("config %s: option %q: %w", path, name, err)
+		}
+	}
+	return nil
+}
+
+// watchInterval is how often --watch polls the source directory. A change
+// is only acted on after one further quiet interval, which debounces the
+// burst of writes editors make when saving.
+const watchInterval = 500 * time.Millisecond
+
+// watchSource runs execute once, then again whenever a .go file in the
+// source directory changes, until ctx is cancelled. Generation errors are
+// reported without stopping the watch.
+func watchSource(ctx context.Context, cfg Config, interval time.Duration) error {
+	dir := cfg.SourceFile
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	if err := execute(cfg); err != nil {
+		fmt.Fprintf(cfg.Stderr, "error: %v\n", err) //nolint:errcheck
+	}
+	last, err := sourceModTimes(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cfg.Stdout, "\nWatching %s for changes (Ctrl+C to stop)...\n", dir) //nolint:errcheck
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := sourceModTimes(dir)
+		if err != nil {
+			return err
+		}
+		if !maps.Equal(current, last) {
+			last = current
+			pending = true
+			continue
+		}
+		if !pending {
+			continue
+		}
+
+		pending = false
+		if err := execute(cfg); err != nil {
+			fmt.Fprintf(cfg.Stderr, "error: %v\n", err) //nolint:errcheck
+			continue
+		}
+		fmt.Fprintf(cfg.Stdout, "\nRegenerated at %s\n", time.Now().Format("15:04:05")) //nolint:errcheck
+	}
+}
+
+// sourceModTimes returns the modification time of each .go file in dir,
+// skipping the generated bindings so regenerating doesn't retrigger itself.
+func sourceModTimes(dir string) (map[string]time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading source directory: %w", err)
+	}
+
+	times := make(map[string]time.Time)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || name == "bindings_gen.go" ||
+			parser.IsGeneratedBindings(filepath.Join(dir, name)) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed between ReadDir and Info; the next poll sees the removal
+			continue
+		}
+		times[name] = info.ModTime()
+	}
+	return times, nil
 }
 
 // execute runs the generator with the given configuration.
 // This is separated from run() for testability.
 func execute(cfg Config) error {
+	// Source from stdin has no directory to compile or name the class after,
+	// so bindings go to the output directory and the build is skipped
+	fromStdin := cfg.SourceFile == stdinSource
+	sourceName := cfg.SourceFile
+	var stdinSrc []byte
+	var info os.FileInfo
+	var err error
+	if fromStdin {
+		if cfg.Entrypoint != "" {
+			return fmt.Errorf("--entrypoint needs the library's source file or directory, not stdin")
+		}
+		if cfg.ClassName == "" {
+			return fmt.Errorf("--class-name is required when reading source from stdin")
+		}
+		sourceName = "<stdin>"
+		stdinSrc, err = io.ReadAll(cfg.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading source from stdin: %w", err)
+		}
+		cfg.NoBuild = true
+	} else {
+		// Check if source file exists; a directory selects the whole package
+		info, err = os.Stat(cfg.SourceFile)
+		if err != nil {
+			return fmt.Errorf("source file not found: %s", cfg.SourceFile)
+		}
+	}
+
 	sourceDir := filepath.Dir(cfg.SourceFile)
-	dirName := filepath.Base(sourceDir)
-	if dirName == "." || dirName == "" {
+	sourceFiles := []string{cfg.SourceFile}
+	if fromStdin {
+		sourceDir = cfg.OutputDir
+		sourceFiles = nil
+	} else if info.IsDir() {
+		sourceDir = filepath.Clean(cfg.SourceFile)
+		sourceFiles, err = parser.PackageFiles(sourceDir)
+		if err != nil {
+			return fmt.Errorf("listing package files: %w", err)
+		}
+	}
+	dirName := moduleName(sourceDir)
+	if fromStdin {
 		dirName = "main"
 	}
 
@@ -102,14 +757,30 @@ func execute(cfg Config) error {
 
 	// Derive output paths
 	tsFilename := generator.ToKebabCase(className) + ".ts"
+	if cfg.DtsOnly {
+		tsFilename = generator.ToKebabCase(className) + ".d.ts"
+	}
 	tsOutput := filepath.Join(cfg.OutputDir, tsFilename)
-	goOutput := filepath.Join(sourceDir, "bindings_gen.go")
+	goOutput := cfg.GoOutput
+	if goOutput == "" {
+		goOutput = filepath.Join(sourceDir, "bindings_gen.go")
+	}
+	buildDir := sourceDir
+	if cfg.Entrypoint != "" {
+		buildDir = cfg.Entrypoint
+	}
 	wasmFile := filepath.Join(cfg.OutputDir, dirName+".wasm")
 	wasmURL := dirName + ".wasm"
+	// worker.js loads one module, so modules sharing the output directory
+	// each get their own
+	workerFile := filepath.Join(cfg.OutputDir, "worker.js")
+	if cfg.MultiSource {
+		workerFile = filepath.Join(cfg.OutputDir, dirName+"-worker.js")
+	}
 
 	if cfg.Verbose {
 		//nolint:errcheck // debug output errors are not critical
-		fmt.Fprintf(cfg.Stderr, "[DEBUG] Source file: %s\n", cfg.SourceFile)
+		fmt.Fprintf(cfg.Stderr, "[DEBUG] Source file: %s\n", sourceName)
 		fmt.Fprintf(cfg.Stderr, "[DEBUG] Source dir: %s\n", sourceDir)     //nolint:errcheck
 		fmt.Fprintf(cfg.Stderr, "[DEBUG] Output dir: %s\n", cfg.OutputDir) //nolint:errcheck
 		fmt.Fprintf(cfg.Stderr, "[DEBUG] TS output: %s\n", tsOutput)       //nolint:errcheck
@@ -122,17 +793,78 @@ func execute(cfg Config) error {
 		fmt.Fprintf(cfg.Stderr, "[DEBUG] No build: %v\n", cfg.NoBuild)     //nolint:errcheck
 	}
 
-	// Check if source file exists
-	if _, err := os.Stat(cfg.SourceFile); err != nil {
-		return fmt.Errorf("source file not found: %s", cfg.SourceFile)
+	// readSource returns the source bytes the checksum covers
+	readSource := func() ([]byte, error) {
+		if fromStdin {
+			return stdinSrc, nil
+		}
+		return readSources(sourceFiles)
+	}
+
+	if cfg.CheckStale {
+		src, err := readSource()
+		if err != nil {
+			return err
+		}
+		return checkStale(src, sourceName, []string{goOutput, tsOutput}, cfg.Stdout)
 	}
 
-	// Parse source file
-	fmt.Fprintf(cfg.Stdout, "Parsing %s...\n", cfg.SourceFile) //nolint:errcheck
-	parsed, err := parser.ParseSourceFile(cfg.SourceFile)
+	// Parse source file or package directory
+	if !cfg.List {
+		fmt.Fprintf(cfg.Stdout, "Parsing %s...\n", sourceName) //nolint:errcheck
+	}
+	var parsed *parser.ParsedFile
+	if fromStdin {
+		parsed, err = parser.ParseSource(bytes.NewReader(stdinSrc), sourceName)
+	} else if info.IsDir() {
+		parsed, err = parser.ParsePackageDir(sourceDir)
+	} else {
+		parsed, err = parser.ParseSourceFile(cfg.SourceFile)
+	}
 	if err != nil {
 		return fmt.Errorf("parsing source file: %w", err)
 	}
+	if cfg.MethodsOf != "" {
+		if err := parser.UseMethodsOf(parsed, cfg.MethodsOf); err != nil {
+			return fmt.Errorf("--methods-of: %w", err)
+		}
+	}
+	if cfg.BigInt {
+		parser.UseBigInt(parsed)
+	}
+	if cfg.Base64Bytes {
+		parser.UseBase64Bytes(parsed)
+	}
+	if cfg.NoTypedArrays {
+		parser.UsePlainArrays(parsed)
+	}
+	if cfg.TypedArrayBytes != "" {
+		parser.UseTypedArrayBytes(parsed, cfg.TypedArrayBytes)
+	}
+	if cfg.StructHelpers {
+		parser.UseStructHelpers(parsed)
+	}
+	if cfg.ResultUnion {
+		parser.UseResultUnion(parsed)
+	}
+	if cfg.ReadonlyReturns {
+		parser.UseReadonlyReturns(parsed)
+	}
+	if cfg.NilAsNull {
+		parser.UseNilAsNull(parsed)
+	}
+	// Runs last, since it overrides how the types nested in structs cross
+	if cfg.JSONStructs {
+		parser.UseJSONStructs(parsed)
+	}
+	if cfg.List {
+		fmt.Fprint(cfg.Stdout, generator.GenerateListing(parsed)) //nolint:errcheck
+		return nil
+	}
+	if cfg.Summary != nil {
+		cfg.Summary.Functions += len(parsed.Functions)
+		cfg.Summary.Types += len(parsed.Types)
+	}
 
 	fmt.Fprintf(cfg.Stdout, "Package: %s\n", parsed.Package)                           //nolint:errcheck
 	fmt.Fprintf(cfg.Stdout, "Found %d exported function(s):\n", len(parsed.Functions)) //nolint:errcheck
@@ -149,80 +881,220 @@ func execute(cfg Config) error {
 
 	if len(parsed.Functions) == 0 {
 		return fmt.Errorf("no exported functions found in %s\n\n"+
-			"Functions must be exported (start with uppercase letter) and have no receiver", cfg.SourceFile)
+			"Functions must be exported (start with uppercase letter) and have no receiver", sourceName)
+	}
+
+	if cfg.Entrypoint != "" && parsed.Package == "main" {
+		return fmt.Errorf("--entrypoint is for library packages, but %s is package main", sourceName)
 	}
 
 	// Check for select {} in main (required for WASM to stay alive)
 	if parsed.Package == "main" {
-		hasSelect, err := parser.HasSelectInMain(cfg.SourceFile)
-		if err != nil {
-			return fmt.Errorf("checking for select {}: %w", err)
+		hasSelect := false
+		if fromStdin {
+			hasSelect, err = parser.HasSelectInMainSource(bytes.NewReader(stdinSrc), sourceName)
+			if err != nil {
+				return fmt.Errorf("checking for select {}: %w", err)
+			}
+		}
+		for _, path := range sourceFiles {
+			found, err := parser.HasSelectInMain(path)
+			if err != nil {
+				return fmt.Errorf("checking for select {}: %w", err)
+			}
+			hasSelect = hasSelect || found
 		}
 		if !hasSelect {
-			return fmt.Errorf("main() does not contain 'select {}' - " +
+			return fmt.Errorf("main() does not contain 'select {}' or a channel receive such as '<-make(chan struct{})' - " +
 				"WASM modules require this to block forever and receive JavaScript calls - " +
 				"add 'select {}' at the end of your main() function")
 		}
 	}
 
 	// Validate functions
-	if err := validator.ValidateFunctions(parsed); err != nil {
+	if err := validator.ValidateFunctions(parsed, validator.Options{SyncMode: cfg.Mode == "sync", AllowAny: cfg.AllowAny, Strict: cfg.Strict}); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
 	// Create output directory
-	if err := os.MkdirAll(cfg.OutputDir, 0750); err != nil {
-		return fmt.Errorf("creating output directory: %w", err)
+	w := fileWriter{dryRun: cfg.DryRun || cfg.Check, minify: cfg.Minify, stdout: cfg.Stdout}
+	if cfg.Summary != nil {
+		w.files = &cfg.Summary.Files
+	}
+	var changed []string
+	if cfg.Check {
+		w.changed = &changed
+	}
+	if !w.dryRun {
+		if err := os.MkdirAll(cfg.OutputDir, 0750); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
 	}
 
 	// Generate Go bindings
 	fmt.Fprintf(cfg.Stdout, "\nGenerating Go bindings...\n") //nolint:errcheck
-	workerMode := cfg.Mode == "worker"
-	bindingsCode := generator.GenerateGoBindings(parsed, workerMode)
+	opts := generator.Options{
+		WorkerMode:   cfg.Mode == "worker",
+		Diagnostics:  cfg.EmitDiagnostics,
+		SplitClient:  cfg.SplitClient,
+		WorkerPool:   cfg.WorkerPool,
+		SharedMemory: cfg.SharedMemory,
+		StreamBytes:  cfg.StreamBytes,
+		Namespace:    cfg.Namespace,
+		GoPackage:    cfg.GoPackage,
+		CommonJS:     cfg.CommonJS,
+		Deno:         cfg.Deno,
+		Node:         cfg.Node,
+		ErrorKey:     cfg.ErrorKey,
+	}
+	if cfg.EmitChecksum {
+		src, err := readSource()
+		if err != nil {
+			return err
+		}
+		opts.SourceChecksum = generator.ChecksumSource(src)
+	}
+	// Format like gofmt; a failure here means the generator produced invalid Go
+	bindingsCode, err := format.Source([]byte(generator.GenerateGoBindings(parsed, opts)))
+	if err != nil {
+		return fmt.Errorf("formatting generated Go bindings: %w", err)
+	}
 
-	if err := os.WriteFile(goOutput, []byte(bindingsCode), 0644); err != nil { //nolint:gosec // generated source files should be readable
+	if err := w.WriteFile(goOutput, bindingsCode); err != nil {
 		return fmt.Errorf("writing Go bindings: %w", err)
 	}
-	fmt.Fprintf(cfg.Stdout, "Generated %s\n", goOutput) //nolint:errcheck
+	if !w.dryRun {
+		fmt.Fprintf(cfg.Stdout, "Generated %s\n", goOutput) //nolint:errcheck
+	}
+
+	if cfg.Entrypoint != "" {
+		entryPath, err := generateEntrypointOutput(w, sourceDir, cfg.Entrypoint)
+		if err != nil {
+			return err
+		}
+		if !w.dryRun {
+			fmt.Fprintf(cfg.Stdout, "Generated %s (entrypoint)\n", entryPath) //nolint:errcheck
+		}
+	}
 
 	// Generate TypeScript client
-	if cfg.Mode == "sync" {
+	if cfg.DtsOnly {
+		if err := generateDeclarationsOutput(w, parsed, tsOutput, opts); err != nil {
+			return err
+		}
+	} else if cfg.Mode == "sync" {
 		if cfg.Verbose {
 			fmt.Fprintf(cfg.Stderr, "[DEBUG] Generating sync mode client\n") //nolint:errcheck
 		}
-		if err := generateSyncOutput(parsed, tsOutput, className); err != nil {
+		if err := generateSyncOutput(w, parsed, tsOutput, className, opts); err != nil {
 			return err
 		}
 	} else {
 		if cfg.Verbose {
 			fmt.Fprintf(cfg.Stderr, "[DEBUG] Generating worker mode client\n") //nolint:errcheck
 		}
-		if err := generateWorkerOutput(parsed, tsOutput, wasmURL, className); err != nil {
+		if err := generateWorkerOutput(w, parsed, tsOutput, workerFile, wasmURL, className, opts); err != nil {
+			return err
+		}
+	}
+
+	// Generate mock client for tests
+	if cfg.EmitMock {
+		mockPath, err := generateMockOutput(w, parsed, tsOutput, className, opts)
+		if err != nil {
+			return err
+		}
+		if !w.dryRun {
+			fmt.Fprintf(cfg.Stdout, "Generated %s (test mock)\n", mockPath) //nolint:errcheck
+		}
+	}
+
+	if cfg.EmitDemo {
+		initURL := wasmURL
+		if cfg.Mode == "worker" {
+			initURL = filepath.Base(workerFile)
+		}
+		demoPath, err := generateDemoOutput(w, parsed, tsOutput, initURL, className, opts)
+		if err != nil {
+			return err
+		}
+		if !w.dryRun {
+			fmt.Fprintf(cfg.Stdout, "Generated %s (manual test page)\n", demoPath) //nolint:errcheck
+		}
+	}
+
+	if cfg.EmitIndex {
+		indexPath, err := generateIndexOutput(w, parsed, tsOutput, className, opts)
+		if err != nil {
 			return err
 		}
+		if !w.dryRun {
+			fmt.Fprintf(cfg.Stdout, "Updated %s\n", indexPath) //nolint:errcheck
+		}
+	}
+
+	if cfg.Manifest {
+		// Like worker.js, each module sharing the output directory gets its own
+		manifestPath := filepath.Join(cfg.OutputDir, generator.ManifestFileName)
+		if cfg.MultiSource {
+			manifestPath = filepath.Join(cfg.OutputDir, dirName+"-"+generator.ManifestFileName)
+		}
+		if err := w.WriteFile(manifestPath, []byte(generator.GenerateManifest(parsed, opts))); err != nil {
+			return fmt.Errorf("writing manifest: %w", err)
+		}
+		if !w.dryRun {
+			fmt.Fprintf(cfg.Stdout, "Generated %s (function manifest)\n", manifestPath) //nolint:errcheck
+		}
+	}
+
+	if cfg.Check {
+		if len(changed) > 0 {
+			return fmt.Errorf("generated files are out of date with %s:\n  %s\n\n"+
+				"Regenerate without --check", sourceName, strings.Join(changed, "\n  "))
+		}
+		fmt.Fprintf(cfg.Stdout, "\nGenerated files are up to date with %s\n", sourceName) //nolint:errcheck
+		return nil
+	}
+
+	if cfg.DryRun {
+		fmt.Fprintf(cfg.Stdout, "\nDry run: no files written, WASM not built\n") //nolint:errcheck
+		return nil
 	}
 
 	// Stop here if --no-build
 	if cfg.NoBuild {
-		return nil
+		return runPostHook(cfg.PostHook, cfg.OutputDir, cfg.Stdout, cfg.Stderr)
 	}
 
 	// Copy wasm_exec.js
-	fmt.Fprintf(cfg.Stdout, "\nCopying wasm_exec.js...\n") //nolint:errcheck
-	if err := copyWasmExec(cfg.Compiler, cfg.OutputDir); err != nil {
-		return err
+	if !cfg.NoWasmExec {
+		fmt.Fprintf(cfg.Stdout, "\nCopying wasm_exec.js...\n") //nolint:errcheck
+		wasmExecPath, err := copyWasmExec(cfg.Compiler, cfg.WasmExec, cfg.OutputDir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cfg.Stdout, "Copied %s\n", wasmExecPath) //nolint:errcheck
+		if cfg.Summary != nil {
+			cfg.Summary.Files = append(cfg.Summary.Files, wasmExecPath)
+		}
 	}
 
 	// Compile WASM
 	fmt.Fprintf(cfg.Stdout, "\nCompiling WASM with %s...\n", cfg.Compiler) //nolint:errcheck
-	if err := compileWasm(sourceDir, wasmFile, cfg.Compiler, cfg.Optimize); err != nil {
+	if err := compileWasm(buildDir, wasmFile, cfg.Compiler, cfg.Optimize, cfg.TinyGoFlags, cfg.Stdout); err != nil {
 		return fmt.Errorf("compiling WASM: %w", err)
 	}
+	if cfg.Summary != nil {
+		cfg.Summary.Files = append(cfg.Summary.Files, wasmFile)
+	}
+	if err := runPostHook(cfg.PostHook, cfg.OutputDir, cfg.Stdout, cfg.Stderr); err != nil {
+		return err
+	}
 
 	fmt.Fprintf(cfg.Stdout, "\nBuild complete!\n") //nolint:errcheck
 	fmt.Fprintf(cfg.Stdout, "  %s\n", tsOutput)    //nolint:errcheck
 	if cfg.Mode == "worker" {
-		fmt.Fprintf(cfg.Stdout, "  %s\n", filepath.Join(cfg.OutputDir, "worker.js")) //nolint:errcheck
+		fmt.Fprintf(cfg.Stdout, "  %s\n", workerFile) //nolint:errcheck
 	}
 	fmt.Fprintf(cfg.Stdout, "  %s\n", filepath.Join(cfg.OutputDir, "wasm_exec.js")) //nolint:errcheck
 	fmt.Fprintf(cfg.Stdout, "  %s\n", wasmFile)                                     //nolint:errcheck
@@ -230,75 +1102,324 @@ func execute(cfg Config) error {
 	return nil
 }
 
-func generateSyncOutput(parsed *parser.ParsedFile, output, className string) error {
+// usageURL is the init argument shown in the usage hint for file. Deno and
+// Node.js read relative paths from the working directory (and Deno requires
+// absolute worker URLs), so their hints resolve it against the module.
+func usageURL(file string, opts generator.Options) string {
+	if opts.Node && opts.CommonJS {
+		return "require('node:path').join(__dirname, '" + file + "')"
+	}
+	if opts.Deno || opts.Node {
+		return "new URL('./" + file + "', import.meta.url)"
+	}
+	return "'./" + file + "'"
+}
+
+func generateSyncOutput(w fileWriter, parsed *parser.ParsedFile, output, className string, opts generator.Options) error {
 	// Generate TypeScript class-based client
-	content := generator.Generate(parsed, filepath.Base(output), className)
+	content := generator.Generate(parsed, filepath.Base(output), className, opts)
 
 	// Write output
-	if err := os.WriteFile(output, []byte(content), 0644); err != nil { //nolint:gosec // generated source files should be readable
+	if err := w.WriteFile(output, w.script(content)); err != nil {
 		return fmt.Errorf("writing output: %w", err)
 	}
+	if w.dryRun {
+		return nil
+	}
+	stdout := w.stdout
 
-	// Derive import path (strip .ts extension)
-	importPath := "./" + strings.TrimSuffix(filepath.Base(output), ".ts")
+	importPath := generator.ModulePath(filepath.Base(output), opts)
 
-	fmt.Printf("\nGenerated %s with %d function(s) (sync mode)\n", output, len(parsed.Functions))
-	fmt.Println("\nUsage:")
-	fmt.Printf("  import { %s } from '%s';\n", className, importPath)
-	fmt.Printf("  const wasm = await %s.init('./<name>.wasm');\n", className)
+	fmt.Fprintf(stdout, "\nGenerated %s with %d function(s) (sync mode)\n", output, len(parsed.Functions)) //nolint:errcheck
+	fmt.Fprintln(stdout, "\nUsage:")                                                                       //nolint:errcheck
+	fmt.Fprintf(stdout, "  import { %s } from '%s';\n", className, importPath)                             //nolint:errcheck
+	fmt.Fprintf(stdout, "  const wasm = await %s.init(%s);\n", className, usageURL("<name>.wasm", opts))   //nolint:errcheck
 	if len(parsed.Functions) > 0 {
-		exampleFunc := generator.LowerFirst(parsed.Functions[0].Name)
-		fmt.Printf("  const result = wasm.%s(...);\n", exampleFunc)
+		exampleFunc := parsed.Functions[0].BindingName()
+		fmt.Fprintf(stdout, "  const result = wasm.%s(...);\n", exampleFunc) //nolint:errcheck
 	}
 	return nil
 }
 
-func generateWorkerOutput(parsed *parser.ParsedFile, output, wasmPath, className string) error {
-	outputDir := filepath.Dir(output)
+// generateDeclarationsOutput writes the ambient declarations for --dts-only.
+func generateDeclarationsOutput(w fileWriter, parsed *parser.ParsedFile, output string, opts generator.Options) error {
+	content := generator.GenerateDeclarations(parsed, filepath.Base(output), opts)
+	if err := w.WriteFile(output, []byte(content)); err != nil {
+		return fmt.Errorf("writing declarations: %w", err)
+	}
+	if w.dryRun {
+		return nil
+	}
+	stdout := w.stdout
+
+	fmt.Fprintf(stdout, "\nGenerated %s with %d function(s) (declarations only)\n", output, len(parsed.Functions)) //nolint:errcheck
+	if len(parsed.Functions) > 0 {
+		fmt.Fprintln(stdout, "\nUsage (after loading the WASM module with wasm_exec.js):")    //nolint:errcheck
+		fmt.Fprintf(stdout, "  const result = %s(...);\n", parsed.Functions[0].BindingName()) //nolint:errcheck
+	}
+	return nil
+}
 
+func generateWorkerOutput(w fileWriter, parsed *parser.ParsedFile, output, workerPath, wasmPath, className string, opts generator.Options) error {
 	// Generate worker.js
-	workerPath := filepath.Join(outputDir, "worker.js")
-	if err := os.WriteFile(workerPath, []byte(generator.GenerateWorker(wasmPath)), 0644); err != nil { //nolint:gosec // generated source files should be readable
+	if err := w.WriteFile(workerPath, w.script(generator.GenerateWorker(wasmPath, opts))); err != nil {
 		return fmt.Errorf("writing worker: %w", err)
 	}
 
 	// Generate client.ts
-	clientContent := generator.GenerateClient(parsed, filepath.Base(output), className)
-	if err := os.WriteFile(output, []byte(clientContent), 0644); err != nil { //nolint:gosec // generated source files should be readable
+	clientContent := generator.GenerateClient(parsed, filepath.Base(output), className, opts)
+	if err := w.WriteFile(output, w.script(clientContent)); err != nil {
 		return fmt.Errorf("writing client: %w", err)
 	}
 
-	// Derive import path (strip .ts extension)
-	importPath := "./" + strings.TrimSuffix(filepath.Base(output), ".ts")
+	importPath := generator.ModulePath(filepath.Base(output), opts)
 
-	fmt.Printf("\nGenerated %s (Web Worker entry point)\n", workerPath)
-	fmt.Printf("Generated %s with %d function(s) (worker mode)\n", output, len(parsed.Functions))
-	fmt.Println("\nUsage:")
-	fmt.Printf("  import { %s } from '%s';\n", className, importPath)
-	fmt.Printf("  const wasm = await %s.init('./worker.js');\n", className)
+	// Generate the init module split out of the client class
+	var initPath string
+	if opts.SplitClient {
+		initPath = strings.TrimSuffix(output, ".ts") + "-init.ts"
+		initContent := generator.GenerateClientInit(parsed, filepath.Base(initPath), className, importPath, opts)
+		if err := w.WriteFile(initPath, w.script(initContent)); err != nil {
+			return fmt.Errorf("writing client init: %w", err)
+		}
+	}
+	if w.dryRun {
+		return nil
+	}
+	stdout := w.stdout
+
+	fmt.Fprintf(stdout, "\nGenerated %s (Web Worker entry point)\n", workerPath)                           //nolint:errcheck
+	fmt.Fprintf(stdout, "Generated %s with %d function(s) (worker mode)\n", output, len(parsed.Functions)) //nolint:errcheck
+	if initPath != "" {
+		fmt.Fprintf(stdout, "Generated %s (worker startup)\n", initPath) //nolint:errcheck
+	}
+	fmt.Fprintln(stdout, "\nUsage:") //nolint:errcheck
+	if initPath != "" {
+		fmt.Fprintf(stdout, "  const { init } = await import('%s');\n", generator.ModulePath(filepath.Base(initPath), opts)) //nolint:errcheck
+		fmt.Fprintf(stdout, "  const wasm = await init(%s);\n", usageURL(filepath.Base(workerPath), opts))                   //nolint:errcheck
+	} else {
+		fmt.Fprintf(stdout, "  import { %s } from '%s';\n", className, importPath)                                       //nolint:errcheck
+		fmt.Fprintf(stdout, "  const wasm = await %s.init(%s);\n", className, usageURL(filepath.Base(workerPath), opts)) //nolint:errcheck
+	}
 	if len(parsed.Functions) > 0 {
-		exampleFunc := generator.LowerFirst(parsed.Functions[0].Name)
-		fmt.Printf("  const result = await wasm.%s(...);\n", exampleFunc)
+		exampleFunc := parsed.Functions[0].BindingName()
+		fmt.Fprintf(stdout, "  const result = await wasm.%s(...);\n", exampleFunc) //nolint:errcheck
 	}
-	fmt.Printf("  wasm.terminate();\n")
+	fmt.Fprintf(stdout, "  wasm.terminate();\n") //nolint:errcheck
 	return nil
 }
 
-// copyWasmExec copies the wasm_exec.js runtime from the compiler installation
-func copyWasmExec(compiler, destDir string) error {
-	srcPath, err := getWasmExecPath(compiler)
+// fileWriter writes generated files. With dryRun set it writes nothing and
+// instead reports the path and size of each file to stdout, or with changed
+// also set, compares each file with the one on disk (see --check).
+type fileWriter struct {
+	dryRun  bool
+	minify  bool // Minify the client and worker scripts passed through script
+	stdout  io.Writer
+	files   *[]string // Paths written (or that would be), when non-nil
+	changed *[]string // Paths whose file on disk is missing or differs, when non-nil
+}
+
+// script returns the content of a generated client or worker script,
+// minified if requested.
+func (w fileWriter) script(content string) []byte {
+	if w.minify {
+		content = generator.Minify(content)
+	}
+	return []byte(content)
+}
+
+// WriteFile writes data to path, readable by everyone like other sources.
+func (w fileWriter) WriteFile(path string, data []byte) error {
+	if w.files != nil {
+		*w.files = append(*w.files, path)
+	}
+	if w.changed != nil {
+		existing, err := os.ReadFile(path) //nolint:gosec // path derived from CLI arguments
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err != nil || !bytes.Equal(existing, data) {
+			*w.changed = append(*w.changed, path)
+		}
+		return nil
+	}
+	if w.dryRun {
+		fmt.Fprintf(w.stdout, "Would write %s (%d bytes)\n", path, len(data)) //nolint:errcheck
+		return nil
+	}
+	return os.WriteFile(path, data, 0644) //nolint:gosec // generated source files should be readable
+}
+
+// generateEntrypointOutput writes main.go to dir, importing the library
+// package in libDir. A main.go that gowasm-bindgen did not generate is left
+// alone.
+func generateEntrypointOutput(w fileWriter, libDir, dir string) (string, error) {
+	importPath, err := packageImportPath(libDir)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("--entrypoint: %w", err)
+	}
+
+	path := filepath.Join(dir, "main.go")
+	existing, err := os.ReadFile(path) //nolint:gosec // path is derived from the --entrypoint flag
+	if err == nil && !bytes.Contains(existing, []byte(generator.EntrypointHeader)) {
+		return "", fmt.Errorf("--entrypoint: %s exists and was not generated by gowasm-bindgen", path)
+	}
+	if !w.dryRun {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return "", fmt.Errorf("creating entrypoint directory: %w", err)
+		}
+	}
+	if err := w.WriteFile(path, []byte(generator.GenerateEntrypoint(importPath))); err != nil {
+		return "", fmt.Errorf("writing entrypoint: %w", err)
+	}
+	return path, nil
+}
+
+// goModModule matches the module directive of a go.mod file.
+var goModModule = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?`)
+
+// packageImportPath returns the import path of the package in dir, from the
+// module path in the nearest go.mod above it.
+func packageImportPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", dir, err)
+	}
+	for modDir := dir; ; {
+		data, err := os.ReadFile(filepath.Join(modDir, "go.mod")) //nolint:gosec // a parent of the source directory
+		if err == nil {
+			match := goModModule.FindSubmatch(data)
+			if match == nil {
+				return "", fmt.Errorf("no module directive in %s", filepath.Join(modDir, "go.mod"))
+			}
+			rel, err := filepath.Rel(modDir, dir)
+			if err != nil {
+				return "", fmt.Errorf("resolving %s: %w", dir, err)
+			}
+			if rel == "." {
+				return string(match[1]), nil
+			}
+			return string(match[1]) + "/" + filepath.ToSlash(rel), nil
+		}
+		parent := filepath.Dir(modDir)
+		if parent == modDir {
+			return "", fmt.Errorf("no go.mod found for %s", dir)
+		}
+		modDir = parent
+	}
+}
+
+// readSources concatenates the given source files in order so a package
+// directory hashes to a single checksum.
+func readSources(paths []string) ([]byte, error) {
+	var src []byte
+	for _, path := range paths {
+		content, err := os.ReadFile(path) //nolint:gosec // path derived from CLI arguments
+		if err != nil {
+			return nil, fmt.Errorf("reading source file: %w", err)
+		}
+		src = append(src, content...)
+	}
+	return src, nil
+}
+
+// checkStale compares the source checksum recorded in each generated file
+// against src, returning an error if any are out of date. The name describes
+// the source in messages.
+func checkStale(src []byte, name string, generated []string, stdout io.Writer) error {
+	want := generator.ChecksumSource(src)
+
+	var stale []string
+	for _, path := range generated {
+		content, err := os.ReadFile(path) //nolint:gosec // path derived from CLI arguments
+		if err != nil {
+			return fmt.Errorf("reading generated file: %w", err)
+		}
+		got, ok := generator.ReadChecksum(content)
+		if !ok {
+			return fmt.Errorf("%s has no source checksum (regenerate with --emit-checksum)", path)
+		}
+		if got != want {
+			stale = append(stale, path)
+		}
+	}
+
+	if len(stale) > 0 {
+		return fmt.Errorf("generated files are stale (%s changed since generation):\n  %s\n\n"+
+			"Regenerate with --emit-checksum", name, strings.Join(stale, "\n  "))
+	}
+
+	fmt.Fprintf(stdout, "Generated files are up to date with %s\n", name) //nolint:errcheck
+	return nil
+}
+
+// generateMockOutput writes the mock client next to the TypeScript client
+// and returns its path.
+func generateMockOutput(w fileWriter, parsed *parser.ParsedFile, output, className string, opts generator.Options) (string, error) {
+	mockPath := strings.TrimSuffix(output, ".ts") + "-mock.ts"
+	importPath := generator.ModulePath(filepath.Base(output), opts)
+	content := generator.GenerateMock(parsed, filepath.Base(mockPath), className, importPath, opts)
+	if err := w.WriteFile(mockPath, []byte(content)); err != nil {
+		return "", fmt.Errorf("writing mock client: %w", err)
+	}
+	return mockPath, nil
+}
+
+// generateDemoOutput writes the --emit-demo page into the output directory
+// and returns its path. initURL is what the page initializes the client with.
+func generateDemoOutput(w fileWriter, parsed *parser.ParsedFile, output, initURL, className string, opts generator.Options) (string, error) {
+	demoPath := filepath.Join(filepath.Dir(output), generator.DemoFileName)
+	entry := strings.TrimSuffix(filepath.Base(output), ".ts")
+	if opts.SplitClient {
+		entry += "-init"
+	}
+	content := generator.GenerateDemo(parsed, className, entry+".js", initURL, opts)
+	if err := w.WriteFile(demoPath, []byte(content)); err != nil {
+		return "", fmt.Errorf("writing demo page: %w", err)
+	}
+	return demoPath, nil
+}
+
+// generateIndexOutput merges the client's exports into the index.ts barrel
+// next to it and returns its path. Names already exported by another client
+// in the barrel are reported and left out.
+func generateIndexOutput(w fileWriter, parsed *parser.ParsedFile, output, className string, opts generator.Options) (string, error) {
+	indexPath := filepath.Join(filepath.Dir(output), generator.IndexFileName)
+	existing, err := os.ReadFile(indexPath) //nolint:gosec // path derived from CLI arguments
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading index: %w", err)
+	}
+	module := strings.TrimPrefix(generator.ModulePath(filepath.Base(output), opts), "./")
+	content, skipped := generator.GenerateIndex(string(existing), module, parsed, className, opts)
+	if len(skipped) > 0 {
+		fmt.Fprintf(w.stdout, "Warning: %s not re-exported from %s: already exported by another client\n", //nolint:errcheck
+			strings.Join(skipped, ", "), indexPath)
+	}
+	if err := w.WriteFile(indexPath, []byte(content)); err != nil {
+		return "", fmt.Errorf("writing index: %w", err)
+	}
+	return indexPath, nil
+}
+
+// copyWasmExec copies the wasm_exec.js runtime from srcPath, or from the
+// compiler installation if srcPath is empty, and returns the copy's path
+func copyWasmExec(compiler, srcPath, destDir string) (string, error) {
+	if srcPath == "" {
+		var err error
+		srcPath, err = getWasmExecPath(compiler)
+		if err != nil {
+			return "", err
+		}
 	}
 	if _, err := os.Stat(srcPath); err != nil {
-		return fmt.Errorf("wasm_exec.js not found at %s: %w", srcPath, err)
+		return "", fmt.Errorf("wasm_exec.js not found at %s: %w", srcPath, err)
 	}
 	destPath := filepath.Join(destDir, "wasm_exec.js")
 	if err := copyFile(srcPath, destPath); err != nil {
-		return fmt.Errorf("copying wasm_exec.js: %w", err)
+		return "", fmt.Errorf("copying wasm_exec.js: %w", err)
 	}
-	fmt.Printf("Copied %s\n", destPath)
-	return nil
+	return destPath, nil
 }
 
 // getWasmExecPath returns the path to wasm_exec.js for the given compiler
@@ -318,7 +1439,7 @@ func getWasmExecPath(compiler string) (string, error) {
 }
 
 // compileWasm compiles the Go source to WASM
-func compileWasm(sourceDir, outputFile, compiler string, optimize bool) error {
+func compileWasm(sourceDir, outputFile, compiler string, optimize bool, tinygoFlags []string, stdout io.Writer) error {
 	// Make output path absolute since we'll change to sourceDir
 	if !filepath.IsAbs(outputFile) {
 		cwd, err := os.Getwd()
@@ -334,6 +1455,7 @@ func compileWasm(sourceDir, outputFile, compiler string, optimize bool) error {
 		if optimize {
 			args = append(args, "-opt=z", "-no-debug", "-panic=trap")
 		}
+		args = append(args, tinygoFlags...)
 		args = append(args, ".")
 		cmd = exec.Command("tinygo", args...) //nolint:gosec // args are validated
 	} else {
@@ -341,7 +1463,7 @@ func compileWasm(sourceDir, outputFile, compiler string, optimize bool) error {
 		cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
 	}
 	cmd.Dir = sourceDir
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("running %s: %w", compiler, err)
@@ -349,6 +1471,37 @@ func compileWasm(sourceDir, outputFile, compiler string, optimize bool) error {
 	return nil
 }
 
+// runPostHook runs the --post-hook command, if any, in outputDir. The
+// command is split on whitespace and run without a shell, like
+// --tinygo-flags, so pipes and && need a script.
+func runPostHook(hook []string, outputDir string, stdout, stderr io.Writer) error {
+	if len(hook) == 0 {
+		return nil
+	}
+	fmt.Fprintf(stdout, "\nRunning post hook %s...\n", strings.Join(hook, " ")) //nolint:errcheck
+
+	cmd := exec.Command(hook[0], hook[1:]...) //nolint:gosec // the command is the user's own
+	cmd.Dir = outputDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running post hook %s: %w", hook[0], err)
+	}
+	return nil
+}
+
+// validateTinyGoFlags rejects --tinygo-flags that would change where or for
+// which target compileWasm builds, since the generated files depend on both.
+func validateTinyGoFlags(flags []string) error {
+	for _, f := range flags {
+		name, _, _ := strings.Cut(strings.TrimLeft(f, "-"), "=")
+		if name == "o" || name == "target" {
+			return fmt.Errorf("--tinygo-flags cannot set %s (gowasm-bindgen sets -o and -target)", f)
+		}
+	}
+	return nil
+}
+
 // copyFile copies a file from src to dst
 func copyFile(src, dst string) error {
 	data, err := os.ReadFile(src) //nolint:gosec // src is from trusted source (compiler path)
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go/format"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	flag "github.com/spf13/pflag"
+
+	"github.com/13rac1/gowasm-bindgen/internal/generator"
 	"github.com/13rac1/gowasm-bindgen/internal/parser"
 )
 
@@ -72,7 +82,7 @@ func TestGenerateSyncOutput(t *testing.T) {
 	}
 
 	output := filepath.Join(tmpDir, "test-client.ts")
-	if err := generateSyncOutput(parsed, output, "TestClass"); err != nil {
+	if err := generateSyncOutput(fileWriter{stdout: io.Discard}, parsed, output, "TestClass", generator.Options{}); err != nil {
 		t.Fatalf("generateSyncOutput failed: %v", err)
 	}
 
@@ -103,7 +113,7 @@ func TestGenerateWorkerOutput(t *testing.T) {
 	}
 
 	output := filepath.Join(tmpDir, "test-client.ts")
-	if err := generateWorkerOutput(parsed, output, "test.wasm", "TestClass"); err != nil {
+	if err := generateWorkerOutput(fileWriter{stdout: io.Discard}, parsed, output, filepath.Join(tmpDir, "worker.js"), "test.wasm", "TestClass", generator.Options{}); err != nil {
 		t.Fatalf("generateWorkerOutput failed: %v", err)
 	}
 
@@ -131,6 +141,99 @@ func TestGenerateWorkerOutput(t *testing.T) {
 	}
 }
 
+func TestGenerateWorkerOutput_SplitClient(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "worker-test-split-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Greet", Params: []parser.GoParameter{{Name: "name", Type: parser.GoType{Kind: parser.KindPrimitive, Name: "string"}}}, Returns: []parser.GoType{{Kind: parser.KindPrimitive, Name: "string"}}},
+		},
+	}
+
+	output := filepath.Join(tmpDir, "test-client.ts")
+	if err := generateWorkerOutput(fileWriter{stdout: io.Discard}, parsed, output, filepath.Join(tmpDir, "worker.js"), "test.wasm", "TestClass", generator.Options{SplitClient: true}); err != nil {
+		t.Fatalf("generateWorkerOutput failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "test-client-init.ts")) //nolint:gosec // test file path
+	if err != nil {
+		t.Fatalf("init module was not created: %v", err)
+	}
+	if !strings.Contains(string(content), "import { TestClass } from './test-client';") {
+		t.Errorf("init module should import the client class, got:\n%s", content)
+	}
+}
+
+func TestGenerateMockOutput(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "mock-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	parsed := &parser.ParsedFile{
+		Package: "main",
+		Functions: []parser.GoFunction{
+			{Name: "Greet", Params: []parser.GoParameter{{Name: "name", Type: parser.GoType{Kind: parser.KindPrimitive, Name: "string"}}}, Returns: []parser.GoType{{Kind: parser.KindPrimitive, Name: "string"}}},
+		},
+	}
+
+	mockPath, err := generateMockOutput(fileWriter{stdout: io.Discard}, parsed, filepath.Join(tmpDir, "test-client.ts"), "TestClass", generator.Options{WorkerMode: true})
+	if err != nil {
+		t.Fatalf("generateMockOutput failed: %v", err)
+	}
+	if want := filepath.Join(tmpDir, "test-client-mock.ts"); mockPath != want {
+		t.Errorf("mock path = %q, want %q", mockPath, want)
+	}
+
+	content, err := os.ReadFile(mockPath) //nolint:gosec // test file path
+	if err != nil {
+		t.Fatalf("mock client was not created: %v", err)
+	}
+	if !strings.Contains(string(content), "import type { TestClass } from './test-client';") {
+		t.Errorf("mock should import the client type, got:\n%s", content)
+	}
+}
+
+func TestCheckStale(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "stale-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	generated := filepath.Join(tmpDir, "client.ts")
+	src := []byte("package main\n")
+	header := "// client.ts - Generated by gowasm-bindgen\n// Source checksum: " + generator.ChecksumSource(src) + "\n"
+	if err := os.WriteFile(generated, []byte(header), 0600); err != nil {
+		t.Fatalf("failed to write generated file: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := checkStale(src, "main.go", []string{generated}, &stdout); err != nil {
+		t.Errorf("checkStale() on fresh output: %v", err)
+	}
+
+	changed := []byte("package main\n\nfunc Changed() {}\n")
+	err = checkStale(changed, "main.go", []string{generated}, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("checkStale() after source change = %v, want stale error", err)
+	}
+
+	if err := os.WriteFile(generated, []byte("// no checksum\n"), 0600); err != nil {
+		t.Fatalf("failed to write generated file: %v", err)
+	}
+	err = checkStale(src, "main.go", []string{generated}, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "no source checksum") {
+		t.Errorf("checkStale() without checksum = %v, want missing checksum error", err)
+	}
+}
+
 func TestGetWasmExecPath_Go(t *testing.T) {
 	path, err := getWasmExecPath("go")
 	if err != nil {
@@ -189,6 +292,401 @@ func TestCLI_InvalidCompiler(t *testing.T) {
 	}
 }
 
+func TestCLI_SplitClientRequiresWorker(t *testing.T) {
+	cmd := exec.Command("go", "run", ".", "--split-client", "--mode", "sync", "test/e2e/wasm/main.go") //nolint:gosec // test command
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("expected error for --split-client in sync mode")
+	}
+	if !strings.Contains(string(output), "--split-client requires --mode worker") {
+		t.Errorf("expected split-client error, got: %s", output)
+	}
+}
+
+func TestCLI_TinyGoFlagsValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"go compiler", []string{"--compiler", "go", "--tinygo-flags", "-gc=leaking"}, "--tinygo-flags requires --compiler tinygo"},
+		{"output", []string{"--tinygo-flags", "-gc=leaking -o out.wasm"}, "--tinygo-flags cannot set -o"},
+		{"target", []string{"--tinygo-flags", "--target=wasi"}, "--tinygo-flags cannot set --target=wasi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"run", "."}, tt.args...)
+			args = append(args, "test/e2e/wasm/main.go")
+			cmd := exec.Command("go", args...) //nolint:gosec // test command
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected error for %v", tt.args)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected %q, got: %s", tt.want, output)
+			}
+		})
+	}
+
+	if err := validateTinyGoFlags([]string{"-scheduler=asyncify", "-gc=leaking", "-opt=2"}); err != nil {
+		t.Errorf("expected flags to be accepted, got: %v", err)
+	}
+}
+
+func TestCLI_WorkerPoolValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"sync mode", []string{"--emit-worker-pool", "4", "--mode", "sync"}, "--emit-worker-pool requires --mode worker"},
+		{"split client", []string{"--emit-worker-pool", "4", "--split-client"}, "--emit-worker-pool cannot be combined with --split-client"},
+		{"negative", []string{"--emit-worker-pool", "-1"}, "--emit-worker-pool must be positive"},
+		{"workers alias", []string{"--workers", "4", "--mode", "sync"}, "--workers requires --mode worker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"run", "."}, tt.args...)
+			args = append(args, "test/e2e/wasm/main.go")
+			cmd := exec.Command("go", args...) //nolint:gosec // test command
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected error for %v", tt.args)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	newFlags := func() *flag.FlagSet {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.StringP("mode", "m", "worker", "")
+		flags.Bool("emit-mock", false, "")
+		var pool int
+		flags.IntVar(&pool, "emit-worker-pool", 0, "")
+		flags.IntVar(&pool, "workers", 0, "")
+		flags.String("config", "", "")
+		return flags
+	}
+	writeConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), configFileName)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("fills unset flags", func(t *testing.T) {
+		flags := newFlags()
+		if err := flags.Parse([]string{"-m", "sync", "--workers", "2"}); err != nil {
+			t.Fatal(err)
+		}
+		path := writeConfig(t, `{"mode": "worker", "emit-mock": true, "emit-worker-pool": 4}`)
+		if err := applyConfigFile(flags, path); err != nil {
+			t.Fatalf("applyConfigFile() error: %v", err)
+		}
+
+		// Command line wins, including over the config's spelling of an alias
+		for name, want := range map[string]string{"mode": "sync", "emit-mock": "true", "workers": "2"} {
+			if got := flags.Lookup(name).Value.String(); got != want {
+				t.Errorf("%s = %q, want %q", name, got, want)
+			}
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown option", `{"mdoe": "sync"}`, `unknown option "mdoe"`},
+		{"nested config", `{"config": "other.json"}`, `unknown option "config"`},
+		{"invalid value", `{"workers": "many"}`, `option "workers"`},
+		{"unsupported type", `{"mode": ["sync"]}`, "must be a string, number, or boolean"},
+		{"invalid JSON", `mode: sync`, "parsing config"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyConfigFile(newFlags(), writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("applyConfigFile() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(source, []byte("package main\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findConfigFile(source); got != "" {
+		t.Errorf("findConfigFile() = %q without a config file, want empty", got)
+	}
+
+	config := filepath.Join(dir, configFileName)
+	if err := os.WriteFile(config, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range []string{source, dir} {
+		if got := findConfigFile(src); got != config {
+			t.Errorf("findConfigFile(%q) = %q, want %q", src, got, config)
+		}
+	}
+}
+
+func TestCLI_ConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("test/e2e/wasm/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, configFileName), []byte(`{"mode": "bogus"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The auto-loaded config's mode is validated like the flag
+	cmd := exec.Command("go", "run", ".", filepath.Join(dir, "main.go")) //nolint:gosec // test command
+	output, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), `--mode must be 'sync' or 'worker', got "bogus"`) {
+		t.Errorf("expected config mode to be validated, got err=%v: %s", err, output)
+	}
+
+	// --config names a file explicitly, and it must exist
+	cmd = exec.Command("go", "run", ".", "--config", filepath.Join(dir, "missing.json"), "test/e2e/wasm/main.go") //nolint:gosec // test command
+	output, err = cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "reading config") {
+		t.Errorf("expected missing --config error, got err=%v: %s", err, output)
+	}
+}
+
+func TestCLI_BytesValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown mode", []string{"--bytes", "hex"}, "--bytes must be 'uint8array' or 'base64'"},
+		{"shared memory", []string{"--bytes", "base64", "--shared-memory"}, "--shared-memory cannot be combined with --bytes base64"},
+		{"stream sync mode", []string{"--stream-bytes", "--mode", "sync"}, "--stream-bytes requires --mode worker"},
+		{"stream shared memory", []string{"--stream-bytes", "--shared-memory"}, "--stream-bytes cannot be combined with --shared-memory"},
+		{"shared buffer alias", []string{"--bytes", "base64", "--shared-buffer"}, "--shared-buffer cannot be combined with --bytes base64"},
+		{"stream base64", []string{"--stream-bytes", "--bytes", "base64"}, "--stream-bytes cannot be combined with --bytes base64"},
+		{"stream result union", []string{"--stream-bytes", "--result-union"}, "--stream-bytes cannot be combined with --result-union"},
+		{"stream nil as null", []string{"--stream-bytes", "--nil-as-null"}, "--stream-bytes cannot be combined with --nil-as-null"},
+		{"typed array byte order", []string{"--typed-array-bytes", "native"}, "--typed-array-bytes must be 'little' or 'big'"},
+		{"typed array bytes plain arrays", []string{"--typed-array-bytes", "big", "--no-typed-arrays"}, "--typed-array-bytes cannot be combined with --no-typed-arrays"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"run", "."}, tt.args...)
+			args = append(args, "test/e2e/wasm/main.go")
+			cmd := exec.Command("go", args...) //nolint:gosec // test command
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected error for %v", tt.args)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestCLI_DtsOnlyValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"worker mode", []string{"--dts-only"}, "--dts-only requires --mode sync"},
+		{"mock", []string{"--dts-only", "--mode", "sync", "--emit-mock"}, "--dts-only cannot be combined with --emit-mock"},
+		{"index", []string{"--dts-only", "--mode", "sync", "--emit-index"}, "--dts-only cannot be combined with --emit-index"},
+		{"demo", []string{"--dts-only", "--mode", "sync", "--emit-demo"}, "--dts-only cannot be combined with --emit-demo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"run", "."}, tt.args...)
+			args = append(args, "test/e2e/wasm/main.go")
+			cmd := exec.Command("go", args...) //nolint:gosec // test command
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected error for %v", tt.args)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestCLI_CheckValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"watch", []string{"--check", "--watch"}, "--check cannot be combined with --watch"},
+		{"list", []string{"--check", "--list"}, "--check cannot be combined with --list"},
+		{"check stale", []string{"--check", "--check-stale"}, "--check cannot be combined with --check-stale"},
+		{"dry run", []string{"--check", "--dry-run"}, "--check cannot be combined with --dry-run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"run", "."}, tt.args...)
+			args = append(args, "test/e2e/wasm/main.go")
+			cmd := exec.Command("go", args...) //nolint:gosec // test command
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected error for %v", tt.args)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestCLI_TargetValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown", []string{"--target", "bun"}, "--target must be 'web', 'deno', or 'node'"},
+		{"commonjs", []string{"--target", "deno", "--module", "commonjs"}, "--target deno cannot be combined with --module commonjs"},
+		{"demo", []string{"--target", "deno", "--emit-demo"}, "--target deno cannot be combined with --emit-demo"},
+		{"node worker", []string{"--target", "node"}, "--target node requires --mode sync"},
+		{"node demo", []string{"--target", "node", "--mode", "sync", "--emit-demo"}, "--target node cannot be combined with --emit-demo"},
+		{"node dts", []string{"--target", "node", "--mode", "sync", "--dts-only"}, "--target node cannot be combined with --dts-only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"run", "."}, tt.args...)
+			args = append(args, "test/e2e/wasm/main.go")
+			cmd := exec.Command("go", args...) //nolint:gosec // test command
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected error for %v", tt.args)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestCLI_SeveralSourcesValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"class name", []string{"--class-name", "Shared"}, "--class-name cannot be used with several sources"},
+		{"go output", []string{"--go-output", "out.go"}, "--go-output cannot be used with several sources"},
+		{"watch", []string{"--watch"}, "--watch cannot be used with several sources"},
+		{"demo", []string{"--emit-demo"}, "--emit-demo cannot be used with several sources"},
+		{"stdin", []string{"-"}, "source from stdin cannot be combined with other sources"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"run", "."}, tt.args...)
+			args = append(args, "test/e2e/wasm/main.go", "examples/simple/wasm/main.go")
+			cmd := exec.Command("go", args...) //nolint:gosec // test command
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected error for %v", tt.args)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestCLI_InvalidNamespace(t *testing.T) {
+	cmd := exec.Command("go", "run", ".", "--namespace", "my-lib", "test/e2e/wasm/main.go") //nolint:gosec // test command
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("expected error for invalid --namespace")
+	}
+	if !strings.Contains(string(output), "--namespace must be a JavaScript identifier") {
+		t.Errorf("expected namespace error, got: %s", output)
+	}
+}
+
+func TestCLI_InvalidPackage(t *testing.T) {
+	cmd := exec.Command("go", "run", ".", "--package", "my-pkg", "test/e2e/wasm/main.go") //nolint:gosec // test command
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("expected error for invalid --package")
+	}
+	if !strings.Contains(string(output), "--package must be a Go identifier") {
+		t.Errorf("expected package error, got: %s", output)
+	}
+}
+
+func TestCLI_ModuleValidation(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--module", "amd"}, "--module must be 'esm' or 'commonjs'"},
+		{[]string{"--module", "commonjs", "--emit-mock"}, "--module commonjs cannot be combined with --emit-mock"},
+		{[]string{"--module", "commonjs", "--split-client"}, "--module commonjs cannot be combined with --split-client"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"run", "."}, tt.args...)
+		args = append(args, "test/e2e/wasm/main.go")
+		output, err := exec.Command("go", args...).CombinedOutput() //nolint:gosec // test command
+		if err == nil {
+			t.Errorf("%v: expected error", tt.args)
+			continue
+		}
+		if !strings.Contains(string(output), tt.want) {
+			t.Errorf("%v: expected %q, got: %s", tt.args, tt.want, output)
+		}
+	}
+}
+
+func TestCLI_InvalidErrorKey(t *testing.T) {
+	cmd := exec.Command("go", "run", ".", "--error-key", "error-message", "test/e2e/wasm/main.go") //nolint:gosec // test command
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("expected error for invalid --error-key")
+	}
+	if !strings.Contains(string(output), "--error-key must be a JavaScript identifier") {
+		t.Errorf("expected error key error, got: %s", output)
+	}
+}
+
+func TestCLI_EntrypointWithPackage(t *testing.T) {
+	cmd := exec.Command("go", "run", ".", "--entrypoint", "cmd/wasm", "--package", "lib", "test/e2e/wasm/main.go") //nolint:gosec // test command
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("expected error for --entrypoint with --package")
+	}
+	if !strings.Contains(string(output), "--entrypoint cannot be combined with --package") {
+		t.Errorf("expected entrypoint error, got: %s", output)
+	}
+}
+
 func TestCLI_SourceFileNotFound(t *testing.T) {
 	cmd := exec.Command("go", "run", ".", "--no-build", "nonexistent/main.go") //nolint:gosec // test command
 	output, err := cmd.CombinedOutput()
@@ -229,8 +727,18 @@ func TestCLI_NoBuildGeneratesFiles(t *testing.T) {
 
 	// Verify bindings_gen.go was generated
 	bindingsFile := filepath.Join("test/e2e/wasm", "bindings_gen.go")
-	if _, err := os.Stat(bindingsFile); os.IsNotExist(err) {
-		t.Errorf("Go bindings not generated at %s", bindingsFile)
+	bindings, err := os.ReadFile(bindingsFile)
+	if err != nil {
+		t.Fatalf("Go bindings not generated at %s: %v", bindingsFile, err)
+	}
+
+	// Verify bindings_gen.go is gofmt-clean
+	formatted, err := format.Source(bindings)
+	if err != nil {
+		t.Fatalf("Go bindings do not parse: %v", err)
+	}
+	if !bytes.Equal(bindings, formatted) {
+		t.Error("Go bindings are not gofmt-formatted")
 	}
 }
 
@@ -370,7 +878,7 @@ func TestCopyWasmExec_Go(t *testing.T) {
 	}
 	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
 
-	if err := copyWasmExec("go", tmpDir); err != nil {
+	if _, err := copyWasmExec("go", "", tmpDir); err != nil {
 		t.Fatalf("copyWasmExec failed: %v", err)
 	}
 
@@ -402,7 +910,7 @@ func TestCopyWasmExec_TinyGo(t *testing.T) {
 	}
 	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
 
-	if err := copyWasmExec("tinygo", tmpDir); err != nil {
+	if _, err := copyWasmExec("tinygo", "", tmpDir); err != nil {
 		t.Fatalf("copyWasmExec failed: %v", err)
 	}
 
@@ -413,6 +921,31 @@ func TestCopyWasmExec_TinyGo(t *testing.T) {
 	}
 }
 
+func TestCopyWasmExec_ExplicitPath(t *testing.T) {
+	srcPath := filepath.Join(t.TempDir(), "custom_wasm_exec.js")
+	if err := os.WriteFile(srcPath, []byte("// custom runtime\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	destDir := t.TempDir()
+
+	// The compiler is never asked for its location, so an unknown one works
+	if _, err := copyWasmExec("missing-compiler", srcPath, destDir); err != nil {
+		t.Fatalf("copyWasmExec failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(destDir, "wasm_exec.js")) //nolint:gosec // test file path
+	if err != nil {
+		t.Fatalf("wasm_exec.js not copied: %v", err)
+	}
+	if string(content) != "// custom runtime\n" {
+		t.Errorf("copied content = %q", content)
+	}
+
+	_, err = copyWasmExec("go", filepath.Join(destDir, "missing.js"), destDir)
+	if err == nil || !strings.Contains(err.Error(), "wasm_exec.js not found at") {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
 func TestCopyFile_DestDirNotExist(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "copyfile-test-*")
 	if err != nil {
@@ -446,7 +979,7 @@ func TestGenerateSyncOutput_NoFunctions(t *testing.T) {
 	}
 
 	output := filepath.Join(tmpDir, "test-client.ts")
-	if err := generateSyncOutput(parsed, output, "TestClass"); err != nil {
+	if err := generateSyncOutput(fileWriter{stdout: io.Discard}, parsed, output, "TestClass", generator.Options{}); err != nil {
 		t.Fatalf("generateSyncOutput failed: %v", err)
 	}
 
@@ -469,7 +1002,7 @@ func TestGenerateWorkerOutput_NoFunctions(t *testing.T) {
 	}
 
 	output := filepath.Join(tmpDir, "test-client.ts")
-	if err := generateWorkerOutput(parsed, output, "test.wasm", "TestClass"); err != nil {
+	if err := generateWorkerOutput(fileWriter{stdout: io.Discard}, parsed, output, filepath.Join(tmpDir, "worker.js"), "test.wasm", "TestClass", generator.Options{}); err != nil {
 		t.Fatalf("generateWorkerOutput failed: %v", err)
 	}
 
@@ -500,9 +1033,13 @@ func TestGenerateSyncOutput_WritesUsageExample(t *testing.T) {
 	}
 
 	output := filepath.Join(tmpDir, "test-client.ts")
-	if err := generateSyncOutput(parsed, output, "TestClass"); err != nil {
+	var stdout bytes.Buffer
+	if err := generateSyncOutput(fileWriter{stdout: &stdout}, parsed, output, "TestClass", generator.Options{}); err != nil {
 		t.Fatalf("generateSyncOutput failed: %v", err)
 	}
+	if !strings.Contains(stdout.String(), "import { TestClass } from './test-client';") {
+		t.Errorf("usage example missing import:\n%s", stdout.String())
+	}
 
 	// Verify file content includes both functions
 	content, err := os.ReadFile(output) //nolint:gosec // test file path
@@ -530,9 +1067,13 @@ func TestGenerateWorkerOutput_WritesUsageExample(t *testing.T) {
 	}
 
 	output := filepath.Join(tmpDir, "test-client.ts")
-	if err := generateWorkerOutput(parsed, output, "custom.wasm", "CustomClass"); err != nil {
+	var stdout bytes.Buffer
+	if err := generateWorkerOutput(fileWriter{stdout: &stdout}, parsed, output, filepath.Join(tmpDir, "worker.js"), "custom.wasm", "CustomClass", generator.Options{}); err != nil {
 		t.Fatalf("generateWorkerOutput failed: %v", err)
 	}
+	if !strings.Contains(stdout.String(), "const result = await wasm.process(...);") {
+		t.Errorf("usage example missing call:\n%s", stdout.String())
+	}
 
 	// Verify worker references custom wasm
 	workerContent, err := os.ReadFile(filepath.Join(tmpDir, "worker.js")) //nolint:gosec // test file path
@@ -589,6 +1130,640 @@ func TestExecute_FullGeneration(t *testing.T) {
 	}
 }
 
+func TestExecute_DryRun(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	var stdout bytes.Buffer
+	cfg := Config{
+		SourceFile: filepath.Join(srcDir, "main.go"),
+		OutputDir:  outDir,
+		Compiler:   "go",
+		Mode:       "worker",
+		ClassName:  "Dry",
+		EmitMock:   true,
+		EmitDemo:   true,
+		DryRun:     true, // Implies no build
+		Stdout:     &stdout,
+		Stderr:     io.Discard,
+	}
+
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(srcDir, "bindings_gen.go"),
+		filepath.Join(outDir, "worker.js"),
+		filepath.Join(outDir, "dry.ts"),
+		filepath.Join(outDir, "dry-mock.ts"),
+		filepath.Join(outDir, "index.html"),
+	} {
+		if !strings.Contains(stdout.String(), "Would write "+path+" (") {
+			t.Errorf("output should report %s:\n%s", path, stdout.String())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(srcDir, "bindings_gen.go")); err == nil {
+		t.Error("dry run should not write bindings_gen.go")
+	}
+	if _, err := os.Stat(outDir); err == nil {
+		t.Error("dry run should not create the output directory")
+	}
+
+	// Validation errors are still reported
+	cfg.SourceFile = filepath.Join(srcDir, "bad.go")
+	bad := "package main\n\nfunc Run(c chan int) {}\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(cfg.SourceFile, []byte(bad), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := execute(cfg); err == nil || !strings.Contains(err.Error(), "validation failed") {
+		t.Errorf("execute() = %v, want validation error", err)
+	}
+}
+
+func TestExecute_Check(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outDir := t.TempDir()
+
+	cfg := Config{
+		SourceFile: filepath.Join(srcDir, "main.go"),
+		OutputDir:  outDir,
+		NoBuild:    true,
+		Mode:       "worker",
+		ClassName:  "Checked",
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	cfg.Check = true
+	cfg.NoBuild = false // Implied by --check
+	cfg.Stdout = &stdout
+	if err := execute(cfg); err != nil {
+		t.Fatalf("check on fresh output: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "up to date") {
+		t.Errorf("output should report the files are up to date:\n%s", stdout.String())
+	}
+
+	// Edited and deleted files are both listed, and left as they are
+	tsFile := filepath.Join(outDir, "checked.ts")
+	if err := os.WriteFile(tsFile, []byte("// edited\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	workerFile := filepath.Join(outDir, "worker.js")
+	if err := os.Remove(workerFile); err != nil {
+		t.Fatal(err)
+	}
+	err := execute(cfg)
+	if err == nil {
+		t.Fatal("check should fail on changed files")
+	}
+	for _, path := range []string{tsFile, workerFile} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("error should list %s: %v", path, err)
+		}
+	}
+	if strings.Contains(err.Error(), "bindings_gen.go") {
+		t.Errorf("error should not list the unchanged bindings: %v", err)
+	}
+	content, readErr := os.ReadFile(tsFile) //nolint:gosec // test file path
+	if readErr != nil || string(content) != "// edited\n" {
+		t.Errorf("check should not rewrite %s, got %q (%v)", tsFile, content, readErr)
+	}
+	if _, statErr := os.Stat(workerFile); statErr == nil {
+		t.Errorf("check should not write %s", workerFile)
+	}
+}
+
+func TestExecute_GoOutput(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	goOutput := filepath.Join(srcDir, "wasm_bindings.go")
+
+	cfg := Config{
+		SourceFile:   srcDir,
+		OutputDir:    t.TempDir(),
+		NoBuild:      true,
+		Mode:         "sync",
+		ClassName:    "Custom",
+		GoOutput:     goOutput,
+		EmitChecksum: true,
+		Stdout:       io.Discard,
+		Stderr:       io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	// The written bindings are not part of the checksummed package sources
+	cfg.CheckStale = true
+	if err := execute(cfg); err != nil {
+		t.Errorf("check-stale after generating: %v", err)
+	}
+
+	content, err := os.ReadFile(goOutput)
+	if err != nil {
+		t.Fatalf("Go bindings not written to --go-output: %v", err)
+	}
+	if !strings.Contains(string(content), "func wasmGreet(") {
+		t.Errorf("unexpected Go bindings:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(srcDir, "bindings_gen.go")); err == nil {
+		t.Error("bindings_gen.go should not be written when --go-output is set")
+	}
+}
+
+func TestExecute_AllowAny(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\nfunc Echo(v interface{}) interface{} { return v }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{
+		SourceFile: srcDir,
+		OutputDir:  t.TempDir(),
+		NoBuild:    true,
+		Mode:       "worker",
+		ClassName:  "Custom",
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+	err := execute(cfg)
+	if err == nil || !strings.Contains(err.Error(), "--allow-any") {
+		t.Fatalf("expected interface{} to need --allow-any, got: %v", err)
+	}
+
+	cfg.AllowAny = true
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(cfg.OutputDir, "custom.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(client), "echo(v: unknown, options?: { signal?: AbortSignal }): Promise<unknown>") {
+		t.Errorf("unexpected client:\n%s", client)
+	}
+}
+
+func TestExecute_Strict(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\nfunc Lookup() map[string]struct{} { return nil }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{
+		SourceFile: srcDir,
+		OutputDir:  t.TempDir(),
+		NoBuild:    true,
+		Mode:       "worker",
+		ClassName:  "Custom",
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed without --strict: %v", err)
+	}
+
+	cfg.OutputDir = t.TempDir()
+	cfg.Strict = true
+	err := execute(cfg)
+	if err == nil || !strings.Contains(err.Error(), "function Lookup: return type map value resolved to any (--strict)") {
+		t.Fatalf("expected --strict to reject map[string]struct{}, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.OutputDir, "custom.ts")); !os.IsNotExist(err) {
+		t.Errorf("client should not be written when --strict fails, stat: %v", err)
+	}
+}
+
+func TestExecute_MethodsOf(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\ntype Counter struct{ n int }\n\nfunc (c *Counter) Add(n int) int { c.n += n; return c.n }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{
+		SourceFile: srcDir,
+		OutputDir:  t.TempDir(),
+		NoBuild:    true,
+		Mode:       "sync",
+		ClassName:  "Custom",
+		MethodsOf:  "Counter",
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	bindings, err := os.ReadFile(filepath.Join(srcDir, "bindings_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bindings), "gowasmInstance.Add(n)") {
+		t.Errorf("bindings do not call the method on the instance:\n%s", bindings)
+	}
+	client, err := os.ReadFile(filepath.Join(cfg.OutputDir, "custom.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(client), "add(n: number): number") {
+		t.Errorf("client is missing add:\n%s", client)
+	}
+
+	cfg.MethodsOf = "Missing"
+	if err := execute(cfg); err == nil || !strings.Contains(err.Error(), "--methods-of: type Missing has no exported methods") {
+		t.Errorf("expected error for a type without methods, got: %v", err)
+	}
+}
+
+func TestExecute_List(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\ntype User struct {\n\tName string `json:\"name\"`\n}\n\nfunc Find(id int) User { return User{} }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	outDir := filepath.Join(t.TempDir(), "generated")
+	cfg := Config{
+		SourceFile: srcDir,
+		OutputDir:  outDir,
+		Mode:       "worker",
+		List:       true,
+		Stdout:     &stdout,
+		Stderr:     io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	for _, want := range []string{"Functions:\n  find(id: number): User  // ", "Types:\nexport interface User {\n  name: string;\n}"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("listing missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "Parsing") {
+		t.Errorf("listing includes progress output:\n%s", stdout.String())
+	}
+	for _, path := range []string{outDir, filepath.Join(srcDir, "bindings_gen.go")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should not be written with --list, stat: %v", path, err)
+		}
+	}
+}
+
+func TestExecute_PostHook(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// go mod init writes go.mod to the directory the hook runs in
+	var stdout bytes.Buffer
+	cfg := Config{
+		SourceFile: srcDir,
+		OutputDir:  t.TempDir(),
+		NoBuild:    true,
+		Mode:       "worker",
+		ClassName:  "Custom",
+		PostHook:   []string{"go", "mod", "init", "example.com/hook"},
+		Stdout:     &stdout,
+		Stderr:     io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Running post hook go mod init example.com/hook...") {
+		t.Errorf("post hook not reported:\n%s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(cfg.OutputDir, "go.mod")); err != nil {
+		t.Errorf("post hook did not run in the output directory: %v", err)
+	}
+
+	cfg.OutputDir = t.TempDir()
+	cfg.PostHook = []string{"go", "no-such-command"}
+	if err := execute(cfg); err == nil || !strings.Contains(err.Error(), "running post hook go: exit status") {
+		t.Errorf("expected failing post hook error, got: %v", err)
+	}
+}
+
+func TestExecute_Entrypoint(t *testing.T) {
+	modDir := t.TempDir()
+	libDir := filepath.Join(modDir, "geo")
+	for name, content := range map[string]string{
+		"go.mod":         "module example.com/shapes\n\ngo 1.25\n",
+		"geo/geo.go":     "package geo\n\nfunc Area(w, h float64) float64 { return w * h }\n",
+		"main/main.go":   "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n",
+		"custom/main.go": "package main\n\nfunc main() {}\n",
+	} {
+		path := filepath.Join(modDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entryDir := filepath.Join(modDir, "cmd", "wasm")
+	cfg := Config{
+		SourceFile: libDir,
+		OutputDir:  t.TempDir(),
+		Entrypoint: entryDir,
+		NoBuild:    true,
+		Mode:       "worker",
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	entry, err := os.ReadFile(filepath.Join(entryDir, "main.go")) //nolint:gosec // test file
+	if err != nil {
+		t.Fatalf("entrypoint not written: %v", err)
+	}
+	if !strings.Contains(string(entry), `import _ "example.com/shapes/geo"`) {
+		t.Errorf("entrypoint does not import the library:\n%s", entry)
+	}
+	bindings, err := os.ReadFile(filepath.Join(libDir, "bindings_gen.go")) //nolint:gosec // test file
+	if err != nil || !strings.Contains(string(bindings), "package geo") {
+		t.Errorf("bindings not generated in the library package: %v", err)
+	}
+
+	// Regenerating replaces the generated entrypoint
+	if err := execute(cfg); err != nil {
+		t.Errorf("regenerating failed: %v", err)
+	}
+
+	cfg.Entrypoint = filepath.Join(modDir, "custom")
+	if err := execute(cfg); err == nil || !strings.Contains(err.Error(), "was not generated by gowasm-bindgen") {
+		t.Errorf("expected error for a hand-written main.go, got: %v", err)
+	}
+
+	cfg.SourceFile = filepath.Join(modDir, "main")
+	cfg.Entrypoint = entryDir
+	if err := execute(cfg); err == nil || !strings.Contains(err.Error(), "--entrypoint is for library packages") {
+		t.Errorf("expected error for package main, got: %v", err)
+	}
+}
+
+func TestPackageImportPath(t *testing.T) {
+	modDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(modDir, "go.mod"), []byte("module example.com/shapes\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{modDir, "example.com/shapes"},
+		{filepath.Join(modDir, "geo"), "example.com/shapes/geo"},
+		{filepath.Join(modDir, "internal", "geo"), "example.com/shapes/internal/geo"},
+	}
+	for _, tt := range tests {
+		got, err := packageImportPath(tt.dir)
+		if err != nil || got != tt.want {
+			t.Errorf("packageImportPath(%s) = %q, %v, want %q", tt.dir, got, err, tt.want)
+		}
+	}
+}
+
+func TestExecute_EmitIndex(t *testing.T) {
+	outDir := t.TempDir()
+	run := func(className string) {
+		t.Helper()
+		srcDir := t.TempDir()
+		src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+		if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg := Config{
+			SourceFile: srcDir,
+			OutputDir:  outDir,
+			NoBuild:    true,
+			Mode:       "sync",
+			ClassName:  className,
+			EmitIndex:  true,
+			Stdout:     io.Discard,
+			Stderr:     io.Discard,
+		}
+		if err := execute(cfg); err != nil {
+			t.Fatalf("execute failed: %v", err)
+		}
+	}
+
+	run("GoImage")
+	run("GoText")
+	run("GoImage")
+
+	content, err := os.ReadFile(filepath.Join(outDir, "index.ts"))
+	if err != nil {
+		t.Fatalf("index.ts not written: %v", err)
+	}
+	for _, line := range []string{
+		"export { GoImage } from './go-image';\n",
+		"export { GoText } from './go-text';\n",
+	} {
+		if strings.Count(string(content), line) != 1 {
+			t.Errorf("index.ts should contain %q once:\n%s", line, content)
+		}
+	}
+}
+
+func TestExecute_Manifest(t *testing.T) {
+	srcDir := t.TempDir()
+	src := "package main\n\n//gowasm:name hello\nfunc Greet(name string, times ...int) string { return name }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outDir := t.TempDir()
+
+	cfg := Config{
+		SourceFile: srcDir,
+		OutputDir:  outDir,
+		NoBuild:    true,
+		Mode:       "worker",
+		Manifest:   true,
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "manifest.json")) //nolint:gosec // test file path
+	if err != nil {
+		t.Fatalf("manifest.json not written: %v", err)
+	}
+	var manifest generator.Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		t.Fatalf("manifest.json is not valid JSON: %v\n%s", err, content)
+	}
+	want := generator.Manifest{
+		Package: "main",
+		Functions: []generator.ManifestFunction{{
+			Name: "hello",
+			Params: []generator.ManifestParam{
+				{Name: "name", Type: "string"},
+				{Name: "times", Type: "number[]", Variadic: true},
+			},
+			Returns: "string",
+		}},
+	}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("manifest = %+v, want %+v", manifest, want)
+	}
+}
+
+func TestExecuteJSON(t *testing.T) {
+	srcDir := t.TempDir()
+	write := func(src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outDir := t.TempDir()
+	cfg := Config{
+		SourceFile: srcDir,
+		OutputDir:  outDir,
+		NoBuild:    true,
+		Mode:       "worker",
+		ClassName:  "Custom",
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+
+	write("package main\n\ntype Point struct{ X int }\n\nfunc Greet(name string) string { return name }\n\nfunc Move(p Point) Point { return p }\n\nfunc main() { select {} }\n")
+	var out bytes.Buffer
+	if err := executeJSON(cfg, []string{cfg.SourceFile}, &out); err != nil {
+		t.Fatalf("executeJSON failed: %v", err)
+	}
+	var summary Summary
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	wantFiles := []string{
+		filepath.Join(srcDir, "bindings_gen.go"),
+		filepath.Join(outDir, "worker.js"),
+		filepath.Join(outDir, "custom.ts"),
+	}
+	if !summary.OK || summary.Functions != 2 || summary.Types != 1 || len(summary.Errors) != 0 ||
+		strings.Join(summary.Files, ",") != strings.Join(wantFiles, ",") {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	// Each validation error is reported separately, and still fails the run
+	write("package main\n\nfunc A(c chan int) {}\n\nfunc B(v interface{}) {}\n\nfunc main() { select {} }\n")
+	out.Reset()
+	if err := executeJSON(cfg, []string{cfg.SourceFile}, &out); err == nil {
+		t.Fatal("expected validation error")
+	}
+	summary = Summary{}
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if summary.OK || len(summary.Errors) != 2 || !strings.Contains(summary.Errors[1], "--allow-any") {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestExecuteSources(t *testing.T) {
+	root := t.TempDir()
+	var sources []string
+	for _, name := range []string{"alpha", "beta"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0750); err != nil {
+			t.Fatal(err)
+		}
+		src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, filepath.Join(dir, "main.go"))
+	}
+	outDir := t.TempDir()
+
+	summary := &Summary{}
+	cfg := Config{
+		OutputDir: outDir,
+		NoBuild:   true,
+		Mode:      "worker",
+		Manifest:  true,
+		Summary:   summary,
+		Stdout:    io.Discard,
+		Stderr:    io.Discard,
+	}
+	if err := executeSources(cfg, sources); err != nil {
+		t.Fatalf("executeSources failed: %v", err)
+	}
+
+	// Each module gets its own client and a worker that loads it
+	for _, name := range []string{"alpha", "beta"} {
+		if _, err := os.Stat(filepath.Join(outDir, "go-"+name+".ts")); err != nil {
+			t.Errorf("client for %s not generated: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(root, name, "bindings_gen.go")); err != nil {
+			t.Errorf("bindings for %s not generated: %v", name, err)
+		}
+		worker, err := os.ReadFile(filepath.Join(outDir, name+"-worker.js")) //nolint:gosec // test file path
+		if err != nil {
+			t.Fatalf("worker for %s not generated: %v", name, err)
+		}
+		if !strings.Contains(string(worker), "fetch('"+name+".wasm')") {
+			t.Errorf("worker for %s should load %s.wasm:\n%s", name, name, worker)
+		}
+		if _, err := os.Stat(filepath.Join(outDir, name+"-manifest.json")); err != nil {
+			t.Errorf("manifest for %s not generated: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "worker.js")); err == nil {
+		t.Error("several sources should not share worker.js")
+	}
+	if summary.Functions != 2 || len(summary.Files) != 8 {
+		t.Errorf("summary should cover both sources: %+v", summary)
+	}
+
+	// Errors name the source they came from
+	bad := filepath.Join(root, "gamma", "main.go")
+	if err := os.Mkdir(filepath.Dir(bad), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("package main\n\nfunc main() { select {} }\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err := executeSources(cfg, append(sources, bad))
+	if err == nil || !strings.HasPrefix(err.Error(), bad+": ") {
+		t.Errorf("executeSources() = %v, want error for %s", err, bad)
+	}
+
+	// Two modules can't share a name in one output directory
+	other := filepath.Join(t.TempDir(), "alpha", "main.go")
+	err = executeSources(cfg, []string{sources[0], other})
+	if err == nil || !strings.Contains(err.Error(), "would both generate alpha.wasm") {
+		t.Errorf("executeSources() = %v, want module name collision", err)
+	}
+}
+
 func TestExecute_SyncMode(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "execute-sync-test-*")
 	if err != nil {
@@ -806,3 +1981,199 @@ func main() { select {} }
 		t.Errorf("TypeScript client not generated at %s", tsFile)
 	}
 }
+
+func TestExecute_PackageDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkgDir := filepath.Join(tmpDir, "calc")
+	if err := os.Mkdir(pkgDir, 0750); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+
+	// select {} lives in a different file than the exported functions
+	files := map[string]string{
+		"main.go": "package main\n\nfunc main() { select {} }\n",
+		"add.go":  "package main\n\nfunc Add(a, b int) int { return a + b }\n",
+		"mul.go":  "package main\n\nfunc Mul(a, b int) int { return a * b }\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(pkgDir, name), []byte(src), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	cfg := Config{
+		SourceFile:   pkgDir + string(filepath.Separator),
+		OutputDir:    outDir,
+		NoBuild:      true,
+		Compiler:     "go",
+		Mode:         "sync",
+		EmitChecksum: true,
+		Stdout:       io.Discard,
+		Stderr:       io.Discard,
+	}
+
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	// Bindings are written into the package directory itself
+	bindings, err := os.ReadFile(filepath.Join(pkgDir, "bindings_gen.go"))
+	if err != nil {
+		t.Fatalf("Go bindings not generated: %v", err)
+	}
+	for _, fn := range []string{"wasmAdd", "wasmMul"} {
+		if !strings.Contains(string(bindings), fn) {
+			t.Errorf("bindings missing %s", fn)
+		}
+	}
+
+	// Class name derives from the directory name
+	if _, err := os.Stat(filepath.Join(outDir, "go-calc.ts")); err != nil {
+		t.Errorf("TypeScript client not generated: %v", err)
+	}
+
+	// Re-running ignores the generated bindings, so the checksum still matches
+	cfg.CheckStale = true
+	if err := execute(cfg); err != nil {
+		t.Errorf("check-stale on fresh output: %v", err)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	goFile := filepath.Join(tmpDir, "main.go")
+	src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+	if err := os.WriteFile(goFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	var stdout syncBuffer
+	cfg := Config{
+		SourceFile: goFile,
+		OutputDir:  filepath.Join(tmpDir, "out"),
+		NoBuild:    true,
+		Compiler:   "go",
+		Mode:       "sync",
+		ClassName:  "Watched",
+		Stdout:     &stdout,
+		Stderr:     io.Discard,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- watchSource(ctx, cfg, 10*time.Millisecond) }()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(stdout.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q in output:\n%s", want, stdout.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Initial generation runs before watching starts
+	waitFor("Watching ")
+	if strings.Contains(stdout.String(), "Regenerated") {
+		t.Error("should not report regeneration before any change")
+	}
+
+	// Adding a function regenerates the client with it
+	src = strings.Replace(src, "func main()", "func Shout(s string) string { return s }\n\nfunc main()", 1)
+	later := time.Now().Add(time.Second)
+	if err := os.WriteFile(goFile, []byte(src), 0600); err != nil {
+		t.Fatalf("failed to update test file: %v", err)
+	}
+	if err := os.Chtimes(goFile, later, later); err != nil {
+		t.Fatalf("failed to touch test file: %v", err)
+	}
+	waitFor("Regenerated")
+
+	// Rewriting bindings_gen.go must not trigger another cycle
+	time.Sleep(100 * time.Millisecond)
+	if got := strings.Count(stdout.String(), "Regenerated"); got != 1 {
+		t.Errorf("regenerated %d times, want 1", got)
+	}
+
+	client, err := os.ReadFile(filepath.Join(tmpDir, "out", "watched.ts"))
+	if err != nil {
+		t.Fatalf("failed to read client: %v", err)
+	}
+	if !strings.Contains(string(client), "shout(s: string)") {
+		t.Errorf("regenerated client missing shout():\n%s", client)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("watchSource() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchSource() did not stop after cancel")
+	}
+}
+
+func TestExecute_Stdin(t *testing.T) {
+	tmpDir := t.TempDir()
+	outDir := filepath.Join(tmpDir, "out")
+	src := "package main\n\nfunc Greet(name string) string { return name }\n\nfunc main() { select {} }\n"
+
+	cfg := Config{
+		SourceFile: "-",
+		OutputDir:  outDir,
+		Compiler:   "go",
+		Mode:       "worker",
+		ClassName:  "Piped",
+		Stdin:      strings.NewReader(src),
+		Stdout:     io.Discard,
+		Stderr:     io.Discard,
+	}
+
+	// NoBuild is implied: there is no source directory to compile
+	if err := execute(cfg); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	for _, name := range []string{"bindings_gen.go", "piped.ts", "worker.js"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Errorf("%s not generated: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "main.wasm")); err == nil {
+		t.Error("WASM should not be compiled from stdin")
+	}
+
+	cfg.ClassName = ""
+	cfg.Stdin = strings.NewReader(src)
+	if err := execute(cfg); err == nil || !strings.Contains(err.Error(), "--class-name is required") {
+		t.Errorf("execute() without --class-name = %v, want class name error", err)
+	}
+
+	cfg.ClassName = "Piped"
+	cfg.Stdin = strings.NewReader("package main\n\nfunc Greet() {}\n\nfunc main() {}\n")
+	if err := execute(cfg); err == nil || !strings.Contains(err.Error(), "select {}") {
+		t.Errorf("execute() without select {} = %v, want select error", err)
+	}
+}
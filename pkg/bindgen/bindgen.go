@@ -0,0 +1,216 @@
+// Package bindgen runs gowasm-bindgen's generation in memory, for tools that
+// embed it in their own code generation pipeline. It covers what the CLI
+// writes for a single source file except building the WASM module: the Go
+// bindings, the TypeScript client, and worker.js.
+package bindgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+
+	"github.com/13rac1/gowasm-bindgen/internal/generator"
+	"github.com/13rac1/gowasm-bindgen/internal/parser"
+	"github.com/13rac1/gowasm-bindgen/internal/validator"
+)
+
+// Config selects the source to bind and the generated output. Fields
+// correspond to the CLI flags noted beside them, and the zero value of each
+// selects the default.
+type Config struct {
+	// Source is the Go source file to generate bindings for. Filename names
+	// it in error messages ("main.go" if empty).
+	Source   []byte
+	Filename string
+
+	// Mode is "worker" (the default) or "sync" (--mode).
+	Mode string
+
+	// ClassName is the TypeScript client class ("GoMain" if empty)
+	// (--class-name).
+	ClassName string
+
+	// WasmURL is the URL worker.js loads the module from, relative to
+	// worker.js ("main.wasm" if empty). Worker mode only.
+	WasmURL string
+
+	// GoPackage replaces the source package name in the bindings' package
+	// clause when non-empty (--package).
+	GoPackage string
+
+	// ErrorKey replaces __error as the field that carries Go errors when
+	// non-empty (--error-key).
+	ErrorKey string
+
+	// MethodsOf also binds the exported methods of the named type, called
+	// on a package-level instance of it, when non-empty (--methods-of).
+	MethodsOf string
+
+	// Namespace registers the functions on globalThis.Namespace instead of
+	// the global scope when non-empty (--namespace).
+	Namespace string
+
+	// TypedArrayBytes decodes numeric slice parameters from the bytes of
+	// any typed array or DataView in this byte order, "little" or "big",
+	// when non-empty (--typed-array-bytes).
+	TypedArrayBytes string
+
+	Diagnostics     bool // --emit-diagnostics
+	BigInt          bool // --bigint
+	Base64Bytes     bool // --bytes base64
+	NoTypedArrays   bool // --no-typed-arrays
+	JSONStructs     bool // --json-structs
+	NilAsNull       bool // --nil-as-null
+	CommonJS        bool // --module commonjs
+	Deno            bool // --target deno
+	Node            bool // --target node
+	Minify          bool // --minify
+	AllowAny        bool // --allow-any
+	Strict          bool // --strict
+	StructHelpers   bool // --helpers
+	ResultUnion     bool // --result-union
+	ReadonlyReturns bool // --readonly-returns
+}
+
+// Result holds the generated files. Nothing is written to disk.
+type Result struct {
+	// GoBindings is the gofmt'd Go bindings, which must be compiled
+	// together with the source (the CLI writes them to bindings_gen.go).
+	GoBindings []byte
+
+	// Client is the TypeScript client, to be saved as ClientFile.
+	Client     string
+	ClientFile string
+
+	// Worker is worker.js, empty in sync mode.
+	Worker string
+}
+
+// Generate parses and validates cfg.Source and generates its bindings. If
+// validation fails, the error message lists each problem.
+func Generate(cfg Config) (Result, error) {
+	filename := cfg.Filename
+	if filename == "" {
+		filename = "main.go"
+	}
+	mode := cfg.Mode
+	if mode == "" {
+		mode = "worker"
+	}
+	if mode != "sync" && mode != "worker" {
+		return Result{}, fmt.Errorf("mode must be 'sync' or 'worker', got %q", mode)
+	}
+	if cfg.Deno && cfg.CommonJS {
+		return Result{}, errors.New("the Deno target cannot be combined with CommonJS: Deno loads ES modules only")
+	}
+	if cfg.Node && cfg.Deno {
+		return Result{}, errors.New("the Node.js and Deno targets are exclusive")
+	}
+	if cfg.Node && mode != "sync" {
+		return Result{}, errors.New("the Node.js target requires sync mode: Node.js has no Web Worker API")
+	}
+	if cfg.TypedArrayBytes != "" && cfg.TypedArrayBytes != "little" && cfg.TypedArrayBytes != "big" {
+		return Result{}, fmt.Errorf("typed array byte order must be 'little' or 'big', got %q", cfg.TypedArrayBytes)
+	}
+	if cfg.TypedArrayBytes != "" && cfg.NoTypedArrays {
+		return Result{}, errors.New("typed array bytes cannot be combined with plain arrays: no parameter takes a typed array")
+	}
+	if cfg.GoPackage != "" && (!token.IsIdentifier(cfg.GoPackage) || cfg.GoPackage == "_") {
+		return Result{}, fmt.Errorf("package must be a Go identifier, got %q", cfg.GoPackage)
+	}
+	className := cfg.ClassName
+	if className == "" {
+		className = generator.DeriveClassName("")
+	}
+	wasmURL := cfg.WasmURL
+	if wasmURL == "" {
+		wasmURL = "main.wasm"
+	}
+
+	parsed, err := parser.ParseSource(bytes.NewReader(cfg.Source), filename)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing source file: %w", err)
+	}
+	if cfg.MethodsOf != "" {
+		if err := parser.UseMethodsOf(parsed, cfg.MethodsOf); err != nil {
+			return Result{}, fmt.Errorf("methods-of: %w", err)
+		}
+	}
+	if cfg.BigInt {
+		parser.UseBigInt(parsed)
+	}
+	if cfg.Base64Bytes {
+		parser.UseBase64Bytes(parsed)
+	}
+	if cfg.NoTypedArrays {
+		parser.UsePlainArrays(parsed)
+	}
+	if cfg.TypedArrayBytes != "" {
+		parser.UseTypedArrayBytes(parsed, cfg.TypedArrayBytes)
+	}
+	if cfg.StructHelpers {
+		parser.UseStructHelpers(parsed)
+	}
+	if cfg.ResultUnion {
+		parser.UseResultUnion(parsed)
+	}
+	if cfg.ReadonlyReturns {
+		parser.UseReadonlyReturns(parsed)
+	}
+	if cfg.NilAsNull {
+		parser.UseNilAsNull(parsed)
+	}
+	if cfg.JSONStructs {
+		parser.UseJSONStructs(parsed)
+	}
+
+	if len(parsed.Functions) == 0 {
+		return Result{}, fmt.Errorf("no exported functions found in %s", filename)
+	}
+	if parsed.Package == "main" {
+		hasSelect, err := parser.HasSelectInMainSource(bytes.NewReader(cfg.Source), filename)
+		if err != nil {
+			return Result{}, fmt.Errorf("checking for select {}: %w", err)
+		}
+		if !hasSelect {
+			return Result{}, errors.New("main() does not contain 'select {}' or a channel receive such as '<-make(chan struct{})' - " +
+				"WASM modules require this to block forever and receive JavaScript calls")
+		}
+	}
+	if err := validator.ValidateFunctions(parsed, validator.Options{SyncMode: mode == "sync", AllowAny: cfg.AllowAny, Strict: cfg.Strict}); err != nil {
+		return Result{}, fmt.Errorf("validation failed: %w", err)
+	}
+
+	opts := generator.Options{
+		WorkerMode:  mode == "worker",
+		Diagnostics: cfg.Diagnostics,
+		Namespace:   cfg.Namespace,
+		GoPackage:   cfg.GoPackage,
+		CommonJS:    cfg.CommonJS,
+		Deno:        cfg.Deno,
+		Node:        cfg.Node,
+		ErrorKey:    cfg.ErrorKey,
+	}
+	bindings, err := format.Source([]byte(generator.GenerateGoBindings(parsed, opts)))
+	if err != nil {
+		return Result{}, fmt.Errorf("formatting generated Go bindings: %w", err)
+	}
+
+	result := Result{
+		GoBindings: bindings,
+		ClientFile: generator.ToKebabCase(className) + ".ts",
+	}
+	if mode == "sync" {
+		result.Client = generator.Generate(parsed, result.ClientFile, className, opts)
+	} else {
+		result.Client = generator.GenerateClient(parsed, result.ClientFile, className, opts)
+		result.Worker = generator.GenerateWorker(wasmURL, opts)
+	}
+	if cfg.Minify {
+		result.Client = generator.Minify(result.Client)
+		result.Worker = generator.Minify(result.Worker)
+	}
+	return result, nil
+}
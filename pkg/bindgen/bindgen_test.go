@@ -0,0 +1,87 @@
+package bindgen
+
+import (
+	"strings"
+	"testing"
+)
+
+const source = `package main
+
+import "errors"
+
+// Divide returns a / b.
+func Divide(a, b int) (int, error) {
+	if b == 0 {
+		return 0, errors.New("division by zero")
+	}
+	return a / b, nil
+}
+
+func main() { select {} }
+`
+
+func TestGenerate(t *testing.T) {
+	result, err := Generate(Config{Source: []byte(source), ClassName: "Calc"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if result.ClientFile != "calc.ts" {
+		t.Errorf("ClientFile = %q, want calc.ts", result.ClientFile)
+	}
+	for name, want := range map[string][]string{
+		"GoBindings": {"package main\n", `js.Global().Set("divide", recoverFunc(wasmDivide))`},
+		"Client":     {"export class Calc {", "divide(a: number, b: number, options?: { signal?: AbortSignal }): Promise<number>"},
+		"Worker":     {"fetch('main.wasm')"},
+	} {
+		got := map[string]string{
+			"GoBindings": string(result.GoBindings),
+			"Client":     result.Client,
+			"Worker":     result.Worker,
+		}[name]
+		for _, w := range want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s missing %q:\n%s", name, w, got)
+			}
+		}
+	}
+
+	sync, err := Generate(Config{Source: []byte(source), Mode: "sync", GoPackage: "bindings", ResultUnion: true})
+	if err != nil {
+		t.Fatalf("Generate (sync) failed: %v", err)
+	}
+	if sync.Worker != "" || sync.ClientFile != "go-main.ts" {
+		t.Errorf("unexpected sync result: worker %q, client file %q", sync.Worker, sync.ClientFile)
+	}
+	if !strings.Contains(string(sync.GoBindings), "package bindings\n") ||
+		!strings.Contains(sync.Client, "divide(a: number, b: number): { ok: true; value: number } | { ok: false; error: string }") {
+		t.Errorf("options not applied:\n%s\n%s", sync.GoBindings, sync.Client)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"mode", Config{Source: []byte(source), Mode: "async"}, "mode must be 'sync' or 'worker'"},
+		{"package", Config{Source: []byte(source), GoPackage: "my-pkg"}, "package must be a Go identifier"},
+		{"deno commonjs", Config{Source: []byte(source), Deno: true, CommonJS: true}, "the Deno target cannot be combined with CommonJS"},
+		{"typed array byte order", Config{Source: []byte(source), TypedArrayBytes: "native"}, "typed array byte order must be 'little' or 'big'"},
+		{"typed array bytes plain arrays", Config{Source: []byte(source), TypedArrayBytes: "big", NoTypedArrays: true}, "typed array bytes cannot be combined with plain arrays"},
+		{"node worker", Config{Source: []byte(source), Node: true}, "the Node.js target requires sync mode"},
+		{"node deno", Config{Source: []byte(source), Mode: "sync", Node: true, Deno: true}, "the Node.js and Deno targets are exclusive"},
+		{"syntax", Config{Source: []byte("package main\nfunc {")}, "parsing source file"},
+		{"no functions", Config{Source: []byte("package main\nfunc main() { select {} }\n")}, "no exported functions found in main.go"},
+		{"no select", Config{Source: []byte("package main\nfunc F() {}\nfunc main() {}\n")}, "does not contain 'select {}'"},
+		{"validation", Config{Source: []byte("package main\nfunc F(c chan int) {}\nfunc main() { select {} }\n"), Filename: "f.go"}, "parameter c uses unsupported type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
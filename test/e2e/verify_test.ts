@@ -27,11 +27,14 @@ void test("WASM functions with TypeScript types", async () => {
   assert.strictEqual(info.version, 1);
   assert.strictEqual(info.active, true);
 
+  // Test sum - typed arrays are copied in bulk
+  assert.strictEqual(wasm.sum(new Float64Array([1.5, 2.5, 3])), 7);
+
   // Test panic recovery - should throw error, not crash WASM
   assert.throws(
     () => wasm.triggerPanic(),
     {
-      name: "Error",
+      name: "WasmError",
       message: "panic: intentional panic for testing",
     }
   );
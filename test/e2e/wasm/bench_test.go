@@ -0,0 +1,35 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+// BenchmarkSliceExtraction compares the bulk copy of a typed array argument
+// into a []float64 with the element-by-element path plain arrays take.
+func BenchmarkSliceExtraction(b *testing.B) {
+	const n = 1 << 20
+	typed := js.Global().Get("Float64Array").New(n)
+	plain := js.Global().Get("Array").New(n)
+	for i := 0; i < n; i++ {
+		typed.SetIndex(i, float64(i))
+		plain.SetIndex(i, float64(i))
+	}
+
+	for _, bm := range []struct {
+		name string
+		arg  js.Value
+	}{
+		{"Float64Array", typed},
+		{"Array", plain},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			args := []js.Value{bm.arg}
+			for b.Loop() {
+				wasmSum(js.Undefined(), args)
+			}
+		})
+	}
+}
@@ -28,6 +28,15 @@ func GetInfo(name string) Info {
 	}
 }
 
+// Sum returns the sum of values.
+func Sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
 // TriggerPanic always panics to test panic recovery.
 func TriggerPanic() string {
 	panic("intentional panic for testing")